@@ -37,6 +37,7 @@ type ComponentReadinessFlags struct {
 	MetricsAddr              string
 	RedisURL                 string
 	MaintainRegressionTables bool
+	RegressionClearSnapshots int
 }
 
 func NewComponentReadinessCommand() *cobra.Command {
@@ -77,10 +78,12 @@ func (f *ComponentReadinessFlags) BindFlags(flagSet *pflag.FlagSet) {
 	f.BigQueryFlags.BindFlags(flagSet)
 	f.GoogleCloudFlags.BindFlags(flagSet)
 	f.ProwFlags.BindFlags(flagSet)
+	flagSet.StringVar(&f.Config, "config", f.Config, "Configuration file for Sippy, such as the component readiness options")
 	flagSet.StringVar(&f.LogLevel, "log-level", f.LogLevel, "Log level (trace,debug,info,warn,error) (default info)")
 	flagSet.StringVar(&f.ListenAddr, "listen", f.ListenAddr, "The address to serve analysis reports on (default :8080)")
 	flagSet.StringVar(&f.MetricsAddr, "listen-metrics", f.MetricsAddr, "The address to serve prometheus metrics on (default :2112)")
 	flagSet.BoolVar(&f.MaintainRegressionTables, "maintain-regression-tables", false, "Enable maintenance of open regressions table in bigquery.")
+	flagSet.IntVar(&f.RegressionClearSnapshots, "regression-clear-snapshots", 0, "Close a maintained regression only once it has been clear for this many consecutive refreshes, 0 closes it as soon as it clears.")
 }
 
 func (f *ComponentReadinessFlags) Validate() error {
@@ -118,10 +121,10 @@ func (f *ComponentReadinessFlags) Run() error { //nolint:gocyclo
 		}
 	}
 
-	return f.runServerMode()
+	return f.runServerMode(sippyConfig)
 }
 
-func (f *ComponentReadinessFlags) runServerMode() error {
+func (f *ComponentReadinessFlags) runServerMode(sippyConfig v1.SippyConfig) error {
 	var err error
 
 	webRoot, err := fs.Sub(resources.SippyNG, "sippy-ng/build")
@@ -167,6 +170,7 @@ func (f *ComponentReadinessFlags) runServerMode() error {
 		nil,
 		cacheClient,
 		4*time.Hour,
+		sippyConfig.ComponentReadiness,
 	)
 
 	if f.MetricsAddr != "" {
@@ -178,7 +182,9 @@ func (f *ComponentReadinessFlags) runServerMode() error {
 			nil,
 			time.Time{},
 			cache.RequestOptions{CRTimeRoundingFactor: defaultCRTimeRoundingFactor},
-			f.MaintainRegressionTables)
+			f.MaintainRegressionTables,
+			f.RegressionClearSnapshots,
+			sippyConfig.ComponentReadiness)
 		if err != nil {
 			log.WithError(err).Error("error refreshing metrics")
 		}
@@ -191,7 +197,7 @@ func (f *ComponentReadinessFlags) runServerMode() error {
 				select {
 				case <-ticker.C:
 					log.Info("tick")
-					err := metrics.RefreshMetricsDB(nil, bigQueryClient, f.ProwFlags.URL, f.GoogleCloudFlags.StorageBucket, nil, time.Time{}, cache.RequestOptions{CRTimeRoundingFactor: defaultCRTimeRoundingFactor}, f.MaintainRegressionTables)
+					err := metrics.RefreshMetricsDB(nil, bigQueryClient, f.ProwFlags.URL, f.GoogleCloudFlags.StorageBucket, nil, time.Time{}, cache.RequestOptions{CRTimeRoundingFactor: defaultCRTimeRoundingFactor}, f.MaintainRegressionTables, f.RegressionClearSnapshots, sippyConfig.ComponentReadiness)
 					if err != nil {
 						log.WithError(err).Error("error refreshing metrics")
 					}
@@ -33,6 +33,7 @@ var (
 type ServerFlags struct {
 	BigQueryFlags    *flags.BigQueryFlags
 	CacheFlags       *flags.CacheFlags
+	ConfigFlags      *flags.ConfigFlags
 	DBFlags          *flags.PostgresFlags
 	GoogleCloudFlags *flags.GoogleCloudFlags
 	ModeFlags        *flags.ModeFlags
@@ -41,6 +42,7 @@ type ServerFlags struct {
 	ListenAddr               string
 	MetricsAddr              string
 	MaintainRegressionTables bool
+	RegressionClearSnapshots int
 	CRTimeRoundingFactor     time.Duration
 }
 
@@ -48,6 +50,7 @@ func NewServerFlags() *ServerFlags {
 	return &ServerFlags{
 		BigQueryFlags:    flags.NewBigQueryFlags(),
 		CacheFlags:       flags.NewCacheFlags(),
+		ConfigFlags:      flags.NewConfigFlags(),
 		DBFlags:          flags.NewPostgresDatabaseFlags(),
 		GoogleCloudFlags: flags.NewGoogleCloudFlags(),
 		ModeFlags:        flags.NewModeFlags(),
@@ -60,6 +63,7 @@ func NewServerFlags() *ServerFlags {
 func (f *ServerFlags) BindFlags(flagSet *pflag.FlagSet) {
 	f.BigQueryFlags.BindFlags(flagSet)
 	f.CacheFlags.BindFlags(flagSet)
+	f.ConfigFlags.BindFlags(flagSet)
 	f.DBFlags.BindFlags(flagSet)
 	f.GoogleCloudFlags.BindFlags(flagSet)
 	f.ModeFlags.BindFlags(flagSet)
@@ -70,6 +74,7 @@ func (f *ServerFlags) BindFlags(flagSet *pflag.FlagSet) {
 	factorUsage := fmt.Sprintf("Set the rounding factor for component readiness release time. The time will be rounded down to the nearest multiple of the factor. Maximum value is %v", maxCRTimeRoundingFactor)
 	flagSet.DurationVar(&f.CRTimeRoundingFactor, "component-readiness-time-rounding-factor", defaultCRTimeRoundingFactor, factorUsage)
 	flagSet.BoolVar(&f.MaintainRegressionTables, "maintain-regression-tables", false, "Enable maintenance of open regressions table in bigquery.")
+	flagSet.IntVar(&f.RegressionClearSnapshots, "regression-clear-snapshots", 0, "Close a maintained regression only once it has been clear for this many consecutive refreshes, 0 closes it as soon as it clears.")
 }
 
 func (f *ServerFlags) Validate() error {
@@ -88,6 +93,11 @@ func NewServeCommand() *cobra.Command {
 				return errors.WithMessage(err, "error validating options")
 			}
 
+			config, err := f.ConfigFlags.GetConfig()
+			if err != nil {
+				return errors.WithMessage(err, "couldn't get config")
+			}
+
 			dbc, err := f.DBFlags.GetDBClient()
 			if err != nil {
 				return errors.WithMessage(err, "couldn't get DB client")
@@ -147,11 +157,12 @@ func NewServeCommand() *cobra.Command {
 				pinnedDateTime,
 				cacheClient,
 				f.CRTimeRoundingFactor,
+				config.ComponentReadiness,
 			)
 
 			if f.MetricsAddr != "" {
 				// Do an immediate metrics update
-				err = metrics.RefreshMetricsDB(dbc, bigQueryClient, f.ProwFlags.URL, f.GoogleCloudFlags.StorageBucket, variantManager, util.GetReportEnd(pinnedDateTime), cache.RequestOptions{CRTimeRoundingFactor: f.CRTimeRoundingFactor}, f.MaintainRegressionTables)
+				err = metrics.RefreshMetricsDB(dbc, bigQueryClient, f.ProwFlags.URL, f.GoogleCloudFlags.StorageBucket, variantManager, util.GetReportEnd(pinnedDateTime), cache.RequestOptions{CRTimeRoundingFactor: f.CRTimeRoundingFactor}, f.MaintainRegressionTables, f.RegressionClearSnapshots, config.ComponentReadiness)
 				if err != nil {
 					log.WithError(err).Error("error refreshing metrics")
 				}
@@ -164,7 +175,7 @@ func NewServeCommand() *cobra.Command {
 						select {
 						case <-ticker.C:
 							log.Info("tick")
-							err := metrics.RefreshMetricsDB(dbc, bigQueryClient, f.ProwFlags.URL, f.GoogleCloudFlags.StorageBucket, variantManager, util.GetReportEnd(pinnedDateTime), cache.RequestOptions{CRTimeRoundingFactor: f.CRTimeRoundingFactor}, f.MaintainRegressionTables)
+							err := metrics.RefreshMetricsDB(dbc, bigQueryClient, f.ProwFlags.URL, f.GoogleCloudFlags.StorageBucket, variantManager, util.GetReportEnd(pinnedDateTime), cache.RequestOptions{CRTimeRoundingFactor: f.CRTimeRoundingFactor}, f.MaintainRegressionTables, f.RegressionClearSnapshots, config.ComponentReadiness)
 							if err != nil {
 								log.WithError(err).Error("error refreshing metrics")
 							}
@@ -3,6 +3,8 @@ package api
 import (
 	"context"
 	"fmt"
+	"math"
+	"path"
 	"regexp"
 	"sort"
 	"strconv"
@@ -14,6 +16,7 @@ import (
 	"github.com/openshift/sippy/pkg/componentreadiness/tracker"
 
 	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/civil"
 	fischer "github.com/glycerine/golang-fisher-exact"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -23,6 +26,7 @@ import (
 	"github.com/openshift/sippy/pkg/apis/cache"
 	bqcachedclient "github.com/openshift/sippy/pkg/bigquery"
 	"github.com/openshift/sippy/pkg/regressionallowances"
+	"github.com/openshift/sippy/pkg/testidentification"
 	"github.com/openshift/sippy/pkg/util/sets"
 )
 
@@ -66,6 +70,23 @@ const (
 			AND skipped = false
 		)
 		SELECT * FROM deduped_testcases WHERE row_num = 1`
+
+	// junitRunAggregationTable collapses the deduped junit rows of the same test in the same job
+	// run, one per junit file, into the first of them with the outcome of the run, see
+	// junitRunOutcome. It is given the success and flake expressions of the JunitAggregation,
+	// then the deduped junit table.
+	junitRunAggregationTable = `
+		SELECT * EXCEPT(run_passes, run_failures, run_flakes, run_row_num) REPLACE(
+			%s AS success_val,
+			%s AS flake_count)
+		FROM (
+			SELECT *,
+				COUNTIF(flake_count = 0 AND success_val > 0) OVER(PARTITION BY prowjob_build_id, test_name, testsuite) AS run_passes,
+				COUNTIF(flake_count = 0 AND success_val = 0) OVER(PARTITION BY prowjob_build_id, test_name, testsuite) AS run_failures,
+				COUNTIF(flake_count > 0) OVER(PARTITION BY prowjob_build_id, test_name, testsuite) AS run_flakes,
+				ROW_NUMBER() OVER(PARTITION BY prowjob_build_id, test_name, testsuite ORDER BY file_path) AS run_row_num
+			FROM (%s))
+		WHERE run_row_num = 1`
 )
 
 type GeneratorType string
@@ -84,6 +105,28 @@ var (
 	DefaultIgnoreDisruption = true
 )
 
+// groupByVariantOrder are the variant groupings understood by the component report, in the
+// order they are listed in.
+var groupByVariantOrder = []string{"cloud", "arch", "network", "upgrade", "variants", "suite"}
+
+// groupByOptions are the variant groupings understood by the component report.
+var groupByOptions = sets.NewString(groupByVariantOrder...)
+
+// ResolveGroupingPreset returns the group by for the named preset, erroring if there is no such
+// preset or it has groupings the component report does not understand.
+func ResolveGroupingPreset(presets map[string][]string, name string) (string, error) {
+	groups, ok := presets[name]
+	if !ok {
+		return "", fmt.Errorf("unknown grouping preset %q", name)
+	}
+	for _, group := range groups {
+		if !groupByOptions.Has(group) {
+			return "", fmt.Errorf("grouping preset %q has unknown grouping %q", name, group)
+		}
+	}
+	return strings.Join(groups, ","), nil
+}
+
 func getSingleColumnResultToSlice(query *bigquery.Query) ([]string, error) {
 	names := []string{}
 	it, err := query.Read(context.TODO())
@@ -116,6 +159,30 @@ func GetComponentTestVariantsFromBigQuery(client *bqcachedclient.Client, gcsBuck
 	return getDataFromCacheOrGenerate[apitype.ComponentReportTestVariants](client.Cache, cache.RequestOptions{}, GetPrefixedCacheKey("TestVariants~", generator), generator.GenerateVariants, apitype.ComponentReportTestVariants{})
 }
 
+// GetObservedTestVariantsFromBigQuery returns the variant values the test ran with in the sample
+// window, see componentReportGenerator.ObservedVariants.
+func GetObservedTestVariantsFromBigQuery(ctx context.Context, client *bqcachedclient.Client, gcsBucket string,
+	sampleRelease apitype.ComponentReportRequestReleaseOptions, testID string, cacheOption cache.RequestOptions) (map[string][]string, []error) {
+	generator := componentReportGenerator{
+		client:        client,
+		gcsBucket:     gcsBucket,
+		cacheOption:   cacheOption,
+		SampleRelease: sampleRelease,
+		ComponentReportRequestTestIdentificationOptions: apitype.ComponentReportRequestTestIdentificationOptions{
+			TestID: testID,
+		},
+	}
+	generate := func() (map[string][]string, []error) {
+		observed, err := generator.ObservedVariants(ctx, testID)
+		if err != nil {
+			return nil, []error{err}
+		}
+		return observed, nil
+	}
+
+	return getDataFromCacheOrGenerate[map[string][]string](client.Cache, cacheOption, GetPrefixedCacheKey("ObservedTestVariants~", generator), generate, nil)
+}
+
 func GetJobVariantsFromBigQuery(client *bqcachedclient.Client, gcsBucket string) (apitype.JobVariants, []error) {
 	generator := componentReportGenerator{
 		client:    client,
@@ -150,13 +217,44 @@ func GetComponentReportFromBigQuery(client *bqcachedclient.Client, prowURL, gcsB
 	return getDataFromCacheOrGenerate[apitype.ComponentReport](generator.client.Cache, generator.cacheOption, generator.GetComponentReportCacheKey("ComponentReport~"), generator.GenerateReport, apitype.ComponentReport{})
 }
 
-func GetComponentReportTestDetailsFromBigQuery(client *bqcachedclient.Client, prowURL, gcsBucket string,
+// GetNewTestsFromBigQuery lists the tests that ran in the sample but not in the basis of the
+// component report with the same options, regardless of their pass rate.
+func GetNewTestsFromBigQuery(client *bqcachedclient.Client, prowURL, gcsBucket string,
 	baseRelease, sampleRelease apitype.ComponentReportRequestReleaseOptions,
 	testIDOption apitype.ComponentReportRequestTestIdentificationOptions,
 	variantOption apitype.ComponentReportRequestVariantOptions,
 	excludeOption apitype.ComponentReportRequestExcludeOptions,
 	advancedOption apitype.ComponentReportRequestAdvancedOptions,
-	cacheOption cache.RequestOptions) (apitype.ComponentReportTestDetails, []error) {
+	cacheOption cache.RequestOptions,
+) ([]apitype.ComponentReportNewTest, []error) {
+	generator := componentReportGenerator{
+		client:        client,
+		prowURL:       prowURL,
+		gcsBucket:     gcsBucket,
+		cacheOption:   cacheOption,
+		BaseRelease:   baseRelease,
+		SampleRelease: sampleRelease,
+		ComponentReportRequestTestIdentificationOptions: testIDOption,
+		ComponentReportRequestVariantOptions:            variantOption,
+		ComponentReportRequestExcludeOptions:            excludeOption,
+		ComponentReportRequestAdvancedOptions:           advancedOption,
+	}
+
+	return getDataFromCacheOrGenerate[[]apitype.ComponentReportNewTest](generator.client.Cache, generator.cacheOption, generator.GetComponentReportCacheKey("NewTests~"), generator.GenerateNewTests, nil)
+}
+
+// StreamComponentReportFromBigQuery generates the component report like GetComponentReportFromBigQuery,
+// but sends each row on rows as soon as it is completed instead of building the whole report in memory.
+// rows is closed when the report is complete, when ctx is done, or when an error is returned.
+func StreamComponentReportFromBigQuery(ctx context.Context, client *bqcachedclient.Client, prowURL, gcsBucket string,
+	baseRelease, sampleRelease apitype.ComponentReportRequestReleaseOptions,
+	testIDOption apitype.ComponentReportRequestTestIdentificationOptions,
+	variantOption apitype.ComponentReportRequestVariantOptions,
+	excludeOption apitype.ComponentReportRequestExcludeOptions,
+	advancedOption apitype.ComponentReportRequestAdvancedOptions,
+	cacheOption cache.RequestOptions,
+	rows chan<- apitype.ComponentReportRow,
+) []error {
 	generator := componentReportGenerator{
 		client:        client,
 		prowURL:       prowURL,
@@ -164,6 +262,73 @@ func GetComponentReportTestDetailsFromBigQuery(client *bqcachedclient.Client, pr
 		cacheOption:   cacheOption,
 		BaseRelease:   baseRelease,
 		SampleRelease: sampleRelease,
+		triagedIssues: nil,
+		ComponentReportRequestTestIdentificationOptions: testIDOption,
+		ComponentReportRequestVariantOptions:            variantOption,
+		ComponentReportRequestExcludeOptions:            excludeOption,
+		ComponentReportRequestAdvancedOptions:           advancedOption,
+	}
+
+	componentReportTestStatus, errs := generator.GenerateComponentReportTestStatus()
+	if len(errs) > 0 {
+		close(rows)
+		return errs
+	}
+	bqs := tracker.NewBigQueryRegressionStore(generator.client)
+	openRegressions, err := bqs.ListCurrentRegressions(generator.SampleRelease.Release)
+	if err != nil {
+		close(rows)
+		return []error{err}
+	}
+	generator.streamComponentTestReport(ctx, componentReportTestStatus.BaseStatus, componentReportTestStatus.SampleStatus, openRegressions, rows)
+	return nil
+}
+
+// GetOpenRegressionsFromBigQuery lists the open regressions of every release, or of only the given
+// release and component when set. Regressions of a release in reports are joined with their latest
+// status in that report, those of other releases have no status. See tracker.SortOpenRegressions
+// for the order.
+func GetOpenRegressionsFromBigQuery(ctx context.Context, client *bqcachedclient.Client, release, component string,
+	reports map[string]apitype.ComponentReport) ([]apitype.OpenTestRegression, error) {
+	regressions, err := tracker.NewBigQueryRegressionStore(client).ListOpenRegressions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filtered := []apitype.OpenTestRegression{}
+	for _, regression := range regressions {
+		if (release != "" && regression.Release != release) || (component != "" && regression.Component != component) {
+			continue
+		}
+		filtered = append(filtered, regression)
+	}
+	tracker.SortOpenRegressions(filtered, reports, time.Now())
+	return filtered, nil
+}
+
+// GetTestRegressionHistoryFromBigQuery returns every regression recorded for the test with exactly
+// the given variants, see componentReportGenerator.TestRegressionHistory.
+func GetTestRegressionHistoryFromBigQuery(ctx context.Context, client *bqcachedclient.Client, testID string,
+	variants map[string]string) ([]apitype.TestRegression, error) {
+	generator := componentReportGenerator{client: client}
+	return generator.TestRegressionHistory(ctx, testID, variants)
+}
+
+func GetComponentReportTestDetailsFromBigQuery(client *bqcachedclient.Client, prowURL, gcsBucket string,
+	baseRelease, sampleRelease apitype.ComponentReportRequestReleaseOptions,
+	testIDOption apitype.ComponentReportRequestTestIdentificationOptions,
+	variantOption apitype.ComponentReportRequestVariantOptions,
+	excludeOption apitype.ComponentReportRequestExcludeOptions,
+	advancedOption apitype.ComponentReportRequestAdvancedOptions,
+	cacheOption cache.RequestOptions) (apitype.ComponentReportTestDetails, []error) {
+	generator := componentReportGenerator{
+		client:      client,
+		prowURL:     prowURL,
+		gcsBucket:   gcsBucket,
+		cacheOption: cacheOption,
+		// the basis override matching the test's variants is resolved by testDetailsBasis, so the
+		// requested base release can still be reported alongside it
+		BaseRelease:   baseRelease,
+		SampleRelease: sampleRelease,
 		ComponentReportRequestTestIdentificationOptions: testIDOption,
 		ComponentReportRequestVariantOptions:            variantOption,
 		ComponentReportRequestExcludeOptions:            excludeOption,
@@ -188,6 +353,15 @@ type componentReportGenerator struct {
 	BaseRelease    apitype.ComponentReportRequestReleaseOptions
 	SampleRelease  apitype.ComponentReportRequestReleaseOptions
 	triagedIssues  *resolvedissues.TriagedIncidentsForRelease
+	// recordedTriagedIncidents collects triage lookups while recording a report,
+	// replayedTriagedIncidents answers them when replaying one.
+	recordedTriagedIncidents *[]RecordedTriagedIncidents
+	replayedTriagedIncidents map[apitype.ComponentReportTestIdentification]RecordedTriagedIncidents
+	// significanceLevel replaces the level derived from Confidence when a multiple comparison
+	// correction is in effect, nil otherwise.
+	significanceLevel *float64
+	// regressionLister replaces the BigQuery regression store when set, for tests.
+	regressionLister testRegressionLister
 	apitype.ComponentReportRequestTestIdentificationOptions
 	apitype.ComponentReportRequestVariantOptions
 	apitype.ComponentReportRequestExcludeOptions
@@ -225,6 +399,117 @@ func (c *componentReportGenerator) GenerateVariants() (apitype.ComponentReportTe
 	}, errs
 }
 
+// observedVariantRow is a combination of variants a test ran with.
+type observedVariantRow struct {
+	Network  string   `bigquery:"network"`
+	Upgrade  string   `bigquery:"upgrade"`
+	Arch     string   `bigquery:"arch"`
+	Platform string   `bigquery:"platform"`
+	Variants []string `bigquery:"variants"`
+}
+
+// ObservedVariants returns the distinct values of each variant the test ran with in the sample
+// window, keyed like the fields of apitype.ComponentReportTestVariants, so only the values
+// test details can be requested for are offered.
+func (c *componentReportGenerator) ObservedVariants(ctx context.Context, testID string) (map[string][]string, error) {
+	queryString := fmt.Sprintf(`WITH latest_component_mapping AS (
+						SELECT *
+						FROM %s.component_mapping cm
+						WHERE created_at = (
+								SELECT MAX(created_at)
+								FROM %s.component_mapping))
+					SELECT
+						network,
+						upgrade,
+						arch,
+						platform,
+						ANY_VALUE(variants) AS variants
+					FROM (%s)
+					INNER JOIN latest_component_mapping cm ON testsuite = cm.suite AND test_name = cm.name
+					WHERE
+						(prowjob_name LIKE 'periodic-%%' OR prowjob_name LIKE 'release-%%' OR prowjob_name LIKE 'aggregator-%%')
+						AND NOT REGEXP_CONTAINS(prowjob_name, @IgnoredJobs)
+						AND branch = @SampleRelease
+						AND cm.id = @TestId
+					GROUP BY
+						network,
+						upgrade,
+						arch,
+						platform,
+						flat_variants`, c.client.Dataset, c.client.Dataset, fmt.Sprintf(dedupedJunitTable, c.client.Dataset))
+	query := c.client.BQ.Query(queryString)
+	query.Parameters = []bigquery.QueryParameter{
+		{
+			Name:  "IgnoredJobs",
+			Value: ignoredJobsRegexp,
+		},
+		{
+			Name:  "From",
+			Value: c.SampleRelease.Start,
+		},
+		{
+			Name:  "To",
+			Value: c.SampleRelease.End,
+		},
+		{
+			Name:  "SampleRelease",
+			Value: c.SampleRelease.Release,
+		},
+		{
+			Name:  "TestId",
+			Value: testID,
+		},
+	}
+	it, err := query.Read(ctx)
+	if err != nil {
+		log.WithError(err).Errorf("error querying observed variants from bigquery for %s", queryString)
+		return nil, err
+	}
+
+	rows := []observedVariantRow{}
+	for {
+		row := observedVariantRow{}
+		err := it.Next(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "error fetching observed variant row")
+		}
+		rows = append(rows, row)
+	}
+	return observedVariants(rows), nil
+}
+
+// observedVariants collects the sorted distinct values of each variant of the rows. Empty values
+// are left out, variants without any value are not keyed.
+func observedVariants(rows []observedVariantRow) map[string][]string {
+	values := map[string]sets.String{}
+	add := func(key, value string) {
+		if value == "" {
+			return
+		}
+		if _, ok := values[key]; !ok {
+			values[key] = sets.NewString()
+		}
+		values[key].Insert(value)
+	}
+	for _, row := range rows {
+		add("network", row.Network)
+		add("upgrade", row.Upgrade)
+		add("arch", row.Arch)
+		add("platform", row.Platform)
+		for _, variant := range row.Variants {
+			add("variant", variant)
+		}
+	}
+	observed := map[string][]string{}
+	for key, set := range values {
+		observed[key] = set.List()
+	}
+	return observed
+}
+
 func (c *componentReportGenerator) GenerateJobVariants() (apitype.JobVariants, []error) {
 	errs := []error{}
 	variants := apitype.JobVariants{Variants: map[string][]string{}}
@@ -292,11 +577,54 @@ func (c *componentReportGenerator) GenerateReport() (apitype.ComponentReport, []
 	}
 	report := c.generateComponentTestReport(componentReportTestStatus.BaseStatus, componentReportTestStatus.SampleStatus, openRegressions)
 	report.GeneratedAt = componentReportTestStatus.GeneratedAt
+	report.BaseWindow = c.BaseRelease.RollingBaselineWindow()
 	log.Infof("GenerateReport completed in %s with %d sample results and %d base results from db", time.Since(before), len(componentReportTestStatus.SampleStatus), len(componentReportTestStatus.BaseStatus))
 
 	return report, nil
 }
 
+func (c *componentReportGenerator) GenerateNewTests() ([]apitype.ComponentReportNewTest, []error) {
+	componentReportTestStatus, errs := c.GenerateComponentReportTestStatus()
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return c.newTests(componentReportTestStatus.BaseStatus, componentReportTestStatus.SampleStatus), nil
+}
+
+// newTests returns the tests of the sample status missing from the base status, sorted by
+// component, test name and variants. Variants are aligned as in the report first, so renamed
+// variants and a cross compared base arch are not mistaken for new tests.
+func (c *componentReportGenerator) newTests(baseStatus, sampleStatus map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus) []apitype.ComponentReportNewTest {
+	baseStatus, sampleStatus = c.alignVariants(baseStatus, sampleStatus)
+	tests := []apitype.ComponentReportNewTest{}
+	for testIdentification, sampleStats := range sampleStatus {
+		if _, ok := baseStatus[testIdentification]; ok {
+			continue
+		}
+		failures := sampleStats.TotalCount - sampleStats.SuccessCount - sampleStats.FlakeCount
+		tests = append(tests, apitype.ComponentReportNewTest{
+			ComponentReportTestIdentification: buildTestID(sampleStats, testIdentification),
+			SampleStats: apitype.ComponentReportTestDetailsTestStats{
+				SuccessRate:  getPassRate(sampleStats, c.FlakeHandling),
+				SuccessCount: sampleStats.SuccessCount,
+				FailureCount: failures,
+				FlakeCount:   sampleStats.FlakeCount,
+			},
+		})
+	}
+	sort.Slice(tests, func(i, j int) bool {
+		a, b := tests[i], tests[j]
+		if a.Component != b.Component {
+			return a.Component < b.Component
+		}
+		if a.TestName != b.TestName {
+			return a.TestName < b.TestName
+		}
+		return fmt.Sprint(a.ComponentReportColumnIdentification) < fmt.Sprint(b.ComponentReportColumnIdentification)
+	})
+	return tests
+}
+
 func (c *componentReportGenerator) GenerateComponentReportTestStatus() (apitype.ComponentReportTestStatus, []error) {
 	before := time.Now()
 	componentReportTestStatus, errs := c.getTestStatusFromBigQuery()
@@ -324,6 +652,7 @@ func (c *componentReportGenerator) GenerateTestDetailsReport() (apitype.Componen
 	}
 	report := c.generateComponentTestDetailsReport(componentJobRunTestReportStatus.BaseStatus, componentJobRunTestReportStatus.SampleStatus)
 	report.GeneratedAt = componentJobRunTestReportStatus.GeneratedAt
+	report.BaseWindow = c.BaseRelease.RollingBaselineWindow()
 	return report, nil
 }
 
@@ -351,14 +680,35 @@ func (c *componentReportGenerator) getCommonJobRunTestStatusQuery() (string, str
 						ANY_VALUE(testsuite) AS test_suite,
 						file_path,
 						ANY_VALUE(prowjob_name) AS prowjob_name,
+						ANY_VALUE(junit.prowjob_build_id) AS prowjob_build_id,
+						ANY_VALUE(jobs.prowjob_start) AS prowjob_start,
+						ANY_VALUE(jobs.org) AS org,
+						ANY_VALUE(jobs.repo) AS repo,
+						ANY_VALUE(jobs.pr_number) AS pr_number,
 						ANY_VALUE(cm.jira_component) AS jira_component,
 						ANY_VALUE(cm.jira_component_id) AS jira_component_id,
 						COUNT(*) AS total_count,
 						ANY_VALUE(cm.capabilities) as capabilities,
 						SUM(success_val) AS success_count,
 						SUM(flake_count) AS flake_count,
-					FROM (%s)
-					INNER JOIN latest_component_mapping cm ON testsuite = cm.suite AND test_name = cm.name`, c.client.Dataset, c.client.Dataset, fmt.Sprintf(dedupedJunitTable, c.client.Dataset))
+						LOGICAL_OR(infra.prowjob_build_id IS NOT NULL) AS infrastructure_failure,
+					FROM (%s) junit
+					INNER JOIN latest_component_mapping cm ON testsuite = cm.suite AND test_name = cm.name
+					LEFT JOIN (
+						SELECT *
+						FROM %s.jobs
+						-- runs start before their results are written, allow a day for long runs
+						WHERE prowjob_start >= DATETIME_SUB(DATETIME(@From), INTERVAL 1 DAY)
+							AND prowjob_start < DATETIME(@To)) jobs ON junit.prowjob_build_id = jobs.prowjob_build_id
+					LEFT JOIN (
+						SELECT prowjob_build_id
+						FROM %s.junit
+						WHERE test_name = @InfrastructureTestName
+							AND modified_time >= DATETIME(@From)
+							AND modified_time < DATETIME(@To)
+						GROUP BY prowjob_build_id
+						HAVING SUM(success_val) + SUM(flake_count) = 0) infra ON junit.prowjob_build_id = infra.prowjob_build_id`,
+		c.client.Dataset, c.client.Dataset, fmt.Sprintf(dedupedJunitTable, c.client.Dataset), c.client.Dataset, c.client.Dataset)
 
 	groupString := `
 					GROUP BY
@@ -368,8 +718,7 @@ func (c *componentReportGenerator) getCommonJobRunTestStatusQuery() (string, str
 						modified_time `
 	queryString += `
 					WHERE
-						(prowjob_name LIKE 'periodic-%%' OR prowjob_name LIKE 'release-%%' OR prowjob_name LIKE 'aggregator-%%')
-						AND NOT REGEXP_CONTAINS(prowjob_name, @IgnoredJobs)
+						NOT REGEXP_CONTAINS(prowjob_name, @IgnoredJobs)
 						AND upgrade = @Upgrade
 						AND arch = @Arch
 						AND network = @Network
@@ -405,11 +754,65 @@ func (c *componentReportGenerator) getCommonJobRunTestStatusQuery() (string, str
 			Name:  "Variant",
 			Value: c.Variant,
 		},
+		{
+			Name:  "InfrastructureTestName",
+			Value: testidentification.NewInfrastructureTestName,
+		},
+	}
+	if len(c.IncludeJobs) > 0 {
+		includeJobsFilter, includeJobsParams := c.getIncludeJobsFilter()
+		queryString += includeJobsFilter
+		commonParams = append(commonParams, includeJobsParams...)
 	}
 
 	return queryString, groupString, commonParams
 }
 
+// periodicJobsFilter limits test details queries to the periodic, release and aggregator jobs
+// the component report is built from.
+const periodicJobsFilter = ` AND (prowjob_name LIKE 'periodic-%%' OR prowjob_name LIKE 'release-%%' OR prowjob_name LIKE 'aggregator-%%')`
+
+// presubmitJobsFilter also includes the presubmit jobs of pull requests, so their first runs
+// can be excluded from the sample.
+const presubmitJobsFilter = ` AND (prowjob_name LIKE 'periodic-%%' OR prowjob_name LIKE 'release-%%' OR prowjob_name LIKE 'aggregator-%%' OR prowjob_name LIKE 'pull-ci-%%')`
+
+// testDetailsQueryCacheKey identifies the job run test status queried for test details. It holds
+// only what the queries depend on, the test, its variants and the release windows, so drilling
+// into a test again after changing other report options, or after triage changes, reuses them.
+type testDetailsQueryCacheKey struct {
+	TestID        string
+	Platform      string
+	Arch          string
+	Network       string
+	Upgrade       string
+	Variant       string
+	IncludeJobs   []string `json:",omitempty"`
+	BaseRelease   apitype.ComponentReportRequestReleaseOptions
+	SampleRelease apitype.ComponentReportRequestReleaseOptions
+	// JobNameNormalizations are part of the key as the rows are keyed by normalized job name
+	JobNameNormalizations []apitype.JobNameNormalization `json:",omitempty"`
+	// IncludePresubmits is set for sample queries that also return pull request runs
+	IncludePresubmits bool `json:",omitempty"`
+}
+
+// testDetailsQueryCacheKey returns the cache key of a test details job run query. Both releases
+// are part of the key for either query, as both are normalized out of job names.
+func (c *componentReportGenerator) testDetailsQueryCacheKey(prefix string, includePresubmits bool) CacheData {
+	return GetPrefixedCacheKey(prefix, testDetailsQueryCacheKey{
+		TestID:                c.TestID,
+		Platform:              c.Platform,
+		Arch:                  c.Arch,
+		Network:               c.Network,
+		Upgrade:               c.Upgrade,
+		Variant:               c.Variant,
+		IncludeJobs:           c.IncludeJobs,
+		BaseRelease:           c.testDetailsBasis(),
+		SampleRelease:         c.SampleRelease,
+		JobNameNormalizations: c.JobNameNormalizations,
+		IncludePresubmits:     includePresubmits,
+	})
+}
+
 type baseJobRunTestStatusGenerator struct {
 	commonQuery              string
 	groupByQuery             string
@@ -433,7 +836,7 @@ func (c *componentReportGenerator) getBaseJobRunTestStatus(commonQuery string,
 		ComponentReportGenerator: c,
 	}
 
-	componentReportTestStatus, errs := getDataFromCacheOrGenerate[apitype.ComponentJobRunTestReportStatus](generator.ComponentReportGenerator.client.Cache, generator.cacheOption, GetPrefixedCacheKey("BaseJobRunTestStatus~", generator), generator.queryTestStatus, apitype.ComponentJobRunTestReportStatus{})
+	componentReportTestStatus, errs := getDataFromCacheOrGenerate[apitype.ComponentJobRunTestReportStatus](generator.ComponentReportGenerator.client.Cache, generator.cacheOption, c.testDetailsQueryCacheKey("BaseJobRunTestStatus~", false), generator.queryTestStatus, apitype.ComponentJobRunTestReportStatus{})
 
 	if len(errs) > 0 {
 		return nil, errs
@@ -443,22 +846,23 @@ func (c *componentReportGenerator) getBaseJobRunTestStatus(commonQuery string,
 }
 
 func (b *baseJobRunTestStatusGenerator) queryTestStatus() (apitype.ComponentJobRunTestReportStatus, []error) {
-	baseString := b.commonQuery + ` AND branch = @BaseRelease`
+	baseString := b.commonQuery + ` AND branch = @BaseRelease` + periodicJobsFilter
 	baseQuery := b.ComponentReportGenerator.client.BQ.Query(baseString + b.groupByQuery)
 
+	basis := b.ComponentReportGenerator.testDetailsBasis()
 	baseQuery.Parameters = append(baseQuery.Parameters, b.queryParameters...)
 	baseQuery.Parameters = append(baseQuery.Parameters, []bigquery.QueryParameter{
 		{
 			Name:  "From",
-			Value: b.ComponentReportGenerator.BaseRelease.Start,
+			Value: basis.EffectiveStart(),
 		},
 		{
 			Name:  "To",
-			Value: b.ComponentReportGenerator.BaseRelease.End,
+			Value: basis.End,
 		},
 		{
 			Name:  "BaseRelease",
-			Value: b.ComponentReportGenerator.BaseRelease.Release,
+			Value: basis.Release,
 		},
 	}...)
 
@@ -483,7 +887,7 @@ func (c *componentReportGenerator) getSampleJobRunTestStatus(commonQuery string,
 		ComponentReportGenerator: c,
 	}
 
-	componentReportTestStatus, errs := getDataFromCacheOrGenerate[apitype.ComponentJobRunTestReportStatus](c.client.Cache, c.cacheOption, GetPrefixedCacheKey("SampleJobRunTestStatus~", generator), generator.queryTestStatus, apitype.ComponentJobRunTestReportStatus{})
+	componentReportTestStatus, errs := getDataFromCacheOrGenerate[apitype.ComponentJobRunTestReportStatus](c.client.Cache, c.cacheOption, c.testDetailsQueryCacheKey("SampleJobRunTestStatus~", c.ExcludeFirstPRRuns), generator.queryTestStatus, apitype.ComponentJobRunTestReportStatus{})
 
 	if len(errs) > 0 {
 		return nil, errs
@@ -494,6 +898,11 @@ func (c *componentReportGenerator) getSampleJobRunTestStatus(commonQuery string,
 
 func (s *sampleJobRunTestQueryGenerator) queryTestStatus() (apitype.ComponentJobRunTestReportStatus, []error) {
 	sampleString := s.commonQuery + ` AND branch = @SampleRelease`
+	if s.ComponentReportGenerator.ExcludeFirstPRRuns {
+		sampleString += presubmitJobsFilter
+	} else {
+		sampleString += periodicJobsFilter
+	}
 	sampleQuery := s.ComponentReportGenerator.client.BQ.Query(sampleString + s.groupByQuery)
 	sampleQuery.Parameters = append(sampleQuery.Parameters, s.queryParameters...)
 	sampleQuery.Parameters = append(sampleQuery.Parameters, []bigquery.QueryParameter{
@@ -563,12 +972,14 @@ func (c *componentReportGenerator) getCommonTestStatusQuery() (string, string, [
 						COUNT(cm.id) AS total_count,
 						SUM(success_val) AS success_count,
 						SUM(flake_count) AS flake_count,
+						COUNT(DISTINCT IF(success_val = 0 AND flake_count = 0, prowjob_name, NULL)) AS failing_job_count,
+						COUNT(DISTINCT testsuite) AS suite_count,
 						ANY_VALUE(cm.component) AS component,
 						ANY_VALUE(cm.capabilities) AS capabilities,
 						ANY_VALUE(cm.jira_component) AS jira_component,
 						ANY_VALUE(cm.jira_component_id) AS jira_component_id
 					FROM (%s)
-					INNER JOIN latest_component_mapping cm ON testsuite = cm.suite AND test_name = cm.name`, c.client.Dataset, c.client.Dataset, fmt.Sprintf(dedupedJunitTable, c.client.Dataset))
+					INNER JOIN latest_component_mapping cm ON testsuite = cm.suite AND test_name = cm.name`, c.client.Dataset, c.client.Dataset, junitTable(c.JunitAggregation, c.client.Dataset))
 
 	groupString := `
 					GROUP BY
@@ -578,6 +989,10 @@ func (c *componentReportGenerator) getCommonTestStatusQuery() (string, string, [
 						platform,
 						flat_variants,
 						cm.id `
+	if c.SeparateMigratedSuites {
+		groupString += `,
+						testsuite `
+	}
 
 	queryString += `
 					WHERE cm.staff_approved_obsolete = false AND (prowjob_name LIKE 'periodic-%%' OR prowjob_name LIKE 'release-%%' OR prowjob_name LIKE 'aggregator-%%') AND NOT REGEXP_CONTAINS(prowjob_name, @IgnoredJobs)`
@@ -599,12 +1014,28 @@ func (c *componentReportGenerator) getCommonTestStatusQuery() (string, string, [
 		})
 	}
 	if c.Arch != "" {
-		queryString += ` AND arch = @Arch`
+		// when cross comparing, base and sample each filter on their own arch
+		if c.BaseArch == "" {
+			queryString += ` AND arch = @Arch`
+		}
 		commonParams = append(commonParams, bigquery.QueryParameter{
 			Name:  "Arch",
 			Value: c.Arch,
 		})
 	}
+	if c.BaseArch != "" {
+		commonParams = append(commonParams, bigquery.QueryParameter{
+			Name:  "BaseArch",
+			Value: c.BaseArch,
+		})
+	}
+	if c.Suite != "" {
+		queryString += ` AND testsuite = @TestSuite`
+		commonParams = append(commonParams, bigquery.QueryParameter{
+			Name:  "TestSuite",
+			Value: c.Suite,
+		})
+	}
 	if c.Capability != "" {
 		queryString += " AND @Capability in UNNEST(capabilities)"
 		commonParams = append(commonParams, bigquery.QueryParameter{
@@ -682,10 +1113,41 @@ func (c *componentReportGenerator) getCommonTestStatusQuery() (string, string, [
 		}
 	}
 
+	if len(c.IncludeJobs) > 0 {
+		includeJobsFilter, includeJobsParams := c.getIncludeJobsFilter()
+		queryString += includeJobsFilter
+		commonParams = append(commonParams, includeJobsParams...)
+	}
+
 	return queryString, groupString, commonParams
 
 }
 
+// getIncludeJobsFilter restricts a query to the requested jobs. Jobs are requested by their
+// normalized name, so the normalization done by normalizeProwJobName is repeated in SQL.
+func (c *componentReportGenerator) getIncludeJobsFilter() (string, []bigquery.QueryParameter) {
+	normalizedName := "prowjob_name"
+	params := []bigquery.QueryParameter{}
+	for i, normalization := range c.jobNameNormalizations() {
+		patternParam := fmt.Sprintf("NormalizePattern%d", i)
+		replacementParam := fmt.Sprintf("NormalizeReplacement%d", i)
+		normalizedName = fmt.Sprintf("REGEXP_REPLACE(%s, @%s, @%s)", normalizedName, patternParam, replacementParam)
+		params = append(params, bigquery.QueryParameter{
+			Name:  patternParam,
+			Value: normalization.pattern.String(),
+		}, bigquery.QueryParameter{
+			Name: replacementParam,
+			// backslashes refer to groups in bigquery replacements, the replacement is literal
+			Value: strings.ReplaceAll(normalization.replacement, `\`, `\\`),
+		})
+	}
+	params = append(params, bigquery.QueryParameter{
+		Name:  "IncludeJobs",
+		Value: c.IncludeJobs,
+	})
+	return ` AND ` + normalizedName + ` IN UNNEST(@IncludeJobs)`, params
+}
+
 type baseQueryGenerator struct {
 	client                   *bqcachedclient.Client
 	cacheOption              cache.RequestOptions
@@ -723,34 +1185,97 @@ func (c *componentReportGenerator) getBaseQueryStatus(commonQuery string,
 func (b *baseQueryGenerator) queryTestStatus() (apitype.ComponentReportTestStatus, []error) {
 	before := time.Now()
 	errs := []error{}
-	baseString := b.commonQuery + ` AND branch = @BaseRelease`
-	baseQuery := b.client.BQ.Query(baseString + b.groupByQuery)
+	basisQueries, err := getBasisQueries(b.ComponentReportGenerator.BaseRelease)
+	if err != nil {
+		return apitype.ComponentReportTestStatus{}, []error{err}
+	}
 
-	baseQuery.Parameters = append(baseQuery.Parameters, b.queryParameters...)
-	baseQuery.Parameters = append(baseQuery.Parameters, []bigquery.QueryParameter{
-		{
-			Name:  "From",
-			Value: b.ComponentReportGenerator.BaseRelease.Start,
-		},
-		{
-			Name:  "To",
-			Value: b.ComponentReportGenerator.BaseRelease.End,
-		},
-		{
-			Name:  "BaseRelease",
-			Value: b.ComponentReportGenerator.BaseRelease.Release,
-		},
-	}...)
+	// each variant override is queried separately against its own release, then merged
+	parts := []apitype.ComponentReportTestStatus{}
+	for _, basis := range basisQueries {
+		baseString := b.commonQuery + ` AND branch = @BaseRelease` + basis.filter
+		if b.ComponentReportGenerator.BaseArch != "" {
+			baseString += ` AND arch = @BaseArch`
+		}
+		baseQuery := b.client.BQ.Query(baseString + b.groupByQuery)
+
+		baseQuery.Parameters = append(baseQuery.Parameters, b.queryParameters...)
+		baseQuery.Parameters = append(baseQuery.Parameters, basis.parameters...)
+		baseQuery.Parameters = append(baseQuery.Parameters, []bigquery.QueryParameter{
+			{
+				Name:  "From",
+				Value: basis.release.EffectiveStart(),
+			},
+			{
+				Name:  "To",
+				Value: basis.release.End,
+			},
+			{
+				Name:  "BaseRelease",
+				Value: basis.release.Release,
+			},
+		}...)
 
-	baseStatus, baseErrs := fetchTestStatus(baseQuery)
+		baseStatus, baseErrs := fetchTestStatus(baseQuery, b.ComponentReportGenerator.SeparateMigratedSuites)
 
-	if len(baseErrs) != 0 {
-		errs = append(errs, baseErrs...)
+		if len(baseErrs) != 0 {
+			errs = append(errs, baseErrs...)
+		}
+		parts = append(parts, apitype.ComponentReportTestStatus{BaseStatus: baseStatus})
 	}
+	merged := apitype.MergeReportTestStatus(parts...)
+
+	log.Infof("Base QueryTestStatus completed in %s with %d base results from db", time.Since(before), len(merged.BaseStatus))
+
+	return apitype.ComponentReportTestStatus{BaseStatus: merged.BaseStatus}, errs
+}
 
-	log.Infof("Base QueryTestStatus completed in %s with %d base results from db", time.Since(before), len(baseStatus))
+// basisOverrideColumns are the junit columns a basis release override can be conditioned on.
+var basisOverrideColumns = sets.NewString("platform", "arch", "network", "upgrade")
 
-	return apitype.ComponentReportTestStatus{BaseStatus: baseStatus}, errs
+// basisQuery is the release and additional filter for one of the queries making up the basis.
+type basisQuery struct {
+	release    apitype.ComponentReportRequestReleaseOptions
+	filter     string
+	parameters []bigquery.QueryParameter
+}
+
+// getBasisQueries splits the basis into one query per variant override, followed by the
+// default release for everything not matched by an override. Earlier overrides take
+// precedence, so each query excludes the variants of the overrides before it.
+func getBasisQueries(baseRelease apitype.ComponentReportRequestReleaseOptions) ([]basisQuery, error) {
+	queries := []basisQuery{}
+	exclusions := ""
+	parameters := []bigquery.QueryParameter{}
+	for i, override := range baseRelease.VariantOverrides {
+		if !basisOverrideColumns.Has(override.Variant) {
+			return nil, fmt.Errorf("unsupported variant %q for basis release override", override.Variant)
+		}
+		paramName := fmt.Sprintf("BasisOverride%d", i)
+		parameters = append(parameters, bigquery.QueryParameter{Name: paramName, Value: override.Value})
+		queries = append(queries, basisQuery{
+			release: apitype.ComponentReportRequestReleaseOptions{
+				Release:   override.Release,
+				Start:     override.Start,
+				End:       override.End,
+				MaxRunAge: baseRelease.MaxRunAge,
+			},
+			filter:     exclusions + fmt.Sprintf(" AND %s = @%s", override.Variant, paramName),
+			parameters: append([]bigquery.QueryParameter{}, parameters...),
+		})
+		exclusions += fmt.Sprintf(" AND %s != @%s", override.Variant, paramName)
+	}
+	queries = append(queries, basisQuery{
+		release: apitype.ComponentReportRequestReleaseOptions{
+			Release:   baseRelease.Release,
+			Start:     baseRelease.Start,
+			End:       baseRelease.End,
+			MaxRunAge: baseRelease.MaxRunAge,
+		},
+		filter:     exclusions,
+		parameters: parameters,
+	})
+	return queries, nil
 }
 
 type sampleQueryGenerator struct {
@@ -782,11 +1307,19 @@ func (c *componentReportGenerator) getSampleQueryStatus(
 	return componentReportTestStatus.SampleStatus, nil
 }
 
+func (s *sampleQueryGenerator) queryString() string {
+	sampleString := s.commonQuery + ` AND branch = @SampleRelease`
+	if s.ComponentReportGenerator.BaseArch != "" {
+		// the common query leaves the arch out when cross comparing
+		sampleString += ` AND arch = @Arch`
+	}
+	return sampleString + s.groupByQuery
+}
+
 func (s *sampleQueryGenerator) queryTestStatus() (apitype.ComponentReportTestStatus, []error) {
 	before := time.Now()
 	errs := []error{}
-	sampleString := s.commonQuery + ` AND branch = @SampleRelease`
-	sampleQuery := s.client.BQ.Query(sampleString + s.groupByQuery)
+	sampleQuery := s.client.BQ.Query(s.queryString())
 	sampleQuery.Parameters = append(sampleQuery.Parameters, s.queryParameters...)
 	sampleQuery.Parameters = append(sampleQuery.Parameters, []bigquery.QueryParameter{
 		{
@@ -803,7 +1336,7 @@ func (s *sampleQueryGenerator) queryTestStatus() (apitype.ComponentReportTestSta
 		},
 	}...)
 
-	sampleStatus, sampleErrs := fetchTestStatus(sampleQuery)
+	sampleStatus, sampleErrs := fetchTestStatus(sampleQuery, s.ComponentReportGenerator.SeparateMigratedSuites)
 
 	if len(sampleErrs) != 0 {
 		errs = append(errs, sampleErrs...)
@@ -869,10 +1402,20 @@ func testToComponentAndCapability(test apitype.ComponentTestIdentification, stat
 	return stats.Component, stats.Capabilities
 }
 
+// componentAndCapabilities returns the component and capabilities of a test from the getter, with
+// the capabilities replaced by those of the CapabilityOverrides for the test, if any.
+func (c *componentReportGenerator) componentAndCapabilities(test apitype.ComponentTestIdentification, stats apitype.ComponentTestStatus) (string, []string) {
+	component, capabilities := componentAndCapabilityGetter(test, stats)
+	if override, ok := c.CapabilityOverrides[test.TestID]; ok {
+		capabilities = override
+	}
+	return component, capabilities
+}
+
 // getRowColumnIdentifications defines the rows and columns since they are variable. For rows, different pages have different row titles (component, capability etc)
 // Columns titles depends on the groupBy parameter user requests. A particular test can belong to multiple rows of different capabilities.
 func (c *componentReportGenerator) getRowColumnIdentifications(test apitype.ComponentTestIdentification, stats apitype.ComponentTestStatus) ([]apitype.ComponentReportRowIdentification, []apitype.ComponentReportColumnIdentification) {
-	component, capabilities := componentAndCapabilityGetter(test, stats)
+	component, capabilities := c.componentAndCapabilities(test, stats)
 	rows := []apitype.ComponentReportRowIdentification{}
 	// First Page with no component requested
 	if c.Component == "" {
@@ -920,7 +1463,11 @@ func (c *componentReportGenerator) getRowColumnIdentifications(test apitype.Comp
 		column.Arch = test.Arch
 		column.Upgrade = test.Upgrade
 		column.Variant = test.FlatVariants
+		column.Suite = stats.TestSuite
 		columns = append(columns, column)
+	} else if c.GroupBy == "" {
+		// without any grouping every test of a row falls in a single aggregate column
+		columns = append(columns, apitype.ComponentReportColumnIdentification{})
 	} else {
 		groups := sets.NewString(strings.Split(c.GroupBy, ",")...)
 		column := apitype.ComponentReportColumnIdentification{}
@@ -939,13 +1486,24 @@ func (c *componentReportGenerator) getRowColumnIdentifications(test apitype.Comp
 		if groups.Has("variants") {
 			column.Variant = test.FlatVariants
 		}
+		if groups.Has("suite") {
+			column.Suite = stats.TestSuite
+		}
 		columns = append(columns, column)
 	}
+	if c.BaseArch != "" {
+		// columns are labeled with the sample arch, record the base it was compared against
+		for i := range columns {
+			if columns[i].Arch != "" {
+				columns[i].BaseArch = c.BaseArch
+			}
+		}
+	}
 
 	return rows, columns
 }
 
-func fetchTestStatus(query *bigquery.Query) (map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus, []error) {
+func fetchTestStatus(query *bigquery.Query, separateSuites bool) (map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus, []error) {
 	errs := []error{}
 	status := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{}
 	log.Infof("Fetching test status with:\n%s\nParameters:\n%+v\n", query.Q, query.Parameters)
@@ -976,15 +1534,20 @@ func fetchTestStatus(query *bigquery.Query) (map[apitype.ComponentTestIdentifica
 			Platform:     testStatus.Platform,
 			FlatVariants: testStatus.FlatVariants,
 		}
+		if separateSuites {
+			testIdentification.TestSuite = testStatus.TestSuite
+		}
 		status[testIdentification] = apitype.ComponentTestStatus{
-			TestName:     testStatus.TestName,
-			TestSuite:    testStatus.TestSuite,
-			Component:    testStatus.Component,
-			Capabilities: testStatus.Capabilities,
-			Variants:     testStatus.Variants,
-			TotalCount:   testStatus.TotalCount,
-			FlakeCount:   testStatus.FlakeCount,
-			SuccessCount: testStatus.SuccessCount,
+			TestName:        testStatus.TestName,
+			TestSuite:       testStatus.TestSuite,
+			Component:       testStatus.Component,
+			Capabilities:    testStatus.Capabilities,
+			Variants:        testStatus.Variants,
+			TotalCount:      testStatus.TotalCount,
+			FlakeCount:      testStatus.FlakeCount,
+			SuccessCount:    testStatus.SuccessCount,
+			FailingJobCount: testStatus.FailingJobCount,
+			SuiteCount:      testStatus.SuiteCount,
 		}
 		log.Tracef("testStatus is %+v", testStatus)
 	}
@@ -1020,25 +1583,70 @@ func previousRelease(release string) (string, error) {
 	return prev, err
 }
 
+// Some jobs encode frequency in their name, which can change
+var prowJobFrequencyRegexp = regexp.MustCompile(`-f\d+`)
+
 func (c *componentReportGenerator) normalizeProwJobName(prowName string) string {
 	name := prowName
-	if c.BaseRelease.Release != "" {
-		name = strings.ReplaceAll(name, c.BaseRelease.Release, "X.X")
-		if prev, err := previousRelease(c.BaseRelease.Release); err == nil {
-			name = strings.ReplaceAll(name, prev, "X.X")
+	for _, normalization := range c.jobNameNormalizations() {
+		name = normalization.pattern.ReplaceAllLiteralString(name, normalization.replacement)
+	}
+
+	return name
+}
+
+type jobNameNormalization struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// jobNameNormalizations are the substitutions normalizing job names, in the order they are
+// applied. Unless others are requested they replace the releases with X.X and the frequency
+// with -fXX. Invalid patterns are skipped.
+func (c *componentReportGenerator) jobNameNormalizations() []jobNameNormalization {
+	requested := c.JobNameNormalizations
+	if len(requested) == 0 {
+		for _, release := range c.normalizedReleases() {
+			requested = append(requested, apitype.JobNameNormalization{Pattern: regexp.QuoteMeta(release), Replacement: "X.X"})
 		}
+		requested = append(requested, apitype.JobNameNormalization{Pattern: prowJobFrequencyRegexp.String(), Replacement: "-fXX"})
 	}
-	if c.SampleRelease.Release != "" {
-		name = strings.ReplaceAll(name, c.SampleRelease.Release, "X.X")
-		if prev, err := previousRelease(c.SampleRelease.Release); err == nil {
-			name = strings.ReplaceAll(name, prev, "X.X")
+	normalizations := []jobNameNormalization{}
+	for _, normalization := range requested {
+		pattern, err := regexp.Compile(normalization.Pattern)
+		if err != nil {
+			log.WithError(err).Errorf("skipping invalid job name normalization %q", normalization.Pattern)
+			continue
 		}
+		normalizations = append(normalizations, jobNameNormalization{pattern: pattern, replacement: normalization.Replacement})
 	}
-	// Some jobs encode frequency in their name, which can change
-	re := regexp.MustCompile(`-f\d+`)
-	name = re.ReplaceAllString(name, "-fXX")
+	return normalizations
+}
 
-	return name
+// normalizedReleases are the releases replaced in job names when normalizing them, in the
+// order they are replaced. When base and sample are windows of the same release, job names match
+// as is, so the previous release is kept. Replacing it too would merge jobs such as minor and
+// micro upgrades.
+func (c *componentReportGenerator) normalizedReleases() []string {
+	// job names of an overridden basis carry the override's release
+	basis := c.testDetailsBasis().Release
+	if basis == c.SampleRelease.Release {
+		if basis == "" {
+			return []string{}
+		}
+		return []string{basis}
+	}
+	releases := []string{}
+	for _, release := range []string{basis, c.SampleRelease.Release} {
+		if release == "" {
+			continue
+		}
+		releases = append(releases, release)
+		if prev, err := previousRelease(release); err == nil {
+			releases = append(releases, prev)
+		}
+	}
+	return releases
 }
 
 func (c *componentReportGenerator) fetchJobRunTestStatus(query *bigquery.Query) (map[string][]apitype.ComponentJobRunTestStatusRow, []error) {
@@ -1080,33 +1688,27 @@ type cellStatus struct {
 	status           apitype.ComponentReportStatus
 	regressedTests   []apitype.ComponentReportTestSummary
 	triagedIncidents []apitype.ComponentReportTriageIncidentSummary
+	improvedTests    []apitype.ComponentReportTestSummary
 }
 
-func getNewCellStatus(testID apitype.ComponentReportTestIdentification,
-	reportStatus apitype.ComponentReportStatus, existingCellStatus *cellStatus,
+func getNewCellStatus(testSummary apitype.ComponentReportTestSummary,
+	existingCellStatus *cellStatus,
 	triagedIncidents []apitype.TriagedIncident,
 	openRegressions []apitype.TestRegression) cellStatus {
+	reportStatus := testSummary.Status
 	var newCellStatus cellStatus
 	if existingCellStatus != nil {
-		if (reportStatus < apitype.NotSignificant && reportStatus < existingCellStatus.status) ||
-			(existingCellStatus.status == apitype.NotSignificant && reportStatus == apitype.SignificantImprovement) {
-			// We want to show the significant improvement if assessment is not regression
-			newCellStatus.status = reportStatus
-		} else {
-			newCellStatus.status = existingCellStatus.status
-		}
+		newCellStatus.status = combineCellStatus(existingCellStatus.status, reportStatus)
 		newCellStatus.regressedTests = existingCellStatus.regressedTests
 		newCellStatus.triagedIncidents = existingCellStatus.triagedIncidents
+		newCellStatus.improvedTests = existingCellStatus.improvedTests
 	} else {
 		newCellStatus.status = reportStatus
 	}
 	// don't show triaged regressions in the regressed tests
 	// need a new UI to show active triaged incidents
 	if reportStatus < apitype.ExtremeTriagedRegression {
-		rt := apitype.ComponentReportTestSummary{
-			ComponentReportTestIdentification: testID,
-			Status:                            reportStatus,
-		}
+		rt := testSummary
 		if len(openRegressions) > 0 {
 			release := openRegressions[0].Release
 			or := tracker.FindOpenRegression(release, rt, openRegressions)
@@ -1115,13 +1717,11 @@ func getNewCellStatus(testID apitype.ComponentReportTestIdentification,
 			}
 		}
 		newCellStatus.regressedTests = append(newCellStatus.regressedTests, rt)
-	} else if reportStatus < apitype.MissingSample {
+	} else if reportStatus <= apitype.SignificantTriagedRegression {
 		ti := apitype.ComponentReportTriageIncidentSummary{
-			TriagedIncidents: triagedIncidents,
-			ComponentReportTestSummary: apitype.ComponentReportTestSummary{
-				ComponentReportTestIdentification: testID,
-				Status:                            reportStatus,
-			}}
+			TriagedIncidents:           triagedIncidents,
+			ComponentReportTestSummary: testSummary,
+		}
 		if len(openRegressions) > 0 {
 			release := openRegressions[0].Release
 			or := tracker.FindOpenRegression(release, ti.ComponentReportTestSummary, openRegressions)
@@ -1130,14 +1730,114 @@ func getNewCellStatus(testID apitype.ComponentReportTestIdentification,
 			}
 		}
 		newCellStatus.triagedIncidents = append(newCellStatus.triagedIncidents, ti)
+	} else if reportStatus == apitype.SignificantImprovement {
+		newCellStatus.improvedTests = append(newCellStatus.improvedTests, testSummary)
 	}
 	return newCellStatus
 }
 
-func updateCellStatus(rowIdentifications []apitype.ComponentReportRowIdentification,
+// combineCellStatus returns the status of a cell after adding a test with reportStatus to it.
+func combineCellStatus(existing, reportStatus apitype.ComponentReportStatus) apitype.ComponentReportStatus {
+	if (reportStatus.WorseThan(apitype.NotSignificant) && reportStatus.WorseThan(existing)) ||
+		(existing == apitype.NotSignificant && reportStatus == apitype.SignificantImprovement) {
+		// We want to show the significant improvement if assessment is not regression
+		return reportStatus
+	}
+	return existing
+}
+
+// updateCapabilityStatuses rolls the status of a test up into each of its capabilities for the
+// cells it belongs to, so rows above the capability level can show which capability regressed.
+func (c *componentReportGenerator) updateCapabilityStatuses(test apitype.ComponentTestIdentification,
+	stats apitype.ComponentTestStatus,
+	rowIdentifications []apitype.ComponentReportRowIdentification,
 	columnIdentifications []apitype.ComponentReportColumnIdentification,
-	testID apitype.ComponentReportTestIdentification,
 	reportStatus apitype.ComponentReportStatus,
+	capabilityStatuses map[apitype.ComponentReportRowIdentification]map[apitype.ComponentReportColumnIdentification]map[string]apitype.ComponentReportStatus) {
+	_, capabilities := c.componentAndCapabilities(test, stats)
+	for _, rowIdentification := range rowIdentifications {
+		// capability rows already are the breakdown
+		if rowIdentification.Capability != "" {
+			continue
+		}
+		row, ok := capabilityStatuses[rowIdentification]
+		if !ok {
+			row = map[apitype.ComponentReportColumnIdentification]map[string]apitype.ComponentReportStatus{}
+			capabilityStatuses[rowIdentification] = row
+		}
+		for _, columnIdentification := range columnIdentifications {
+			cell, ok := row[columnIdentification]
+			if !ok {
+				cell = map[string]apitype.ComponentReportStatus{}
+				row[columnIdentification] = cell
+			}
+			for _, capability := range capabilities {
+				if existing, ok := cell[capability]; ok {
+					cell[capability] = combineCellStatus(existing, reportStatus)
+				} else {
+					cell[capability] = reportStatus
+				}
+			}
+		}
+	}
+}
+
+// appendPValue records the p-value of a test in each of the cells it belongs to.
+func appendPValue(rowIdentifications []apitype.ComponentReportRowIdentification,
+	columnIdentifications []apitype.ComponentReportColumnIdentification,
+	pValue float64,
+	pValues map[apitype.ComponentReportRowIdentification]map[apitype.ComponentReportColumnIdentification][]float64) {
+	for _, rowIdentification := range rowIdentifications {
+		row, ok := pValues[rowIdentification]
+		if !ok {
+			row = map[apitype.ComponentReportColumnIdentification][]float64{}
+			pValues[rowIdentification] = row
+		}
+		for _, columnIdentification := range columnIdentifications {
+			row[columnIdentification] = append(row[columnIdentification], pValue)
+		}
+	}
+}
+
+// cellFlakeCounts are the flakes and runs of all tests of a cell, see IncludeFlakeRates.
+type cellFlakeCounts struct {
+	sampleFlakes, sampleTotal, baseFlakes, baseTotal int
+}
+
+// addFlakeCounts adds the flakes and runs of a test to each of the cells it belongs to.
+func addFlakeCounts(rowIdentifications []apitype.ComponentReportRowIdentification,
+	columnIdentifications []apitype.ComponentReportColumnIdentification,
+	counts cellFlakeCounts,
+	flakeCounts map[apitype.ComponentReportRowIdentification]map[apitype.ComponentReportColumnIdentification]cellFlakeCounts) {
+	for _, rowIdentification := range rowIdentifications {
+		row, ok := flakeCounts[rowIdentification]
+		if !ok {
+			row = map[apitype.ComponentReportColumnIdentification]cellFlakeCounts{}
+			flakeCounts[rowIdentification] = row
+		}
+		for _, columnIdentification := range columnIdentifications {
+			cell := row[columnIdentification]
+			cell.sampleFlakes += counts.sampleFlakes
+			cell.sampleTotal += counts.sampleTotal
+			cell.baseFlakes += counts.baseFlakes
+			cell.baseTotal += counts.baseTotal
+			row[columnIdentification] = cell
+		}
+	}
+}
+
+// flakeRate returns flakes over total, nil without any runs.
+func flakeRate(flakes, total int) *float64 {
+	if total == 0 {
+		return nil
+	}
+	rate := float64(flakes) / float64(total)
+	return &rate
+}
+
+func updateCellStatus(rowIdentifications []apitype.ComponentReportRowIdentification,
+	columnIdentifications []apitype.ComponentReportColumnIdentification,
+	testSummary apitype.ComponentReportTestSummary,
 	status map[apitype.ComponentReportRowIdentification]map[apitype.ComponentReportColumnIdentification]cellStatus,
 	allRows map[apitype.ComponentReportRowIdentification]struct{},
 	allColumns map[apitype.ComponentReportColumnIdentification]struct{},
@@ -1153,7 +1853,7 @@ func updateCellStatus(rowIdentifications []apitype.ComponentReportRowIdentificat
 		// Each test might have multiple Capabilities. Initial ID just pick the first on
 		// the list. If we are on a page with specific capability, this needs to be rewritten.
 		if rowIdentification.Capability != "" {
-			testID.Capability = rowIdentification.Capability
+			testSummary.Capability = rowIdentification.Capability
 		}
 		if _, ok := allRows[rowIdentification]; !ok {
 			allRows[rowIdentification] = struct{}{}
@@ -1162,16 +1862,16 @@ func updateCellStatus(rowIdentifications []apitype.ComponentReportRowIdentificat
 		if !ok {
 			row = map[apitype.ComponentReportColumnIdentification]cellStatus{}
 			for _, columnIdentification := range columnIdentifications {
-				row[columnIdentification] = getNewCellStatus(testID, reportStatus, nil, triagedIncidents, openRegressions)
+				row[columnIdentification] = getNewCellStatus(testSummary, nil, triagedIncidents, openRegressions)
 				status[rowIdentification] = row
 			}
 		} else {
 			for _, columnIdentification := range columnIdentifications {
 				existing, ok := row[columnIdentification]
 				if !ok {
-					row[columnIdentification] = getNewCellStatus(testID, reportStatus, nil, triagedIncidents, openRegressions)
+					row[columnIdentification] = getNewCellStatus(testSummary, nil, triagedIncidents, openRegressions)
 				} else {
-					row[columnIdentification] = getNewCellStatus(testID, reportStatus, &existing, triagedIncidents, openRegressions)
+					row[columnIdentification] = getNewCellStatus(testSummary, &existing, triagedIncidents, openRegressions)
 				}
 			}
 		}
@@ -1427,6 +2127,11 @@ func (t *triagedIncidentsGenerator) fetchTriagedIssues(query *bigquery.Query) ([
 }
 
 func (c *componentReportGenerator) triagedIncidentsFor(testID apitype.ComponentReportTestIdentification) (int, []apitype.TriagedIncident) {
+	if c.replayedTriagedIncidents != nil {
+		recorded := c.replayedTriagedIncidents[testID]
+		return recorded.ImpactedJobRuns, recorded.TriagedIncidents
+	}
+
 	// handle test case / missing client
 	if c.client == nil {
 		return 0, nil
@@ -1441,6 +2146,13 @@ func (c *componentReportGenerator) triagedIncidentsFor(testID apitype.ComponentR
 		return 0, nil
 	}
 
+	if c.recordedTriagedIncidents != nil && (impactedRuns > 0 || len(triagedIncidents) > 0) {
+		*c.recordedTriagedIncidents = append(*c.recordedTriagedIncidents, RecordedTriagedIncidents{
+			TestID:           testID,
+			ImpactedJobRuns:  impactedRuns,
+			TriagedIncidents: triagedIncidents,
+		})
+	}
 	return impactedRuns, triagedIncidents
 }
 
@@ -1449,29 +2161,175 @@ func (c *componentReportGenerator) generateComponentTestReport(baseStatus map[ap
 	report := apitype.ComponentReport{
 		Rows: []apitype.ComponentReportRow{},
 	}
+	regressionStatuses := c.emitComponentTestReport(baseStatus, sampleStatus, openRegressions, func(row apitype.ComponentReportRow) bool {
+		report.Rows = append(report.Rows, row)
+		return true
+	})
+	if len(regressionStatuses) > 0 {
+		report.RegressionStatuses = regressionStatuses
+	}
+	if c.IncludeCollapsedVariants {
+		report.CollapsedVariants = c.collapsedVariants(baseStatus, sampleStatus)
+	}
+	return report
+}
+
+// aliasVariants keys the status by the identifications with variant aliases applied, merging the
+// stats of tests whose variants become the same. The failing jobs of merged stats are summed, so
+// may count a job twice.
+func aliasVariants(aliases map[string]map[string]string, status map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus) map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus {
+	aliased := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{}
+	for testIdentification, stats := range status {
+		key := testIdentification.WithVariantAliases(aliases)
+		if existing, ok := aliased[key]; ok {
+			existing.TotalCount += stats.TotalCount
+			existing.SuccessCount += stats.SuccessCount
+			existing.FlakeCount += stats.FlakeCount
+			existing.FailingJobCount += stats.FailingJobCount
+			if stats.SuiteCount > existing.SuiteCount {
+				existing.SuiteCount = stats.SuiteCount
+			}
+			stats = existing
+		}
+		aliased[key] = stats
+	}
+	return aliased
+
+}
+
+// alignVariants applies the variant aliases to both statuses and, when cross comparing arches,
+// relabels the base as the sample arch, so the same test is keyed alike on both sides.
+func (c *componentReportGenerator) alignVariants(baseStatus, sampleStatus map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus) (map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus, map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus) {
+	if len(c.VariantAliases) > 0 {
+		baseStatus = aliasVariants(c.VariantAliases, baseStatus)
+		sampleStatus = aliasVariants(c.VariantAliases, sampleStatus)
+	}
+	if c.BaseArch != "" {
+		baseStatus = aliasVariants(map[string]map[string]string{"arch": {c.BaseArch: c.Arch}}, baseStatus)
+	}
+	return baseStatus, sampleStatus
+}
 
+// collapsedVariants returns the groupings, in the order of groupByVariantOrder, left out of the
+// requested GroupBy although the tests ran with more than one value of them, so their cells
+// aggregate over those values. Nothing is collapsed when a single test is requested, as its
+// columns then carry every variant.
+func (c *componentReportGenerator) collapsedVariants(statuses ...map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus) []string {
+	if c.TestID != "" {
+		return nil
+	}
+	groups := sets.NewString(strings.Split(c.GroupBy, ",")...)
+	values := map[string]sets.String{}
+	for _, group := range groupByVariantOrder {
+		values[group] = sets.NewString()
+	}
+	for _, status := range statuses {
+		for test, stats := range status {
+			test = test.WithVariantAliases(c.VariantAliases)
+			values["cloud"].Insert(test.Platform)
+			values["arch"].Insert(test.Arch)
+			values["network"].Insert(test.Network)
+			values["upgrade"].Insert(test.Upgrade)
+			values["variants"].Insert(test.FlatVariants)
+			values["suite"].Insert(stats.TestSuite)
+		}
+	}
+	var collapsed []string
+	for _, group := range groupByVariantOrder {
+		if !groups.Has(group) && values[group].Len() > 1 {
+			collapsed = append(collapsed, group)
+		}
+	}
+	return collapsed
+}
+
+// streamComponentTestReport sends the rows of the report on rows as each is completed, in the
+// order generateComponentTestReport returns them, then closes rows. It stops early when ctx is done.
+func (c *componentReportGenerator) streamComponentTestReport(ctx context.Context, baseStatus map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus,
+	sampleStatus map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus, openRegressions []apitype.TestRegression, rows chan<- apitype.ComponentReportRow) {
+	defer close(rows)
+	c.emitComponentTestReport(baseStatus, sampleStatus, openRegressions, func(row apitype.ComponentReportRow) bool {
+		select {
+		case rows <- row:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	})
+}
+
+// emitComponentTestReport aggregates the test statuses into cells, then builds the report rows one
+// at a time and passes each to emit, regressed rows first. It stops when emit returns false. It
+// returns the status of the test of each of openRegressions the report assessed, by regression ID.
+func (c *componentReportGenerator) emitComponentTestReport(baseStatus map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus,
+	sampleStatus map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus, openRegressions []apitype.TestRegression,
+	emit func(apitype.ComponentReportRow) bool) map[string]apitype.ComponentReportStatus {
+
+	baseStatus, sampleStatus = c.alignVariants(baseStatus, sampleStatus)
 	// aggregatedStatus is the aggregated status based on the requested rows and columns
 	aggregatedStatus := map[apitype.ComponentReportRowIdentification]map[apitype.ComponentReportColumnIdentification]cellStatus{}
 	// allRows and allColumns are used to make sure rows are ordered and all rows have the same columns in the same order
 	allRows := map[apitype.ComponentReportRowIdentification]struct{}{}
 	allColumns := map[apitype.ComponentReportColumnIdentification]struct{}{}
+	// capabilityStatuses is the status of each capability within a cell, only collected when requested
+	capabilityStatuses := map[apitype.ComponentReportRowIdentification]map[apitype.ComponentReportColumnIdentification]map[string]apitype.ComponentReportStatus{}
+	// pValues are the p-values of the tests within a cell, only collected when requested
+	pValues := map[apitype.ComponentReportRowIdentification]map[apitype.ComponentReportColumnIdentification][]float64{}
+	// flakeCounts are the flakes and runs of the tests within a cell, only collected when requested
+	flakeCounts := map[apitype.ComponentReportRowIdentification]map[apitype.ComponentReportColumnIdentification]cellFlakeCounts{}
+	migratedSuites := suiteMigrations(baseStatus, sampleStatus)
+	regressionStatuses := map[string]apitype.ComponentReportStatus{}
+	recordRegressionStatus := func(testSummary apitype.ComponentReportTestSummary) {
+		if regression := tracker.FindOpenRegression(c.SampleRelease.Release, testSummary, openRegressions); regression != nil {
+			regressionStatuses[regression.RegressionID] = testSummary.Status
+		}
+	}
+	if c.MultipleComparisonCorrection != "" {
+		level := c.correctedSignificanceLevel(baseStatus, sampleStatus)
+		c.significanceLevel = &level
+		defer func() { c.significanceLevel = nil }()
+	}
 	// testID is used to identify the most regressed test. With this, we can
 	// create a shortcut link from any page to go straight to the most regressed test page.
 	for testIdentification, baseStats := range baseStatus {
 		testID := buildTestID(baseStats, testIdentification)
 
 		var reportStatus apitype.ComponentReportStatus
+		var decidingFactor apitype.DecidingFactor
+		var fisherExact float64
 		var triagedIncidents []apitype.TriagedIncident
 		var resolvedIssueCompensation int
+		assessor, overriddenConfidence := c.assessorFor(testIdentification, baseStats)
+		minimumBasisRuns := c.MinimumBasisRunsFor(testID.ComponentReportColumnIdentification)
+		weakBasis := minimumBasisRuns > 0 && baseStats.TotalCount < minimumBasisRuns
+		regressionAge := c.regressionAgeDays(testID, openRegressions)
 		sampleStats, ok := sampleStatus[testIdentification]
 		if !ok {
 			reportStatus = apitype.MissingSample
 		} else {
 			approvedRegression := regressionallowances.IntentionalRegressionFor(c.SampleRelease.Release, testID.ComponentReportColumnIdentification, testID.TestID)
 			resolvedIssueCompensation, triagedIncidents = c.triagedIncidentsFor(testID)
-			reportStatus, _ = c.assessComponentStatus(sampleStats.TotalCount, sampleStats.SuccessCount, sampleStats.FlakeCount, baseStats.TotalCount, baseStats.SuccessCount, baseStats.FlakeCount, approvedRegression, resolvedIssueCompensation)
+			reportStatus, fisherExact, decidingFactor = assessor.assessComponentStatus(sampleStats.TotalCount, sampleStats.SuccessCount, sampleStats.FlakeCount, baseStats.TotalCount, baseStats.SuccessCount, baseStats.FlakeCount, approvedRegression, resolvedIssueCompensation)
+
+			if reportStatus <= apitype.SignificantRegression && c.MinimumFailingJobs > 0 && sampleStats.FailingJobCount < c.MinimumFailingJobs {
+				log.Debugf("suppressing regression of %s, failures came from only %d job(s)", testID.TestID, sampleStats.FailingJobCount)
+				reportStatus = apitype.NotSignificant
+				decidingFactor = apitype.DecidingFactorMinimumFailingJobs
+			}
 
-			if reportStatus < apitype.MissingSample && reportStatus > apitype.SignificantRegression {
+			if reportStatus <= apitype.SignificantRegression && c.SuppressWeakBasisRegressions && weakBasis {
+				log.Debugf("suppressing regression of %s, basis has only %d run(s)", testID.TestID, baseStats.TotalCount)
+				reportStatus = apitype.NotSignificant
+				decidingFactor = apitype.DecidingFactorWeakBasis
+			}
+
+			if reportStatus <= apitype.SignificantRegression && c.MinimumRegressionAgeDays > 0 && regressionAge < c.MinimumRegressionAgeDays {
+				log.Debugf("suppressing regression of %s, open for only %d day(s)", testID.TestID, regressionAge)
+				reportStatus = apitype.NotSignificant
+				decidingFactor = apitype.DecidingFactorRegressionAge
+			}
+
+			if reportStatus <= apitype.SignificantTriagedRegression && reportStatus > apitype.SignificantRegression {
 				// we are within the triage range
 				// do we want to show the triage icon or flip reportStatus
 				canClearReportStatus := true
@@ -1493,14 +2351,87 @@ func (c *componentReportGenerator) generateComponentTestReport(baseStatus map[ap
 		}
 		delete(sampleStatus, testIdentification)
 
+		testSummary := apitype.ComponentReportTestSummary{
+			ComponentReportTestIdentification: testID,
+			Status:                            reportStatus,
+			SampleSuccessRate:                 getPassRate(sampleStats, c.FlakeHandling),
+			BaseSuccessRate:                   getPassRate(baseStats, c.FlakeHandling),
+			SingleJobFailures:                 sampleStats.FailingJobCount == 1,
+			DecidingFactor:                    decidingFactor,
+			FisherExact:                       fisherExact,
+			BaseSampleGap:                     c.baseSampleGap(testID.ComponentReportColumnIdentification),
+			SuiteMigration:                    migratedSuites[withoutSuite(testIdentification)],
+			WeakBasis:                         weakBasis,
+			OverriddenConfidence:              overriddenConfidence,
+			RegressionAgeDays:                 regressionAge,
+		}
+		if c.IncludeRawStats && ok {
+			testSummary.ContingencyTable = contingencyTable(sampleStats.TotalCount, sampleStats.SuccessCount, sampleStats.FlakeCount,
+				baseStats.TotalCount, baseStats.SuccessCount, baseStats.FlakeCount, resolvedIssueCompensation)
+		}
+		if c.IncludeZTest {
+			testSummary.ZScore, testSummary.ZPValue = twoProportionZTest(sampleStats.TotalCount, sampleStats.SuccessCount+sampleStats.FlakeCount,
+				baseStats.TotalCount, baseStats.SuccessCount+baseStats.FlakeCount)
+		}
+		if c.Comparison == apitype.ComparisonBayesian && ok {
+			testSummary.CredibleIntervalLower, testSummary.CredibleIntervalUpper = credibleInterval(sampleStats.TotalCount, sampleStats.SuccessCount+sampleStats.FlakeCount,
+				baseStats.TotalCount, baseStats.SuccessCount+baseStats.FlakeCount, assessor.Confidence)
+		}
+		recordRegressionStatus(testSummary)
 		rowIdentifications, columnIdentifications := c.getRowColumnIdentifications(testIdentification, baseStats)
-		updateCellStatus(rowIdentifications, columnIdentifications, testID, reportStatus, aggregatedStatus, allRows, allColumns, triagedIncidents, openRegressions)
+		updateCellStatus(rowIdentifications, columnIdentifications, testSummary, aggregatedStatus, allRows, allColumns, triagedIncidents, openRegressions)
+		if c.IncludeCapabilityStatuses {
+			c.updateCapabilityStatuses(testIdentification, baseStats, rowIdentifications, columnIdentifications, reportStatus, capabilityStatuses)
+		}
+		if c.IncludePValues && decidingFactor.IsSignificanceTest() {
+			appendPValue(rowIdentifications, columnIdentifications, fisherExact, pValues)
+		}
+		if c.IncludeFlakeRates {
+			addFlakeCounts(rowIdentifications, columnIdentifications, cellFlakeCounts{
+				sampleFlakes: sampleStats.FlakeCount,
+				sampleTotal:  sampleStats.TotalCount,
+				baseFlakes:   baseStats.FlakeCount,
+				baseTotal:    baseStats.TotalCount,
+			}, flakeCounts)
+		}
 	}
 	// Those sample ones are missing base stats
 	for testIdentification, sampleStats := range sampleStatus {
 		testID := buildTestID(sampleStats, testIdentification)
+		reportStatus := apitype.MissingBasis
+		var decidingFactor apitype.DecidingFactor
+		// the pass rate floor takes precedence over the missing basis, it is the only assessment
+		// that does not need a basis to compare against
+		regressionAge := c.regressionAgeDays(testID, openRegressions)
+		if c.ExtremePassRateFloor > 0 {
+			component, _ := c.componentAndCapabilities(testIdentification, sampleStats)
+			reportStatus, _, decidingFactor = c.withMinimumFailureFor(component).assessComponentStatus(sampleStats.TotalCount, sampleStats.SuccessCount, sampleStats.FlakeCount, 0, 0, 0, nil, 0)
+			if reportStatus <= apitype.SignificantRegression && c.MinimumRegressionAgeDays > 0 && regressionAge < c.MinimumRegressionAgeDays {
+				reportStatus = apitype.MissingBasis
+				decidingFactor = apitype.DecidingFactorRegressionAge
+			}
+		}
+		testSummary := apitype.ComponentReportTestSummary{
+			ComponentReportTestIdentification: testID,
+			Status:                            reportStatus,
+			SampleSuccessRate:                 getPassRate(sampleStats, c.FlakeHandling),
+			SingleJobFailures:                 sampleStats.FailingJobCount == 1,
+			DecidingFactor:                    decidingFactor,
+			SuiteMigration:                    migratedSuites[withoutSuite(testIdentification)],
+			RegressionAgeDays:                 regressionAge,
+		}
+		recordRegressionStatus(testSummary)
 		rowIdentifications, columnIdentification := c.getRowColumnIdentifications(testIdentification, sampleStats)
-		updateCellStatus(rowIdentifications, columnIdentification, testID, apitype.MissingBasis, aggregatedStatus, allRows, allColumns, nil, openRegressions)
+		updateCellStatus(rowIdentifications, columnIdentification, testSummary, aggregatedStatus, allRows, allColumns, nil, openRegressions)
+		if c.IncludeCapabilityStatuses {
+			c.updateCapabilityStatuses(testIdentification, sampleStats, rowIdentifications, columnIdentification, reportStatus, capabilityStatuses)
+		}
+		if c.IncludeFlakeRates {
+			addFlakeCounts(rowIdentifications, columnIdentification, cellFlakeCounts{
+				sampleFlakes: sampleStats.FlakeCount,
+				sampleTotal:  sampleStats.TotalCount,
+			}, flakeCounts)
+		}
 	}
 
 	// Sort the row identifications
@@ -1537,6 +2468,9 @@ func (c *componentReportGenerator) generateComponentTestReport(baseStatus map[ap
 					less = sortedColumns[i].Upgrade < sortedColumns[j].Upgrade
 					if sortedColumns[i].Upgrade == sortedColumns[j].Upgrade {
 						less = sortedColumns[i].Variant < sortedColumns[j].Variant
+						if sortedColumns[i].Variant == sortedColumns[j].Variant {
+							less = sortedColumns[i].Suite < sortedColumns[j].Suite
+						}
 					}
 				}
 			}
@@ -1544,50 +2478,77 @@ func (c *componentReportGenerator) generateComponentTestReport(baseStatus map[ap
 		return less
 	})
 
-	// Now build the report
-	var regressionRows, goodRows []apitype.ComponentReportRow
-	for _, rowID := range sortedRows {
-		columns, ok := aggregatedStatus[rowID]
-		if !ok {
-			continue
-		}
-		reportRow := apitype.ComponentReportRow{ComponentReportRowIdentification: rowID}
-		hasRegression := false
-		for _, columnID := range sortedColumns {
-			if reportRow.Columns == nil {
-				reportRow.Columns = []apitype.ComponentReportColumn{}
-			}
-			reportColumn := apitype.ComponentReportColumn{ComponentReportColumnIdentification: columnID}
-			status, ok := columns[columnID]
-			if !ok {
-				reportColumn.Status = apitype.MissingBasisAndSample
-			} else {
-				reportColumn.Status = status.status
-				reportColumn.RegressedTests = status.regressedTests
-				sort.Slice(reportColumn.RegressedTests, func(i, j int) bool {
-					return reportColumn.RegressedTests[i].Status < reportColumn.RegressedTests[j].Status
-				})
-				reportColumn.TriagedIncidents = status.triagedIncidents
-				sort.Slice(reportColumn.TriagedIncidents, func(i, j int) bool {
-					return reportColumn.TriagedIncidents[i].Status < reportColumn.TriagedIncidents[j].Status
-				})
+	// Now build the report. Any rows with regression should appear first, so rows are
+	// built in two passes rather than holding on to the good rows.
+	for _, regressed := range []bool{true, false} {
+		for _, rowID := range sortedRows {
+			columns, ok := aggregatedStatus[rowID]
+			if !ok || rowHasRegression(columns) != regressed {
+				continue
 			}
-			reportRow.Columns = append(reportRow.Columns, reportColumn)
-			if reportColumn.Status <= apitype.SignificantTriagedRegression {
-				hasRegression = true
+			if !emit(c.buildReportRow(rowID, columns, sortedColumns, capabilityStatuses[rowID], pValues[rowID], flakeCounts[rowID])) {
+				return regressionStatuses
 			}
 		}
-		// Any rows with regression should appear first, so make two slices
-		// and assemble them later.
-		if hasRegression {
-			regressionRows = append(regressionRows, reportRow)
-		} else {
-			goodRows = append(goodRows, reportRow)
+	}
+	return regressionStatuses
+}
+
+// rowHasRegression returns true if any cell of the row is regressed, including triaged regressions.
+func rowHasRegression(columns map[apitype.ComponentReportColumnIdentification]cellStatus) bool {
+	for _, status := range columns {
+		if status.status <= apitype.SignificantTriagedRegression {
+			return true
 		}
 	}
+	return false
+}
 
-	report.Rows = append(regressionRows, goodRows...)
-	return report
+// buildReportRow builds the row with a column for every one of sortedColumns.
+func (c *componentReportGenerator) buildReportRow(rowID apitype.ComponentReportRowIdentification,
+	columns map[apitype.ComponentReportColumnIdentification]cellStatus,
+	sortedColumns []apitype.ComponentReportColumnIdentification,
+	capabilityStatuses map[apitype.ComponentReportColumnIdentification]map[string]apitype.ComponentReportStatus,
+	pValues map[apitype.ComponentReportColumnIdentification][]float64,
+	flakeCounts map[apitype.ComponentReportColumnIdentification]cellFlakeCounts) apitype.ComponentReportRow {
+	reportRow := apitype.ComponentReportRow{ComponentReportRowIdentification: rowID}
+	for _, columnID := range sortedColumns {
+		if reportRow.Columns == nil {
+			reportRow.Columns = []apitype.ComponentReportColumn{}
+		}
+		reportColumn := apitype.ComponentReportColumn{ComponentReportColumnIdentification: columnID}
+		status, ok := columns[columnID]
+		if !ok {
+			reportColumn.Status = apitype.MissingBasisAndSample
+		} else {
+			reportColumn.Status = status.status
+			reportColumn.RegressedTests = status.regressedTests
+			sort.Slice(reportColumn.RegressedTests, func(i, j int) bool {
+				return reportColumn.RegressedTests[i].Status < reportColumn.RegressedTests[j].Status
+			})
+			reportColumn.TriagedIncidents = status.triagedIncidents
+			sort.Slice(reportColumn.TriagedIncidents, func(i, j int) bool {
+				return reportColumn.TriagedIncidents[i].Status < reportColumn.TriagedIncidents[j].Status
+			})
+			reportColumn.CapabilityStatuses = capabilityStatuses[columnID]
+			reportColumn.PValues = pValues[columnID]
+			if counts, ok := flakeCounts[columnID]; ok {
+				reportColumn.SampleFlakeRate = flakeRate(counts.sampleFlakes, counts.sampleTotal)
+				reportColumn.BaseFlakeRate = flakeRate(counts.baseFlakes, counts.baseTotal)
+			}
+			if c.IncludeImprovementsInList {
+				reportColumn.ImprovedTests = status.improvedTests
+				sort.Slice(reportColumn.ImprovedTests, func(i, j int) bool {
+					return reportColumn.ImprovedTests[i].TestName < reportColumn.ImprovedTests[j].TestName
+				})
+			}
+		}
+		reportRow.Columns = append(reportRow.Columns, reportColumn)
+	}
+	if c.SortColumnsBy == apitype.ColumnSortSeverity {
+		sortColumnsBySeverity(reportRow.Columns)
+	}
+	return reportRow
 }
 
 func buildTestID(stats apitype.ComponentTestStatus, testIdentification apitype.ComponentTestIdentification) apitype.ComponentReportTestIdentification {
@@ -1604,6 +2565,7 @@ func buildTestID(stats apitype.ComponentTestStatus, testIdentification apitype.C
 			Arch:     testIdentification.Arch,
 			Platform: testIdentification.Platform,
 			Variant:  testIdentification.FlatVariants,
+			Suite:    stats.TestSuite,
 		},
 	}
 	// Take the first cap for now. When we reach to a cell with specific capability, we will override the value.
@@ -1621,7 +2583,25 @@ func getFailureCount(status apitype.ComponentJobRunTestStatusRow) int {
 	return failure
 }
 
-func getSuccessRate(success, failure, flake int) float64 {
+// getPassRate returns the pass rate for the aggregated test status, counting flakes as passes
+// unless flakeHandling excludes them.
+func getPassRate(stats apitype.ComponentTestStatus, flakeHandling apitype.FlakeHandling) float64 {
+	failure := stats.TotalCount - stats.SuccessCount - stats.FlakeCount
+	if failure < 0 {
+		failure = 0
+	}
+	return getSuccessRate(stats.SuccessCount, failure, stats.FlakeCount, flakeHandling)
+}
+
+// getSuccessRate returns the pass rate of the counts, counting flakes as passes unless
+// flakeHandling excludes them from both passes and runs. Zero is returned without runs to count.
+func getSuccessRate(success, failure, flake int, flakeHandling apitype.FlakeHandling) float64 {
+	if flakeHandling == apitype.FlakeHandlingExclude {
+		if success+failure == 0 {
+			return 0.0
+		}
+		return float64(success) / float64(success+failure)
+	}
 	total := success + failure + flake
 	if total == 0 {
 		return 0.0
@@ -1629,7 +2609,7 @@ func getSuccessRate(success, failure, flake int) float64 {
 	return float64(success+flake) / float64(total)
 }
 
-func getJobRunStats(stats apitype.ComponentJobRunTestStatusRow, prowURL, gcsBucket string) apitype.ComponentReportTestDetailsJobRunStats {
+func getJobRunStats(stats apitype.ComponentJobRunTestStatusRow, prowURL, gcsBucket string, flakeHandling apitype.FlakeHandling) apitype.ComponentReportTestDetailsJobRunStats {
 	failure := getFailureCount(stats)
 	url := fmt.Sprintf("%s/view/gs/%s/", prowURL, gcsBucket)
 	subs := strings.Split(stats.FilePath, "/artifacts/")
@@ -1638,18 +2618,352 @@ func getJobRunStats(stats apitype.ComponentJobRunTestStatusRow, prowURL, gcsBuck
 	}
 	jobRunStats := apitype.ComponentReportTestDetailsJobRunStats{
 		TestStats: apitype.ComponentReportTestDetailsTestStats{
-			SuccessRate:  getSuccessRate(stats.SuccessCount, failure, stats.FlakeCount),
+			SuccessRate:  getSuccessRate(stats.SuccessCount, failure, stats.FlakeCount, flakeHandling),
 			SuccessCount: stats.SuccessCount,
 			FailureCount: failure,
 			FlakeCount:   stats.FlakeCount,
 		},
-		JobURL: url,
+		JobURL:    url,
+		JobRunID:  stats.ProwJobRunID,
+		StartTime: stats.StartTime,
 	}
 	return jobRunStats
 }
 
+// jobRunStartedAfter orders job runs most recent first. Runs with an unknown (zero) start time
+// are treated as the oldest, rather than sorting ahead of everything else.
+// aggregateJobSuccessRates combines the success rates of the jobs with runs on each side into
+// the overall base and sample success rates, zero when no job ran on that side.
+func aggregateJobSuccessRates(aggregation apitype.JobAggregation, jobStats []apitype.ComponentReportTestDetailsJobStats) (float64, float64) {
+	var baseRates, sampleRates []float64
+	for _, stats := range jobStats {
+		if stats.BaseStats.SuccessCount+stats.BaseStats.FailureCount+stats.BaseStats.FlakeCount > 0 {
+			baseRates = append(baseRates, stats.BaseStats.SuccessRate)
+		}
+		if stats.SampleStats.SuccessCount+stats.SampleStats.FailureCount+stats.SampleStats.FlakeCount > 0 {
+			sampleRates = append(sampleRates, stats.SampleStats.SuccessRate)
+		}
+	}
+	if aggregation == apitype.JobAggregationMean {
+		return mean(baseRates), mean(sampleRates)
+	}
+	return median(baseRates), median(sampleRates)
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+func jobRunStartedAfter(a, b civil.DateTime) bool {
+	if a.IsZero() || b.IsZero() {
+		return !a.IsZero() && b.IsZero()
+	}
+	return a.After(b)
+}
+
+// sortJobRunStats sorts job run stats most recent first, keeping the original order of runs
+// that started at the same or an unknown time.
+func sortJobRunStats(stats []apitype.ComponentReportTestDetailsJobRunStats) {
+	sort.SliceStable(stats, func(i, j int) bool {
+		return jobRunStartedAfter(stats[i].StartTime, stats[j].StartTime)
+	})
+}
+
+// excludeFirstPullRequestRuns drops the earliest run of the test on each pull request. Runs
+// not associated with a pull request are kept as is.
+func excludeFirstPullRequestRuns(status map[string][]apitype.ComponentJobRunTestStatusRow) map[string][]apitype.ComponentJobRunTestStatusRow {
+	type firstRun struct {
+		prowJob string
+		index   int
+	}
+	firstRuns := map[string]firstRun{}
+	for prowJob, rows := range status {
+		for i, row := range rows {
+			if row.PRNumber == "" {
+				continue
+			}
+			pr := fmt.Sprintf("%s/%s#%s", row.PROrg, row.PRRepo, row.PRNumber)
+			first, ok := firstRuns[pr]
+			if !ok || row.StartTime.Before(status[first.prowJob][first.index].StartTime) {
+				firstRuns[pr] = firstRun{prowJob: prowJob, index: i}
+			}
+		}
+	}
+
+	excluded := map[firstRun]struct{}{}
+	for _, first := range firstRuns {
+		excluded[first] = struct{}{}
+	}
+	filtered := map[string][]apitype.ComponentJobRunTestStatusRow{}
+	for prowJob, rows := range status {
+		for i, row := range rows {
+			if _, ok := excluded[firstRun{prowJob: prowJob, index: i}]; ok {
+				continue
+			}
+			filtered[prowJob] = append(filtered[prowJob], row)
+		}
+	}
+	return filtered
+}
+
+// excludeRunsStartedBefore drops runs that started before the given time.
+// jobSetOverlap is the Jaccard index of the (normalized) jobs with runs in base and sample, one
+// when neither has any.
+func jobSetOverlap(baseStatus, sampleStatus map[string][]apitype.ComponentJobRunTestStatusRow) float64 {
+	baseJobs, sampleJobs := sets.NewString(), sets.NewString()
+	for prowJob, rows := range baseStatus {
+		if len(rows) > 0 {
+			baseJobs.Insert(prowJob)
+		}
+	}
+	for prowJob, rows := range sampleStatus {
+		if len(rows) > 0 {
+			sampleJobs.Insert(prowJob)
+		}
+	}
+	union := baseJobs.Union(sampleJobs)
+	if union.Len() == 0 {
+		return 1
+	}
+	return float64(baseJobs.Intersection(sampleJobs).Len()) / float64(union.Len())
+}
+
+// junitTable returns the deduped junit table of the dataset, with the junit files of the same test
+// in the same job run collapsed as requested by the aggregation.
+func junitTable(aggregation apitype.JunitAggregation, dataset string) string {
+	table := fmt.Sprintf(dedupedJunitTable, dataset)
+	var success, flake string
+	switch aggregation {
+	case apitype.JunitAggregationAnyPass:
+		success = "IF(run_flakes = 0 AND run_passes > 0, 1, 0)"
+		flake = "IF(run_flakes > 0, 1, 0)"
+	case apitype.JunitAggregationAllPass:
+		success = "IF(run_failures = 0 AND run_flakes = 0, 1, 0)"
+		flake = "IF(run_failures = 0 AND run_flakes > 0, 1, 0)"
+	case apitype.JunitAggregationMajority:
+		success = "IF(run_passes + run_flakes > run_failures AND run_flakes = 0, 1, 0)"
+		flake = "IF(run_passes + run_flakes > run_failures AND run_flakes > 0, 1, 0)"
+	default:
+		return table
+	}
+	return fmt.Sprintf(junitRunAggregationTable, success, flake, table)
+}
+
+// junitRunOutcome collapses the junit results of a test in a job run, by how many junit files
+// passed, failed and flaked, into whether the run passed and whether it flaked. It is the
+// aggregation junitTable applies in the query.
+func junitRunOutcome(aggregation apitype.JunitAggregation, passes, failures, flakes int) (bool, bool) {
+	switch aggregation {
+	case apitype.JunitAggregationAllPass:
+		return failures == 0 && flakes == 0, failures == 0 && flakes > 0
+	case apitype.JunitAggregationMajority:
+		passed := passes+flakes > failures
+		return passed && flakes == 0, passed && flakes > 0
+	default:
+		return flakes == 0 && passes > 0, flakes > 0
+	}
+}
+
+// aggregateJunitRows collapses the rows of the same job run, one per junit file, into the first
+// of them with the outcome of the run, see junitRunOutcome. Rows without a job run are kept.
+func aggregateJunitRows(aggregation apitype.JunitAggregation, status map[string][]apitype.ComponentJobRunTestStatusRow) map[string][]apitype.ComponentJobRunTestStatusRow {
+	type runCounts struct {
+		index                    int
+		passes, failures, flakes int
+	}
+	aggregated := map[string][]apitype.ComponentJobRunTestStatusRow{}
+	for prowJob, rows := range status {
+		runs := map[string]*runCounts{}
+		jobRows := []apitype.ComponentJobRunTestStatusRow{}
+		for _, row := range rows {
+			if row.TotalCount == 0 {
+				jobRows = append(jobRows, row)
+				continue
+			}
+			counts, ok := runs[row.ProwJobRunID]
+			if !ok || row.ProwJobRunID == "" {
+				counts = &runCounts{index: len(jobRows)}
+				runs[row.ProwJobRunID] = counts
+				jobRows = append(jobRows, row)
+			}
+			switch {
+			case row.FlakeCount > 0:
+				counts.flakes++
+			case row.SuccessCount > 0:
+				counts.passes++
+			default:
+				counts.failures++
+			}
+			passed, flaked := junitRunOutcome(aggregation, counts.passes, counts.failures, counts.flakes)
+			collapsed := &jobRows[counts.index]
+			collapsed.TotalCount = 1
+			collapsed.SuccessCount = 0
+			collapsed.FlakeCount = 0
+			if passed {
+				collapsed.SuccessCount = 1
+			}
+			if flaked {
+				collapsed.FlakeCount = 1
+			}
+		}
+		aggregated[prowJob] = jobRows
+	}
+	return aggregated
+}
+
+// excludeRunsWithoutTest drops the rows of job runs that have no results for the test, returning
+// how many were dropped. Jobs left without runs are dropped entirely.
+func excludeRunsWithoutTest(status map[string][]apitype.ComponentJobRunTestStatusRow) (map[string][]apitype.ComponentJobRunTestStatusRow, int) {
+	filtered := map[string][]apitype.ComponentJobRunTestStatusRow{}
+	excluded := 0
+	for prowJob, rows := range status {
+		for _, row := range rows {
+			if row.TotalCount == 0 {
+				excluded++
+				continue
+			}
+			filtered[prowJob] = append(filtered[prowJob], row)
+		}
+	}
+	return filtered, excluded
+}
+
+func excludeRunsStartedBefore(status map[string][]apitype.ComponentJobRunTestStatusRow, oldest time.Time) map[string][]apitype.ComponentJobRunTestStatusRow {
+	filtered := map[string][]apitype.ComponentJobRunTestStatusRow{}
+	for prowJob, rows := range status {
+		for _, row := range rows {
+			if row.StartTime.In(time.UTC).Before(oldest) {
+				continue
+			}
+			filtered[prowJob] = append(filtered[prowJob], row)
+		}
+	}
+	return filtered
+}
+
+// infraFailedRunIDs returns the prow job run IDs of the runs that failed for infrastructure reasons.
+func infraFailedRunIDs(statuses ...map[string][]apitype.ComponentJobRunTestStatusRow) sets.String {
+	runIDs := sets.NewString()
+	for _, status := range statuses {
+		for _, rows := range status {
+			for _, row := range rows {
+				if row.InfrastructureFailure {
+					runIDs.Insert(row.ProwJobRunID)
+				}
+			}
+		}
+	}
+	return runIDs
+}
+
+// excludeRunsOutsideWindow drops runs that started before start or after end. A zero time leaves
+// that side of the window open.
+func excludeRunsOutsideWindow(status map[string][]apitype.ComponentJobRunTestStatusRow, start, end time.Time) map[string][]apitype.ComponentJobRunTestStatusRow {
+	filtered := map[string][]apitype.ComponentJobRunTestStatusRow{}
+	for prowJob, rows := range status {
+		for _, row := range rows {
+			startTime := row.StartTime.In(time.UTC)
+			if (!start.IsZero() && startTime.Before(start)) || (!end.IsZero() && startTime.After(end)) {
+				continue
+			}
+			filtered[prowJob] = append(filtered[prowJob], row)
+		}
+	}
+	return filtered
+}
+
+// sampleSubWindow intersects the requested sample sub-window with the sample release window.
+func (c *componentReportGenerator) sampleSubWindow() (time.Time, time.Time) {
+	start, end := c.SampleSubWindowStart, c.SampleSubWindowEnd
+	if !c.SampleRelease.Start.IsZero() && c.SampleRelease.Start.After(start) {
+		start = c.SampleRelease.Start
+	}
+	if !c.SampleRelease.End.IsZero() && (end.IsZero() || c.SampleRelease.End.Before(end)) {
+		end = c.SampleRelease.End
+	}
+	return start, end
+}
+
+// excludeJobRuns drops the rows of the job runs with the given prow job run IDs, returning the
+// remaining rows and how many distinct job runs were dropped.
+func excludeJobRuns(status map[string][]apitype.ComponentJobRunTestStatusRow, runIDs sets.String) (map[string][]apitype.ComponentJobRunTestStatusRow, int) {
+	filtered := map[string][]apitype.ComponentJobRunTestStatusRow{}
+	excluded := sets.NewString()
+	for prowJob, rows := range status {
+		for _, row := range rows {
+			if runIDs.Has(row.ProwJobRunID) {
+				excluded.Insert(row.ProwJobRunID)
+				continue
+			}
+			filtered[prowJob] = append(filtered[prowJob], row)
+		}
+	}
+	return filtered, excluded.Len()
+}
+
 func (c *componentReportGenerator) generateComponentTestDetailsReport(baseStatus map[string][]apitype.ComponentJobRunTestStatusRow,
 	sampleStatus map[string][]apitype.ComponentJobRunTestStatusRow) apitype.ComponentReportTestDetails {
+	var excludedJobRuns int
+	if len(c.ExcludedJobRunURLs) > 0 {
+		runIDs := sets.NewString()
+		for _, url := range c.ExcludedJobRunURLs {
+			runIDs.Insert(path.Base(strings.TrimSuffix(url, "/")))
+		}
+		var excludedBase, excludedSample int
+		baseStatus, excludedBase = excludeJobRuns(baseStatus, runIDs)
+		sampleStatus, excludedSample = excludeJobRuns(sampleStatus, runIDs)
+		excludedJobRuns = excludedBase + excludedSample
+	}
+	if c.JunitAggregation != "" {
+		baseStatus = aggregateJunitRows(c.JunitAggregation, baseStatus)
+		sampleStatus = aggregateJunitRows(c.JunitAggregation, sampleStatus)
+	}
+	var runsWithoutTest int
+	if c.ExcludeRunsWithoutTest {
+		var baseWithoutTest, sampleWithoutTest int
+		baseStatus, baseWithoutTest = excludeRunsWithoutTest(baseStatus)
+		sampleStatus, sampleWithoutTest = excludeRunsWithoutTest(sampleStatus)
+		runsWithoutTest = baseWithoutTest + sampleWithoutTest
+	}
+	if !c.SampleSubWindowStart.IsZero() || !c.SampleSubWindowEnd.IsZero() {
+		start, end := c.sampleSubWindow()
+		sampleStatus = excludeRunsOutsideWindow(sampleStatus, start, end)
+	}
+	var infraFailedJobRuns int
+	if c.IgnoreInfraFailures {
+		runIDs := infraFailedRunIDs(baseStatus, sampleStatus)
+		baseStatus, _ = excludeJobRuns(baseStatus, runIDs)
+		sampleStatus, _ = excludeJobRuns(sampleStatus, runIDs)
+		infraFailedJobRuns = runIDs.Len()
+	}
+	if c.ExcludeFirstPRRuns {
+		sampleStatus = excludeFirstPullRequestRuns(sampleStatus)
+	}
+	basis := c.testDetailsBasis()
+	if basis.MaxRunAge > 0 {
+		// the query already applies the max run age, this guards against rows cached before it
+		baseStatus = excludeRunsStartedBefore(baseStatus, basis.EffectiveStart())
+	}
 	result := apitype.ComponentReportTestDetails{
 		ComponentReportTestIdentification: apitype.ComponentReportTestIdentification{
 			ComponentReportRowIdentification: apitype.ComponentReportRowIdentification{
@@ -1666,9 +2980,26 @@ func (c *componentReportGenerator) generateComponentTestDetailsReport(baseStatus
 			},
 		},
 	}
+	// noisier variants may be assessed at their own confidence, see ConfidenceOverrides
+	assessor, overriddenConfidence := c.withConfidenceFor(result.ComponentReportColumnIdentification)
+	assessor = assessor.withMinimumFailureFor(result.Component)
+	result.OverriddenConfidence = overriddenConfidence
+	result.RunsWithoutTest = runsWithoutTest
+	result.SuiteMigration = jobRunSuiteMigration(baseStatus, sampleStatus)
+	result.JobSetOverlap = jobSetOverlap(baseStatus, sampleStatus)
+	if c.MinimumJobSetOverlap > 0 && result.JobSetOverlap*100 < float64(c.MinimumJobSetOverlap) {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("only %.0f%% of the jobs that ran the test ran it in both base and sample, the jobs changed between the releases",
+			result.JobSetOverlap*100))
+	}
+	result.ExcludedJobRuns = excludedJobRuns
+	result.InfraFailedJobRuns = infraFailedJobRuns
 	approvedRegression := regressionallowances.IntentionalRegressionFor(c.SampleRelease.Release, result.ComponentReportColumnIdentification, c.TestID)
 	resolvedIssueCompensation, _ := c.triagedIncidentsFor(result.ComponentReportTestIdentification)
 
+	if c.BucketGranularity != "" {
+		result.SampleSeries = assessor.sampleSeries(sampleStatus)
+	}
+
 	var totalBaseFailure, totalBaseSuccess, totalBaseFlake, totalSampleFailure, totalSampleSuccess, totalSampleFlake int
 	var perJobBaseFailure, perJobBaseSuccess, perJobBaseFlake, perJobSampleFailure, perJobSampleSuccess, perJobSampleFlake int
 	for prowJob, baseStatsList := range baseStatus {
@@ -1689,7 +3020,7 @@ func (c *componentReportGenerator) generateComponentTestDetailsReport(baseStatus
 				result.JiraComponentID = baseStats.JiraComponentID
 			}
 
-			jobStats.BaseJobRunStats = append(jobStats.BaseJobRunStats, getJobRunStats(baseStats, c.prowURL, c.gcsBucket))
+			jobStats.BaseJobRunStats = append(jobStats.BaseJobRunStats, getJobRunStats(baseStats, c.prowURL, c.gcsBucket, c.FlakeHandling))
 			perJobBaseSuccess += baseStats.SuccessCount
 			perJobBaseFlake += baseStats.FlakeCount
 			perJobBaseFailure += getFailureCount(baseStats)
@@ -1703,26 +3034,28 @@ func (c *componentReportGenerator) generateComponentTestDetailsReport(baseStatus
 					result.JiraComponentID = sampleStats.JiraComponentID
 				}
 
-				jobStats.SampleJobRunStats = append(jobStats.SampleJobRunStats, getJobRunStats(sampleStats, c.prowURL, c.gcsBucket))
+				jobStats.SampleJobRunStats = append(jobStats.SampleJobRunStats, getJobRunStats(sampleStats, c.prowURL, c.gcsBucket, c.FlakeHandling))
 				perJobSampleSuccess += sampleStats.SuccessCount
 				perJobSampleFlake += sampleStats.FlakeCount
 				perJobSampleFailure += getFailureCount(sampleStats)
 			}
 			delete(sampleStatus, prowJob)
 		}
+		sortJobRunStats(jobStats.BaseJobRunStats)
+		sortJobRunStats(jobStats.SampleJobRunStats)
 		jobStats.BaseStats.SuccessCount = perJobBaseSuccess
 		jobStats.BaseStats.FlakeCount = perJobBaseFlake
 		jobStats.BaseStats.FailureCount = perJobBaseFailure
-		jobStats.BaseStats.SuccessRate = getSuccessRate(perJobBaseSuccess, perJobBaseFailure, perJobBaseFlake)
+		jobStats.BaseStats.SuccessRate = getSuccessRate(perJobBaseSuccess, perJobBaseFailure, perJobBaseFlake, c.FlakeHandling)
 		jobStats.SampleStats.SuccessCount = perJobSampleSuccess
 		jobStats.SampleStats.FlakeCount = perJobSampleFlake
 		jobStats.SampleStats.FailureCount = perJobSampleFailure
-		jobStats.SampleStats.SuccessRate = getSuccessRate(perJobSampleSuccess, perJobSampleFailure, perJobSampleFlake)
+		jobStats.SampleStats.SuccessRate = getSuccessRate(perJobSampleSuccess, perJobSampleFailure, perJobSampleFlake, c.FlakeHandling)
 		_, _, r, _ := fischer.FisherExactTest(perJobSampleFailure,
 			perJobSampleSuccess,
 			perJobBaseFailure,
 			perJobSampleSuccess)
-		jobStats.Significant = r < 1-float64(c.Confidence)/100
+		jobStats.Significant = r < 1-float64(assessor.Confidence)/100
 
 		result.JobStats = append(result.JobStats, jobStats)
 
@@ -1741,37 +3074,44 @@ func (c *componentReportGenerator) generateComponentTestDetailsReport(baseStatus
 		perJobSampleSuccess = 0
 		perJobSampleFlake = 0
 		for _, sampleStats := range sampleStatsList {
-			jobStats.SampleJobRunStats = append(jobStats.SampleJobRunStats, getJobRunStats(sampleStats, c.prowURL, c.gcsBucket))
+			jobStats.SampleJobRunStats = append(jobStats.SampleJobRunStats, getJobRunStats(sampleStats, c.prowURL, c.gcsBucket, c.FlakeHandling))
 			perJobSampleSuccess += sampleStats.SuccessCount
 			perJobSampleFlake += sampleStats.FlakeCount
 			perJobSampleFailure += getFailureCount(sampleStats)
 		}
+		sortJobRunStats(jobStats.SampleJobRunStats)
 		jobStats.SampleStats.SuccessCount = perJobSampleSuccess
 		jobStats.SampleStats.FlakeCount = perJobSampleFlake
 		jobStats.SampleStats.FailureCount = perJobSampleFailure
-		jobStats.SampleStats.SuccessRate = getSuccessRate(perJobSampleSuccess, perJobSampleFailure, perJobSampleFlake)
+		jobStats.SampleStats.SuccessRate = getSuccessRate(perJobSampleSuccess, perJobSampleFailure, perJobSampleFlake, c.FlakeHandling)
 		result.JobStats = append(result.JobStats, jobStats)
 		_, _, r, _ := fischer.FisherExactTest(perJobSampleFailure,
 			perJobSampleSuccess+perJobSampleFlake,
 			0,
 			0)
-		jobStats.Significant = r < 1-float64(c.Confidence)/100
+		jobStats.Significant = r < 1-float64(assessor.Confidence)/100
 
 		totalSampleFailure += perJobSampleFailure
 		totalSampleSuccess += perJobSampleSuccess
 		totalSampleFlake += perJobSampleFlake
 	}
-	result.BaseStats.Release = c.BaseRelease.Release
+	result.BaseStats.Release = basis.Release
+	if basis.Release != c.BaseRelease.Release {
+		result.RequestedBaseRelease = c.BaseRelease.Release
+	}
 	result.BaseStats.SuccessCount = totalBaseSuccess
 	result.BaseStats.FailureCount = totalBaseFailure
 	result.BaseStats.FlakeCount = totalBaseFlake
-	result.BaseStats.SuccessRate = getSuccessRate(totalBaseSuccess, totalBaseFailure, totalBaseFlake)
+	result.BaseStats.SuccessRate = getSuccessRate(totalBaseSuccess, totalBaseFailure, totalBaseFlake, c.FlakeHandling)
 	result.SampleStats.Release = c.SampleRelease.Release
 	result.SampleStats.SuccessCount = totalSampleSuccess
 	result.SampleStats.FailureCount = totalSampleFailure
 	result.SampleStats.FlakeCount = totalSampleFlake
-	result.SampleStats.SuccessRate = getSuccessRate(totalSampleSuccess, totalSampleFailure, totalSampleFlake)
-	result.ReportStatus, result.FisherExact = c.assessComponentStatus(
+	result.SampleStats.SuccessRate = getSuccessRate(totalSampleSuccess, totalSampleFailure, totalSampleFlake, c.FlakeHandling)
+	if c.JobAggregation != "" {
+		result.BaseStats.SuccessRate, result.SampleStats.SuccessRate = aggregateJobSuccessRates(c.JobAggregation, result.JobStats)
+	}
+	result.ReportStatus, result.FisherExact, result.DecidingFactor = assessor.assessComponentStatus(
 		totalSampleSuccess+totalSampleFailure+totalSampleFlake,
 		totalSampleSuccess,
 		totalSampleFlake,
@@ -1781,25 +3121,127 @@ func (c *componentReportGenerator) generateComponentTestDetailsReport(baseStatus
 		approvedRegression,
 		resolvedIssueCompensation,
 	)
-	sort.Slice(result.JobStats, func(i, j int) bool {
-		return result.JobStats[i].JobName < result.JobStats[j].JobName
-	})
+	if c.IncludeMinimumDetectableEffect {
+		mde := MinimumDetectableEffect(result.BaseStats.ComponentReportTestDetailsTestStats, result.SampleStats.ComponentReportTestDetailsTestStats, assessor.Confidence)
+		result.MinimumDetectableEffect = &mde
+	}
+	if c.IncludeRawStats {
+		result.ContingencyTable = contingencyTable(totalSampleSuccess+totalSampleFailure+totalSampleFlake, totalSampleSuccess, totalSampleFlake,
+			totalBaseSuccess+totalBaseFailure+totalBaseFlake, totalBaseSuccess, totalBaseFlake, resolvedIssueCompensation)
+	}
+	if c.IncludeZTest {
+		result.ZScore, result.ZPValue = twoProportionZTest(totalSampleSuccess+totalSampleFailure+totalSampleFlake, totalSampleSuccess+totalSampleFlake,
+			totalBaseSuccess+totalBaseFailure+totalBaseFlake, totalBaseSuccess+totalBaseFlake)
+	}
+	if c.Comparison == apitype.ComparisonBayesian {
+		result.CredibleIntervalLower, result.CredibleIntervalUpper = credibleInterval(totalSampleSuccess+totalSampleFailure+totalSampleFlake, totalSampleSuccess+totalSampleFlake,
+			totalBaseSuccess+totalBaseFailure+totalBaseFlake, totalBaseSuccess+totalBaseFlake, assessor.Confidence)
+	}
+	if c.SortJobStatsBy == apitype.JobStatsSortContribution {
+		sortJobStatsByContribution(result.JobStats, result.BaseStats.SuccessRate)
+	} else {
+		sort.Slice(result.JobStats, func(i, j int) bool {
+			return result.JobStats[i].JobName < result.JobStats[j].JobName
+		})
+	}
 	return result
 }
 
-func (c *componentReportGenerator) assessComponentStatus(sampleTotal, sampleSuccess, sampleFlake, baseTotal, baseSuccess, baseFlake int, approvedRegression *regressionallowances.IntentionalRegression, numberOfIgnoredSampleJobRuns int) (apitype.ComponentReportStatus, float64) {
+// jobStatsContribution is how much a job contributes to a regression of the test, the drop in
+// its pass rate weighted by its sample runs. Jobs without base runs are compared against the
+// pass rate of the whole base, baseSuccessRate.
+func jobStatsContribution(jobStats apitype.ComponentReportTestDetailsJobStats, baseSuccessRate float64) float64 {
+	sampleTotal := jobStats.SampleStats.SuccessCount + jobStats.SampleStats.FailureCount + jobStats.SampleStats.FlakeCount
+	if sampleTotal == 0 {
+		return 0
+	}
+	baseRate := baseSuccessRate
+	if jobStats.BaseStats.SuccessCount+jobStats.BaseStats.FailureCount+jobStats.BaseStats.FlakeCount > 0 {
+		baseRate = jobStats.BaseStats.SuccessRate
+	}
+	return (baseRate - jobStats.SampleStats.SuccessRate) * float64(sampleTotal)
+}
+
+// sortJobStatsByContribution orders the jobs by jobStatsContribution, largest first, breaking
+// ties by job name.
+func sortJobStatsByContribution(jobStats []apitype.ComponentReportTestDetailsJobStats, baseSuccessRate float64) {
+	sort.SliceStable(jobStats, func(i, j int) bool {
+		ci := jobStatsContribution(jobStats[i], baseSuccessRate)
+		cj := jobStatsContribution(jobStats[j], baseSuccessRate)
+		if ci != cj {
+			return ci > cj
+		}
+		return jobStats[i].JobName < jobStats[j].JobName
+	})
+}
+
+// sampleSeries buckets the sample job runs over the sample window, or the requested sub-window of
+// it, at the requested granularity, with confidence bands at the requested confidence.
+func (c *componentReportGenerator) sampleSeries(sampleStatus map[string][]apitype.ComponentJobRunTestStatusRow) []apitype.BandedPoint {
+	runs := []apitype.ComponentJobRunTestStatusRow{}
+	for _, rows := range sampleStatus {
+		runs = append(runs, rows...)
+	}
+	start, end := c.SampleRelease.Start, c.SampleRelease.End
+	if !c.SampleSubWindowStart.IsZero() || !c.SampleSubWindowEnd.IsZero() {
+		// the runs outside the sub-window were dropped, so buckets outside it would only be empty
+		start, end = c.sampleSubWindow()
+	}
+	start, end = start.UTC(), end.UTC()
+	z := math.Sqrt2 * math.Erfinv(float64(c.Confidence)/100)
+	return apitype.PassRateSeries(runs, start, end, c.BucketGranularity.BucketWidth(end.Sub(start)), z, false)
+}
+
+func (c *componentReportGenerator) assessComponentStatus(sampleTotal, sampleSuccess, sampleFlake, baseTotal, baseSuccess, baseFlake int, approvedRegression *regressionallowances.IntentionalRegression, numberOfIgnoredSampleJobRuns int) (apitype.ComponentReportStatus, float64, apitype.DecidingFactor) {
+	status, fischerExact, decidingFactor := c.assessPassRateStatus(sampleTotal, sampleSuccess, sampleFlake, baseTotal, baseSuccess, baseFlake, approvedRegression, numberOfIgnoredSampleJobRuns)
+	// flakes count as passes, so a test that turned flaky keeps a stable pass rate
+	if status == apitype.NotSignificant && c.FlagHighFlakeRates {
+		if highFlakeRate, p := c.flakeRateTest(sampleTotal, sampleFlake, baseTotal, baseFlake); highFlakeRate {
+			return apitype.HighFlakeRate, p, apitype.DecidingFactorFlakeRate
+		}
+	}
+	return status, fischerExact, decidingFactor
+}
+
+// flakeRateTest runs the fisher exact test of flakes against all other results, returning
+// whether the sample flake rate is significantly above the basis flake rate, by more than the
+// pity factor, and the p-value.
+func (c *componentReportGenerator) flakeRateTest(sampleTotal, sampleFlake, baseTotal, baseFlake int) (bool, float64) {
+	if sampleTotal == 0 || baseTotal == 0 {
+		return false, 0
+	}
+	sampleFlakeRate := float64(sampleFlake) / float64(sampleTotal)
+	baseFlakeRate := float64(baseFlake) / float64(baseTotal)
+	if sampleFlakeRate-baseFlakeRate <= float64(c.PityFactor)/100 {
+		return false, 0
+	}
+	_, _, r, _ := fischer.FisherExactTest(sampleFlake, sampleTotal-sampleFlake, baseFlake, baseTotal-baseFlake)
+	if c.significanceLevel != nil {
+		return r <= *c.significanceLevel, r
+	}
+	return r < 1-float64(c.Confidence)/100, r
+}
+
+// assessPassRateStatus assesses the pass rate of the sample against the basis, see
+// assessComponentStatus.
+func (c *componentReportGenerator) assessPassRateStatus(sampleTotal, sampleSuccess, sampleFlake, baseTotal, baseSuccess, baseFlake int, approvedRegression *regressionallowances.IntentionalRegression, numberOfIgnoredSampleJobRuns int) (apitype.ComponentReportStatus, float64, apitype.DecidingFactor) {
 	// preserve the initial sampleTotal so we can check
 	// to see if numberOfIgnoredSampleJobRuns impacts the status
 	initialSampleTotal := sampleTotal
-	adjustedSampleTotal := sampleTotal - numberOfIgnoredSampleJobRuns
-	if adjustedSampleTotal < sampleSuccess {
-		log.Errorf("adjustedSampleTotal is too small: sampleTotal=%d, numberOfIgnoredSampleJobRuns=%d, sampleSuccess=%d", sampleTotal, numberOfIgnoredSampleJobRuns, sampleSuccess)
-	} else {
-		sampleTotal = adjustedSampleTotal
-	}
+	sampleTotal = adjustSampleTotal(sampleTotal, sampleSuccess, numberOfIgnoredSampleJobRuns)
 
 	status := apitype.MissingBasis
 	fischerExact := 0.0
+	var decidingFactor apitype.DecidingFactor
+
+	// the pass rate floor applies regardless of the basis, but still requires enough failures to be meaningful
+	if c.ExtremePassRateFloor > 0 && sampleTotal > 0 && (sampleTotal-sampleSuccess-sampleFlake) >= c.MinimumFailure {
+		samplePassPercentage := float64(sampleSuccess+sampleFlake) / float64(sampleTotal)
+		if samplePassPercentage*100 < float64(c.ExtremePassRateFloor) {
+			return apitype.ExtremeRegression, fischerExact, apitype.DecidingFactorPassRateFloor
+		}
+	}
+
 	if baseTotal != 0 {
 		// if the unadjusted sample was 0 then nothing to do
 		if initialSampleTotal == 0 {
@@ -1815,16 +3257,25 @@ func (c *componentReportGenerator) assessComponentStatus(sampleTotal, sampleSucc
 			initialPassPercentage := float64(sampleSuccess+sampleFlake) / float64(initialSampleTotal)
 			effectivePityFactor := c.PityFactor
 
+			// identical base and sample counts can never be significant in either direction,
+			// so there is no need to pay for the significance test
+			if initialSampleTotal == sampleTotal && sampleTotal == baseTotal && sampleSuccess == baseSuccess && sampleFlake == baseFlake {
+				log.Debugf("identical base and sample: base=%d/%d/%d sample=%d/%d/%d", baseTotal, baseSuccess, baseFlake, sampleTotal, sampleSuccess, sampleFlake)
+				return apitype.NotSignificant, fischerExact, apitype.DecidingFactorIdentical
+			}
+
 			wasSignificant := false
+			var wasSignificantFactor apitype.DecidingFactor
 			// only consider wasSignificant if the sampleTotal has been changed and our sample
 			// pass percentage is below the basis
 			if initialSampleTotal > sampleTotal && initialPassPercentage < basisPassPercentage {
 				if basisPassPercentage-initialPassPercentage > float64(effectivePityFactor)/100 {
-					wasSignificant, _ = c.fischerExactTest(initialSampleTotal, sampleSuccess, sampleFlake, baseTotal, baseSuccess, baseFlake)
+					wasSignificant, _, wasSignificantFactor = c.significanceTest(initialSampleTotal, sampleSuccess, sampleFlake, baseTotal, baseSuccess, baseFlake)
 				}
 				// if it was significant without the adjustment use
 				// ExtremeTriagedRegression or SignificantTriagedRegression
 				if wasSignificant {
+					decidingFactor = wasSignificantFactor
 					if (basisPassPercentage - initialPassPercentage) > 0.15 {
 						status = apitype.ExtremeTriagedRegression
 					} else {
@@ -1842,7 +3293,7 @@ func (c *componentReportGenerator) assessComponentStatus(sampleTotal, sampleSucc
 						status = apitype.MissingSample
 					}
 				}
-				return status, fischerExact
+				return status, fischerExact, decidingFactor
 			}
 
 			// if we didn't detect a significant regression prior to adjusting set our default here
@@ -1857,9 +3308,9 @@ func (c *componentReportGenerator) assessComponentStatus(sampleTotal, sampleSucc
 			if c.MinimumFailure != 0 && (sampleTotal-sampleSuccess-sampleFlake) < c.MinimumFailure {
 				// if we were below the threshold with the initialSampleTotal too then return not significant
 				if c.MinimumFailure != 0 && (initialSampleTotal-sampleSuccess-sampleFlake) < c.MinimumFailure {
-					return apitype.NotSignificant, fischerExact
+					return apitype.NotSignificant, fischerExact, apitype.DecidingFactorMinimumFailure
 				}
-				return status, fischerExact
+				return status, fischerExact, decidingFactor
 			}
 
 			// how do approvedRegressions and triagedRegressions interact?  If we triaged a regression we will
@@ -1881,14 +3332,19 @@ func (c *componentReportGenerator) assessComponentStatus(sampleTotal, sampleSucc
 
 			if improved {
 				// flip base and sample when improved
-				significant, fischerExact = c.fischerExactTest(baseTotal, baseSuccess, baseFlake, sampleTotal, sampleSuccess, sampleFlake)
+				significant, fischerExact, decidingFactor = c.significanceTest(baseTotal, baseSuccess, baseFlake, sampleTotal, sampleSuccess, sampleFlake)
 			} else if basisPassPercentage-samplePassPercentage > float64(effectivePityFactor)/100 {
-				significant, fischerExact = c.fischerExactTest(sampleTotal, sampleSuccess, sampleFlake, baseTotal, baseSuccess, baseFlake)
+				significant, fischerExact, decidingFactor = c.significanceTest(sampleTotal, sampleSuccess, sampleFlake, baseTotal, baseSuccess, baseFlake)
+			} else if !wasSignificant {
+				decidingFactor = apitype.DecidingFactorPity
 			}
 			if significant {
 				if improved {
-					// only show improvements if we are not dropping out triaged results
-					if initialSampleTotal == sampleTotal {
+					if (samplePassPercentage-basisPassPercentage)*100 <= c.MinimumImprovementDelta {
+						// too small an improvement to be worth reporting, however significant
+						decidingFactor = apitype.DecidingFactorMinimumImprovement
+					} else if initialSampleTotal == sampleTotal {
+						// only show improvements if we are not dropping out triaged results
 						status = apitype.SignificantImprovement
 					}
 				} else {
@@ -1898,20 +3354,547 @@ func (c *componentReportGenerator) assessComponentStatus(sampleTotal, sampleSucc
 						status = apitype.SignificantRegression
 					}
 				}
+			} else if !improved && !wasSignificant && decidingFactor.IsSignificanceTest() && c.withinWarningMargin(fischerExact) {
+				status = apitype.RegressionWarning
 			}
 		}
 	}
-	return status, fischerExact
+	return status, fischerExact, decidingFactor
+}
+
+// adjustSampleTotal removes the ignored sample job runs, the failures of triaged incidents, from
+// the sample total unless that would leave fewer runs than passed.
+func adjustSampleTotal(sampleTotal, sampleSuccess, numberOfIgnoredSampleJobRuns int) int {
+	adjustedSampleTotal := sampleTotal - numberOfIgnoredSampleJobRuns
+	if adjustedSampleTotal < sampleSuccess {
+		log.Errorf("adjustedSampleTotal is too small: sampleTotal=%d, numberOfIgnoredSampleJobRuns=%d, sampleSuccess=%d", sampleTotal, numberOfIgnoredSampleJobRuns, sampleSuccess)
+		return sampleTotal
+	}
+	return adjustedSampleTotal
+}
+
+// contingencyTable returns the passes and failures assessComponentStatus compares, after
+// removing the ignored sample job runs. Flakes count as passes.
+func contingencyTable(sampleTotal, sampleSuccess, sampleFlake, baseTotal, baseSuccess, baseFlake, numberOfIgnoredSampleJobRuns int) *apitype.ContingencyTable {
+	sampleTotal = adjustSampleTotal(sampleTotal, sampleSuccess, numberOfIgnoredSampleJobRuns)
+	return &apitype.ContingencyTable{
+		SamplePass: sampleSuccess + sampleFlake,
+		SampleFail: sampleTotal - sampleSuccess - sampleFlake,
+		BasePass:   baseSuccess + baseFlake,
+		BaseFail:   baseTotal - baseSuccess - baseFlake,
+	}
+}
+
+// significanceTest runs the fisher exact test, or the chi-squared test when both totals exceed
+// the requested ChiSquaredThreshold, or the bayesian comparison when requested, returning whether
+// the sample differs significantly from the base, the p-value and which test ran.
+func (c *componentReportGenerator) significanceTest(sampleTotal, sampleSuccess, sampleFlake, baseTotal, baseSuccess, baseFlake int) (bool, float64, apitype.DecidingFactor) {
+	if c.Comparison == apitype.ComparisonBayesian {
+		significant, p := c.bayesianTest(sampleTotal, sampleSuccess, sampleFlake, baseTotal, baseSuccess, baseFlake)
+		return significant, p, apitype.DecidingFactorBayesian
+	}
+	if c.ChiSquaredThreshold > 0 && sampleTotal > c.ChiSquaredThreshold && baseTotal > c.ChiSquaredThreshold {
+		significant, p := c.chiSquaredTest(sampleTotal, sampleSuccess, sampleFlake, baseTotal, baseSuccess, baseFlake)
+		return significant, p, apitype.DecidingFactorChiSquared
+	}
+	significant, p := c.fischerExactTest(sampleTotal, sampleSuccess, sampleFlake, baseTotal, baseSuccess, baseFlake)
+	return significant, p, apitype.DecidingFactorFisher
+}
+
+// chiSquaredTest is Pearson's chi-squared test of the 2x2 table of failures and passes, a cheap
+// approximation of the fisher exact test for large totals.
+func (c *componentReportGenerator) chiSquaredTest(sampleTotal, sampleSuccess, sampleFlake, baseTotal, baseSuccess, baseFlake int) (bool, float64) {
+	sampleFailure := float64(sampleTotal - sampleSuccess - sampleFlake)
+	samplePass := float64(sampleSuccess + sampleFlake)
+	baseFailure := float64(baseTotal - baseSuccess - baseFlake)
+	basePass := float64(baseSuccess + baseFlake)
+	n := sampleFailure + samplePass + baseFailure + basePass
+	denominator := (sampleFailure + samplePass) * (baseFailure + basePass) * (sampleFailure + baseFailure) * (samplePass + basePass)
+	r := 1.0
+	if denominator > 0 {
+		// like the fisher exact test this is one sided by default, only more sample failures are significant,
+		// so the statistic is taken as a signed normal score
+		difference := sampleFailure*basePass - samplePass*baseFailure
+		z := math.Sqrt(n/denominator) * difference
+		r = math.Erfc(z/math.Sqrt2) / 2
+		if c.AlternativeHypothesis == apitype.AlternativeTwoSided {
+			r = math.Erfc(math.Abs(z) / math.Sqrt2)
+		}
+	}
+	if c.significanceLevel != nil {
+		return r <= *c.significanceLevel, r
+	}
+	return r < 1-float64(c.Confidence)/100, r
+}
+
+// bayesianTest compares the posterior pass rates of sample and base, see credibleInterval. Its
+// p-value is twice the posterior probability that the sample pass rate is at least the base pass
+// rate, so it is below the significance level exactly when the central credible interval of the
+// difference at the requested confidence lies entirely below zero.
+func (c *componentReportGenerator) bayesianTest(sampleTotal, sampleSuccess, sampleFlake, baseTotal, baseSuccess, baseFlake int) (bool, float64) {
+	mean, sd := passRateDifferencePosterior(sampleTotal, sampleSuccess+sampleFlake, baseTotal, baseSuccess+baseFlake)
+	r := math.Min(1, math.Erfc(-mean/(sd*math.Sqrt2)))
+	if c.significanceLevel != nil {
+		return r <= *c.significanceLevel, r
+	}
+	return r < 1-float64(c.Confidence)/100, r
+}
+
+// credibleInterval returns the central credible interval, at the given confidence, of the sample
+// pass rate minus the base pass rate.
+func credibleInterval(sampleTotal, samplePass, baseTotal, basePass, confidence int) (*float64, *float64) {
+	mean, sd := passRateDifferencePosterior(sampleTotal, samplePass, baseTotal, basePass)
+	z := math.Sqrt2 * math.Erfinv(float64(confidence)/100)
+	lower, upper := mean-z*sd, mean+z*sd
+	return &lower, &upper
+}
+
+// passRateDifferencePosterior returns the mean and standard deviation of the posterior of the
+// sample pass rate minus the base pass rate. Each pass rate has a uniform Beta(1, 1) prior, and
+// the difference of the Beta posteriors is approximated by a normal distribution.
+func passRateDifferencePosterior(sampleTotal, samplePass, baseTotal, basePass int) (float64, float64) {
+	betaMoments := func(total, pass int) (float64, float64) {
+		a, b := float64(pass+1), float64(total-pass+1)
+		return a / (a + b), a * b / ((a + b) * (a + b) * (a + b + 1))
+	}
+	sampleMean, sampleVariance := betaMoments(sampleTotal, samplePass)
+	baseMean, baseVariance := betaMoments(baseTotal, basePass)
+	return sampleMean - baseMean, math.Sqrt(sampleVariance + baseVariance)
 }
 
 func (c *componentReportGenerator) fischerExactTest(sampleTotal, sampleSuccess, sampleFlake, baseTotal, baseSuccess, baseFlake int) (bool, float64) {
-	_, _, r, _ := fischer.FisherExactTest(sampleTotal-sampleSuccess-sampleFlake,
+	_, _, r, twoSided := fischer.FisherExactTest(sampleTotal-sampleSuccess-sampleFlake,
 		sampleSuccess+sampleFlake,
 		baseTotal-baseSuccess-baseFlake,
 		baseSuccess+baseFlake)
+	if c.AlternativeHypothesis == apitype.AlternativeTwoSided {
+		r = twoSided
+	}
+	if c.significanceLevel != nil {
+		return r <= *c.significanceLevel, r
+	}
 	return r < 1-float64(c.Confidence)/100, r
 }
 
+// regressionAgeDays returns the whole days the tracked regression of a test had been open by the
+// end of the sample. It is zero for untracked tests, and for regressions that have closed or
+// opened after the sample, as their opened time says nothing about the regression in the sample.
+func (c *componentReportGenerator) regressionAgeDays(testID apitype.ComponentReportTestIdentification, openRegressions []apitype.TestRegression) int {
+	regression := tracker.FindOpenRegression(c.SampleRelease.Release, apitype.ComponentReportTestSummary{ComponentReportTestIdentification: testID}, openRegressions)
+	if regression == nil || regression.Closed.Valid || regression.Opened.After(c.SampleRelease.End) {
+		return 0
+	}
+	return int(c.SampleRelease.End.Sub(regression.Opened).Hours() / 24)
+}
+
+// withConfidenceFor returns the generator to assess a cell with, a copy at the cell's confidence
+// when one of the ConfidenceOverrides applies, along with that confidence, or zero when none does.
+// Overrides have no effect while a multiple comparison correction sets the significance level.
+func (c *componentReportGenerator) withConfidenceFor(column apitype.ComponentReportColumnIdentification) (*componentReportGenerator, int) {
+	confidence := c.ConfidenceFor(column)
+	if confidence == c.Confidence || c.significanceLevel != nil {
+		return c, 0
+	}
+	overridden := *c
+	overridden.Confidence = confidence
+	return &overridden, confidence
+}
+
+// withMinimumFailureFor returns the generator to assess the tests of a component with, a copy at
+// the component's minimum failures when MinimumFailureByComponent has an entry for it.
+func (c *componentReportGenerator) withMinimumFailureFor(component string) *componentReportGenerator {
+	minimumFailure := c.MinimumFailureFor(component)
+	if minimumFailure == c.MinimumFailure {
+		return c
+	}
+	overridden := *c
+	overridden.MinimumFailure = minimumFailure
+	return &overridden
+}
+
+// assessorFor returns the generator to assess a test with, applying both the confidence override of
+// its column and the minimum failures of its component, along with the overridden confidence if any.
+func (c *componentReportGenerator) assessorFor(testIdentification apitype.ComponentTestIdentification, stats apitype.ComponentTestStatus) (*componentReportGenerator, int) {
+	assessor, overriddenConfidence := c.withConfidenceFor(buildTestID(stats, testIdentification).ComponentReportColumnIdentification)
+	component, _ := c.componentAndCapabilities(testIdentification, stats)
+	return assessor.withMinimumFailureFor(component), overriddenConfidence
+}
+
+// withinWarningMargin returns true when a p-value that missed significance is still within
+// the requested WarningMargin of it.
+func (c *componentReportGenerator) withinWarningMargin(p float64) bool {
+	if c.WarningMargin <= 0 {
+		return false
+	}
+	alpha := 1 - float64(c.Confidence)/100
+	if c.significanceLevel != nil {
+		alpha = *c.significanceLevel
+	}
+	return p < alpha+float64(c.WarningMargin)/100
+}
+
+// correctedSignificanceLevel collects the fisher exact p-values of every test in the report, then
+// returns the significance level after applying the requested multiple comparison correction.
+// Triage and intentional regressions are ignored when collecting, they are not known yet.
+func (c *componentReportGenerator) correctedSignificanceLevel(baseStatus, sampleStatus map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus) float64 {
+	pValues := []float64{}
+	for testIdentification, baseStats := range baseStatus {
+		sampleStats, ok := sampleStatus[testIdentification]
+		if !ok {
+			continue
+		}
+		_, fisherExact, decidingFactor := c.assessComponentStatus(sampleStats.TotalCount, sampleStats.SuccessCount, sampleStats.FlakeCount,
+			baseStats.TotalCount, baseStats.SuccessCount, baseStats.FlakeCount, nil, 0)
+		// only tests that ran a significance test are comparisons to correct for
+		if decidingFactor.IsSignificanceTest() {
+			pValues = append(pValues, fisherExact)
+		}
+	}
+	return correctSignificanceLevel(c.MultipleComparisonCorrection, 1-float64(c.Confidence)/100, pValues)
+}
+
+// correctSignificanceLevel returns the level p-values must be at or below to be significant once
+// corrected for the number of p-values. A negative level means nothing is significant.
+func correctSignificanceLevel(correction apitype.MultipleComparisonCorrection, alpha float64, pValues []float64) float64 {
+	m := len(pValues)
+	if m == 0 {
+		return alpha
+	}
+	switch correction {
+	case apitype.CorrectionBonferroni:
+		return alpha / float64(m)
+	case apitype.CorrectionBenjaminiHochberg:
+		sorted := append([]float64{}, pValues...)
+		sort.Float64s(sorted)
+		// the largest p-value under its rank's threshold, every p-value up to it is significant
+		for k := m; k > 0; k-- {
+			if sorted[k-1] <= float64(k)/float64(m)*alpha {
+				return sorted[k-1]
+			}
+		}
+		return -1
+	}
+	return alpha
+}
+
+// sortColumnsBySeverity orders columns worst status first. Columns with the same status are
+// ordered by their most significant fisher exact result, otherwise they keep their order.
+func sortColumnsBySeverity(columns []apitype.ComponentReportColumn) {
+	significance := func(column apitype.ComponentReportColumn) float64 {
+		lowest := 1.0
+		for _, test := range column.RegressedTests {
+			if test.FisherExact > 0 && test.FisherExact < lowest {
+				lowest = test.FisherExact
+			}
+		}
+		return lowest
+	}
+	sort.SliceStable(columns, func(i, j int) bool {
+		if columns[i].Status != columns[j].Status {
+			return columns[i].Status.WorseThan(columns[j].Status)
+		}
+		return significance(columns[i]) < significance(columns[j])
+	})
+}
+
+// MinimumDetectableEffect returns the smallest drop from the base pass rate that the fisher exact
+// test would find significant at the given confidence with the sample size of sampleStats. The pity
+// factor is not applied. When even a sample that always failed would not be significant, the whole
+// base pass rate is returned, and 1 when there is no basis or sample to compare at all.
+func MinimumDetectableEffect(baseStats, sampleStats apitype.ComponentReportTestDetailsTestStats, confidence int) float64 {
+	basePass := baseStats.SuccessCount + baseStats.FlakeCount
+	baseTotal := basePass + baseStats.FailureCount
+	sampleTotal := sampleStats.SuccessCount + sampleStats.FailureCount + sampleStats.FlakeCount
+	if baseTotal == 0 || sampleTotal == 0 {
+		return 1
+	}
+	basePassRate := float64(basePass) / float64(baseTotal)
+	significant := func(failures int) bool {
+		_, _, r, _ := fischer.FisherExactTest(failures, sampleTotal-failures, baseTotal-basePass, basePass)
+		return r < 1-float64(confidence)/100
+	}
+
+	// fewest sample failures that are a drop at all, then search for the fewest that are significant
+	low := int(math.Floor(float64(sampleTotal)*(1-basePassRate))) + 1
+	if low > sampleTotal || !significant(sampleTotal) {
+		return basePassRate
+	}
+	high := sampleTotal
+	for low < high {
+		mid := (low + high) / 2
+		if significant(mid) {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+	return basePassRate - float64(sampleTotal-high)/float64(sampleTotal)
+}
+
+// twoProportionZTest compares the sample and base pass rates with a pooled two-proportion z-test,
+// returning the z score and its two-sided p-value. Both are nil when the test is undefined, which
+// is the case when either side is empty or everything passed or failed.
+func twoProportionZTest(sampleTotal, samplePass, baseTotal, basePass int) (*float64, *float64) {
+	if sampleTotal == 0 || baseTotal == 0 {
+		return nil, nil
+	}
+	pooled := float64(samplePass+basePass) / float64(sampleTotal+baseTotal)
+	standardError := math.Sqrt(pooled * (1 - pooled) * (1/float64(sampleTotal) + 1/float64(baseTotal)))
+	if standardError == 0 {
+		return nil, nil
+	}
+	z := (float64(samplePass)/float64(sampleTotal) - float64(basePass)/float64(baseTotal)) / standardError
+	p := math.Erfc(math.Abs(z) / math.Sqrt2)
+	return &z, &p
+}
+
+func withoutSuite(testIdentification apitype.ComponentTestIdentification) apitype.ComponentTestIdentification {
+	testIdentification.TestSuite = ""
+	return testIdentification
+}
+
+// suiteMigrations returns the tests that ran in more than one suite, either within the stats of
+// a single query or, when kept apart by suite, across the stats of several.
+func suiteMigrations(statuses ...map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus) map[apitype.ComponentTestIdentification]bool {
+	migrated := map[apitype.ComponentTestIdentification]bool{}
+	suites := map[apitype.ComponentTestIdentification]sets.String{}
+	for _, status := range statuses {
+		for testIdentification, stats := range status {
+			key := withoutSuite(testIdentification)
+			if stats.SuiteCount > 1 {
+				migrated[key] = true
+			}
+			if testIdentification.TestSuite == "" {
+				continue
+			}
+			if _, ok := suites[key]; !ok {
+				suites[key] = sets.NewString()
+			}
+			suites[key].Insert(testIdentification.TestSuite)
+			if suites[key].Len() > 1 {
+				migrated[key] = true
+			}
+		}
+	}
+	return migrated
+}
+
+// jobRunSuiteMigration reports whether the job runs ran the test in more than one suite.
+func jobRunSuiteMigration(statuses ...map[string][]apitype.ComponentJobRunTestStatusRow) bool {
+	suites := sets.NewString()
+	for _, status := range statuses {
+		for _, rows := range status {
+			for _, row := range rows {
+				if row.TestSuite != "" {
+					suites.Insert(row.TestSuite)
+				}
+			}
+		}
+	}
+	return suites.Len() > 1
+}
+
+// testDetailsBasis is the basis of the test details after applying any matching variant override.
+func (c *componentReportGenerator) testDetailsBasis() apitype.ComponentReportRequestReleaseOptions {
+	return c.BaseRelease.ReleaseForVariants(c.Platform, c.Arch, c.Network, c.Upgrade)
+}
+
+// baseSampleGap returns the time between the end of the basis of a cell and the start of the
+// sample when it exceeds MaxBaseSampleGap, zero otherwise.
+func (c *componentReportGenerator) baseSampleGap(column apitype.ComponentReportColumnIdentification) time.Duration {
+	if c.MaxBaseSampleGap <= 0 {
+		return 0
+	}
+	basis := c.BaseRelease.ReleaseForVariants(column.Platform, column.Arch, column.Network, column.Upgrade)
+	if gap := c.SampleRelease.Start.Sub(basis.End); gap > c.MaxBaseSampleGap {
+		return gap
+	}
+	return 0
+}
+
+// maxFlakeToggleExamples limits how many changed tests FlakeToggleImpact returns.
+const maxFlakeToggleExamples = 10
+
+// FlakeToggleImpact assesses each test in the report status twice, once with flakes counted as
+// successes as the report does and once with flakes counted as failures. It returns how many tests
+// change status between the two, and a few of them as examples. Triage and intentional regressions
+// are not considered, so only the effect of the flake policy itself is measured.
+func FlakeToggleImpact(s apitype.ComponentReportTestStatus, opts apitype.ComponentReportRequestAdvancedOptions) (int, []apitype.ComponentTestIdentification) {
+	generator := componentReportGenerator{ComponentReportRequestAdvancedOptions: opts}
+	changed := []apitype.ComponentTestIdentification{}
+	for testID, baseStats := range s.BaseStatus {
+		sampleStats, ok := s.SampleStatus[testID]
+		if !ok {
+			// missing samples are reported the same either way
+			continue
+		}
+		assessor, _ := generator.assessorFor(testID, baseStats)
+		flakesPass, _, _ := assessor.assessComponentStatus(sampleStats.TotalCount, sampleStats.SuccessCount, sampleStats.FlakeCount,
+			baseStats.TotalCount, baseStats.SuccessCount, baseStats.FlakeCount, nil, 0)
+		flakesFail, _, _ := assessor.assessComponentStatus(sampleStats.TotalCount, sampleStats.SuccessCount, 0,
+			baseStats.TotalCount, baseStats.SuccessCount, 0, nil, 0)
+		if flakesPass != flakesFail {
+			changed = append(changed, testID)
+		}
+	}
+
+	sort.Slice(changed, func(i, j int) bool {
+		a, b := changed[i], changed[j]
+		for _, pair := range [][2]string{
+			{a.TestID, b.TestID}, {a.Platform, b.Platform}, {a.Arch, b.Arch},
+			{a.Network, b.Network}, {a.Upgrade, b.Upgrade}, {a.FlatVariants, b.FlatVariants},
+		} {
+			if pair[0] != pair[1] {
+				return pair[0] < pair[1]
+			}
+		}
+		return false
+	})
+	if len(changed) > maxFlakeToggleExamples {
+		return len(changed), changed[:maxFlakeToggleExamples]
+	}
+	return len(changed), changed
+}
+
+// FailingRuns returns the sample job runs in which the test failed in the cell, most recent first.
+// Runs where the test passed or flaked are left out, as flakes count as passes throughout.
+func (c *componentReportGenerator) FailingRuns(ctx context.Context, id apitype.ComponentReportTestIdentification) ([]apitype.ComponentJobRunTestStatusRow, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	generator := *c
+	generator.Component = id.Component
+	generator.Capability = id.Capability
+	generator.TestID = id.TestID
+	generator.Platform = id.Platform
+	generator.Upgrade = id.Upgrade
+	generator.Arch = id.Arch
+	generator.Network = id.Network
+	generator.Variant = id.Variant
+	componentJobRunTestReportStatus, errs := generator.GenerateJobRunTestReportStatus()
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("error querying job run test status from big query: %v", errs)
+	}
+	return generator.failingRuns(componentJobRunTestReportStatus.SampleStatus), nil
+}
+
+func (c *componentReportGenerator) failingRuns(sampleStatus map[string][]apitype.ComponentJobRunTestStatusRow) []apitype.ComponentJobRunTestStatusRow {
+	if c.ExcludeFirstPRRuns {
+		sampleStatus = excludeFirstPullRequestRuns(sampleStatus)
+	}
+	failed := []apitype.ComponentJobRunTestStatusRow{}
+	for _, rows := range sampleStatus {
+		for _, row := range rows {
+			if getFailureCount(row) > 0 {
+				failed = append(failed, row)
+			}
+		}
+	}
+	sort.SliceStable(failed, func(i, j int) bool {
+		if failed[i].StartTime == failed[j].StartTime {
+			return failed[i].ProwJobRunID < failed[j].ProwJobRunID
+		}
+		return jobRunStartedAfter(failed[i].StartTime, failed[j].StartTime)
+	})
+	return failed
+}
+
+// NewImprovementsSince returns the tests of the sample release that were regressed in the
+// snapshot of regressions, but are now assessed as SignificantImprovement or NotSignificant.
+// Tests still within triage are not considered fixed.
+func (c *componentReportGenerator) NewImprovementsSince(snapshot []apitype.TestRegression) ([]apitype.ComponentReportTestSummary, error) {
+	componentReportTestStatus, errs := c.GenerateComponentReportTestStatus()
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("error querying test status from big query: %v", errs)
+	}
+	return c.newImprovementsSince(componentReportTestStatus.BaseStatus, componentReportTestStatus.SampleStatus, snapshot), nil
+}
+
+func (c *componentReportGenerator) newImprovementsSince(baseStatus, sampleStatus map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus,
+	snapshot []apitype.TestRegression) []apitype.ComponentReportTestSummary {
+	improvements := []apitype.ComponentReportTestSummary{}
+	if len(snapshot) == 0 {
+		return improvements
+	}
+	baseStatus, sampleStatus = c.alignVariants(baseStatus, sampleStatus)
+	for testIdentification, baseStats := range baseStatus {
+		sampleStats, ok := sampleStatus[testIdentification]
+		if !ok {
+			continue
+		}
+		testSummary := apitype.ComponentReportTestSummary{
+			ComponentReportTestIdentification: buildTestID(baseStats, testIdentification),
+			SampleSuccessRate:                 getPassRate(sampleStats, c.FlakeHandling),
+			BaseSuccessRate:                   getPassRate(baseStats, c.FlakeHandling),
+		}
+		if tracker.FindOpenRegression(c.SampleRelease.Release, testSummary, snapshot) == nil {
+			continue
+		}
+		approvedRegression := regressionallowances.IntentionalRegressionFor(c.SampleRelease.Release, testSummary.ComponentReportColumnIdentification, testSummary.TestID)
+		resolvedIssueCompensation, _ := c.triagedIncidentsFor(testSummary.ComponentReportTestIdentification)
+		assessor, _ := c.assessorFor(testIdentification, baseStats)
+		testSummary.Status, testSummary.FisherExact, testSummary.DecidingFactor = assessor.assessComponentStatus(sampleStats.TotalCount, sampleStats.SuccessCount, sampleStats.FlakeCount,
+			baseStats.TotalCount, baseStats.SuccessCount, baseStats.FlakeCount, approvedRegression, resolvedIssueCompensation)
+		if testSummary.Status == apitype.SignificantImprovement || testSummary.Status == apitype.NotSignificant {
+			improvements = append(improvements, testSummary)
+		}
+	}
+
+	sort.Slice(improvements, func(i, j int) bool {
+		a, b := improvements[i], improvements[j]
+		for _, pair := range [][2]string{
+			{a.TestID, b.TestID}, {a.Platform, b.Platform}, {a.Arch, b.Arch},
+			{a.Network, b.Network}, {a.Upgrade, b.Upgrade}, {a.Variant, b.Variant},
+		} {
+			if pair[0] != pair[1] {
+				return pair[0] < pair[1]
+			}
+		}
+		return false
+	})
+	return improvements
+}
+
+// testRegressionLister lists every regression recorded for a test, see tracker.RegressionStore.
+type testRegressionLister interface {
+	ListTestRegressions(ctx context.Context, testID string) ([]apitype.TestRegression, error)
+}
+
+// TestRegressionHistory returns every regression recorded for the test with exactly the given variants,
+// across all releases and whether open or closed, oldest first.
+func (c *componentReportGenerator) TestRegressionHistory(ctx context.Context, testID string, variants map[string]string) ([]apitype.TestRegression, error) {
+	lister := c.regressionLister
+	if lister == nil {
+		lister = tracker.NewBigQueryRegressionStore(c.client)
+	}
+	regs, err := lister.ListTestRegressions(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	history := []apitype.TestRegression{}
+	for _, reg := range regs {
+		if reg.TestID == testID && hasExactVariants(reg, variants) {
+			history = append(history, reg)
+		}
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].Opened.Before(history[j].Opened)
+	})
+	return history, nil
+}
+
+func hasExactVariants(reg apitype.TestRegression, variants map[string]string) bool {
+	if len(reg.Variants) != len(variants) {
+		return false
+	}
+	for _, v := range reg.Variants {
+		if value, ok := variants[v.Key]; !ok || value != v.Value {
+			return false
+		}
+	}
+	return true
+}
+
 func (c *componentReportGenerator) getUniqueJUnitColumnValuesLast60Days(field string, nested bool) ([]string, error) {
 	unnest := ""
 	if nested {
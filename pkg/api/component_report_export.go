@@ -0,0 +1,109 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+)
+
+const componentReadinessTestDetailsPath = "/sippy-ng/component_readiness/test_details"
+
+var componentReportStatusNames = map[apitype.ComponentReportStatus]string{
+	apitype.ExtremeRegression:            "Extreme regression",
+	apitype.SignificantRegression:        "Significant regression",
+	apitype.ExtremeTriagedRegression:     "Extreme triaged regression",
+	apitype.SignificantTriagedRegression: "Significant triaged regression",
+	apitype.RegressionWarning:            "Regression warning",
+	apitype.HighFlakeRate:                "High flake rate",
+	apitype.MissingSample:                "Missing sample",
+	apitype.NotSignificant:               "Not significant",
+	apitype.MissingBasis:                 "Missing basis",
+	apitype.MissingBasisAndSample:        "Missing basis and sample",
+	apitype.SignificantImprovement:       "Significant improvement",
+}
+
+// RegressionsToMarkdown renders the regressed tests as a Markdown table, suitable for
+// pasting into the description of an issue. The links to the test details are absolute,
+// rooted at the sippy instance at baseURL, and compare the given releases, which should be
+// those of the report the regressions came from.
+func RegressionsToMarkdown(baseURL string, summaries []apitype.ComponentReportTestSummary, baseRelease, sampleRelease apitype.ComponentReportRequestReleaseOptions) string {
+	sb := strings.Builder{}
+	sb.WriteString("| Test | Variants | Status | Pass Rate Delta | Link |\n")
+	sb.WriteString("| --- | --- | --- | --- | --- |\n")
+	for _, summary := range summaries {
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s | [details](%s) |\n",
+			escapeMarkdownTableCell(summary.TestName),
+			escapeMarkdownTableCell(columnVariantsString(summary.ComponentReportColumnIdentification)),
+			componentReportStatusName(summary.Status),
+			passRateDeltaString(summary),
+			testDetailsLink(baseURL, summary.ComponentReportTestIdentification, baseRelease, sampleRelease))
+	}
+	return sb.String()
+}
+
+func componentReportStatusName(status apitype.ComponentReportStatus) string {
+	if name, ok := componentReportStatusNames[status]; ok {
+		return name
+	}
+	return fmt.Sprintf("Unknown (%d)", status)
+}
+
+func passRateDeltaString(summary apitype.ComponentReportTestSummary) string {
+	// there is nothing to compare against when either side is missing
+	if summary.Status == apitype.MissingBasis || summary.Status == apitype.MissingSample || summary.Status == apitype.MissingBasisAndSample {
+		return "n/a"
+	}
+	return fmt.Sprintf("%+.2f%%", (summary.SampleSuccessRate-summary.BaseSuccessRate)*100)
+}
+
+// columnVariantsString joins the non-empty variants of a column for display.
+func columnVariantsString(column apitype.ComponentReportColumnIdentification) string {
+	variants := []string{}
+	for _, v := range []string{column.Platform, column.Arch, column.Network, column.Upgrade, column.Variant, column.Suite} {
+		if v != "" {
+			variants = append(variants, v)
+		}
+	}
+	return strings.Join(variants, " ")
+}
+
+func escapeMarkdownTableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+// testDetailsLink builds a link to the test details page of the sippy instance at baseURL for
+// the given test, using the same query parameters understood by the component readiness API.
+func testDetailsLink(baseURL string, testID apitype.ComponentReportTestIdentification, baseRelease, sampleRelease apitype.ComponentReportRequestReleaseOptions) string {
+	params := url.Values{}
+	params.Set("baseRelease", baseRelease.Release)
+	if baseRelease.RollingBaseline > 0 {
+		params.Set("baseRollingWindow", baseRelease.RollingBaseline.String())
+	} else {
+		params.Set("baseStartTime", formatReleaseTime(baseRelease.Start))
+		params.Set("baseEndTime", formatReleaseTime(baseRelease.End))
+	}
+	if baseRelease.MaxRunAge > 0 {
+		params.Set("baseMaxRunAge", baseRelease.MaxRunAge.String())
+	}
+	params.Set("sampleRelease", sampleRelease.Release)
+	params.Set("sampleStartTime", formatReleaseTime(sampleRelease.Start))
+	params.Set("sampleEndTime", formatReleaseTime(sampleRelease.End))
+	params.Set("component", testID.Component)
+	params.Set("capability", testID.Capability)
+	params.Set("testId", testID.TestID)
+	params.Set("platform", testID.Platform)
+	params.Set("arch", testID.Arch)
+	params.Set("network", testID.Network)
+	params.Set("upgrade", testID.Upgrade)
+	params.Set("variant", testID.Variant)
+	return strings.TrimSuffix(baseURL, "/") + componentReadinessTestDetailsPath + "?" + params.Encode()
+}
+
+// formatReleaseTime formats the start or end of a release window the way the API parses it.
+func formatReleaseTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
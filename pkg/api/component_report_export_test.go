@@ -0,0 +1,118 @@
+package api
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+)
+
+func TestRegressionsToMarkdown(t *testing.T) {
+	summaries := []apitype.ComponentReportTestSummary{
+		{
+			ComponentReportTestIdentification: apitype.ComponentReportTestIdentification{
+				ComponentReportRowIdentification: apitype.ComponentReportRowIdentification{
+					Component:  "component 1",
+					Capability: "cap1",
+					TestName:   "test | with pipe",
+					TestID:     "1",
+				},
+				ComponentReportColumnIdentification: apitype.ComponentReportColumnIdentification{
+					Platform: "aws",
+					Arch:     "amd64",
+					Network:  "ovn",
+					Upgrade:  "upgrade-micro",
+					Variant:  "standard",
+				},
+			},
+			Status:            apitype.ExtremeRegression,
+			SampleSuccessRate: 0.5,
+			BaseSuccessRate:   0.9,
+		},
+		{
+			ComponentReportTestIdentification: apitype.ComponentReportTestIdentification{
+				ComponentReportRowIdentification: apitype.ComponentReportRowIdentification{
+					Component: "component 2",
+					TestName:  "test 2",
+					TestID:    "2",
+				},
+				ComponentReportColumnIdentification: apitype.ComponentReportColumnIdentification{
+					Platform: "gcp",
+					Network:  "sdn",
+				},
+			},
+			Status:            apitype.SignificantRegression,
+			SampleSuccessRate: 0.85,
+			BaseSuccessRate:   0.95,
+		},
+	}
+
+	baseRelease := apitype.ComponentReportRequestReleaseOptions{
+		Release: "4.15",
+		Start:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:     time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+	sampleRelease := apitype.ComponentReportRequestReleaseOptions{
+		Release: "4.16",
+		Start:   time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		End:     time.Date(2024, 5, 8, 12, 30, 0, 0, time.UTC),
+	}
+	lines := strings.Split(strings.TrimSuffix(RegressionsToMarkdown("https://sippy.example.com/", summaries, baseRelease, sampleRelease), "\n"), "\n")
+	assert.Equal(t, 4, len(lines), "expected a header, a separator and one line per regression")
+	assert.Equal(t, "| Test | Variants | Status | Pass Rate Delta | Link |", lines[0])
+	assert.Equal(t, "| --- | --- | --- | --- | --- |", lines[1])
+	assert.True(t, strings.HasPrefix(lines[2], `| test \| with pipe | aws amd64 ovn upgrade-micro standard | Extreme regression | -40.00% | [details](`), "unexpected row %s", lines[2])
+	assert.Contains(t, lines[2], "testId=1")
+	assert.True(t, strings.HasPrefix(lines[3], "| test 2 | gcp sdn | Significant regression | -10.00% | [details](https://sippy.example.com/sippy-ng/component_readiness/test_details?"), "unexpected row %s", lines[3])
+	link := lines[3][strings.Index(lines[3], "](")+2 : len(lines[3])-len(") |")]
+	parsed, err := url.Parse(link)
+	assert.NoError(t, err)
+	assert.Equal(t, "https", parsed.Scheme)
+	assert.Equal(t, "sippy.example.com", parsed.Host)
+	assert.Equal(t, "/sippy-ng/component_readiness/test_details", parsed.Path, "the base URL should be joined without a double slash")
+	assert.Equal(t, url.Values{
+		"baseRelease":     {"4.15"},
+		"baseStartTime":   {"2024-01-01T00:00:00Z"},
+		"baseEndTime":     {"2024-02-01T00:00:00Z"},
+		"sampleRelease":   {"4.16"},
+		"sampleStartTime": {"2024-05-01T00:00:00Z"},
+		"sampleEndTime":   {"2024-05-08T12:30:00Z"},
+		"component":       {"component 2"},
+		"capability":      {""},
+		"testId":          {"2"},
+		"platform":        {"gcp"},
+		"arch":            {""},
+		"network":         {"sdn"},
+		"upgrade":         {""},
+		"variant":         {""},
+	}, parsed.Query())
+	for _, line := range lines {
+		// escaped pipes must not introduce extra columns
+		assert.Equal(t, 6, strings.Count(strings.ReplaceAll(line, `\|`, ""), "|"), "unexpected column count in %s", line)
+	}
+}
+
+func TestTestDetailsLinkRollingBaseline(t *testing.T) {
+	baseRelease := apitype.ComponentReportRequestReleaseOptions{
+		Release:         "4.16",
+		RollingBaseline: 14 * 24 * time.Hour,
+		MaxRunAge:       72 * time.Hour,
+	}
+	sampleRelease := apitype.ComponentReportRequestReleaseOptions{
+		Release: "4.16",
+		Start:   time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		End:     time.Date(2024, 5, 8, 0, 0, 0, 0, time.UTC),
+	}
+	parsed, err := url.Parse(testDetailsLink("https://sippy.example.com", apitype.ComponentReportTestIdentification{}, baseRelease, sampleRelease))
+	assert.NoError(t, err)
+	query := parsed.Query()
+	assert.Equal(t, "336h0m0s", query.Get("baseRollingWindow"))
+	assert.Equal(t, "72h0m0s", query.Get("baseMaxRunAge"))
+	assert.False(t, query.Has("baseStartTime"), "the rolling window replaces the base start time")
+	assert.False(t, query.Has("baseEndTime"), "the rolling window replaces the base end time")
+	assert.Equal(t, "2024-05-01T00:00:00Z", query.Get("sampleStartTime"))
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"sort"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// OpenBugsForTests returns the open bugs linked to each of the given tests, keyed by test name,
+// such as query.LoadOpenBugsForTests.
+type OpenBugsForTests func(testNames []string) (map[string][]models.Bug, error)
+
+// AnnotateKnownIssues attaches the open bugs linked to each regressed and triaged test of the
+// report as its KnownIssues, so known issues show in the report without a separate triage step.
+// The bugs of all tests are looked up with a single call to openBugsForTests.
+func AnnotateKnownIssues(report *apitype.ComponentReport, openBugsForTests OpenBugsForTests) error {
+	testNames := map[string]bool{}
+	forEachRegressedTest(report, func(summary *apitype.ComponentReportTestSummary) {
+		testNames[summary.TestName] = true
+	})
+	if len(testNames) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(testNames))
+	for name := range testNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bugs, err := openBugsForTests(names)
+	if err != nil {
+		return err
+	}
+	forEachRegressedTest(report, func(summary *apitype.ComponentReportTestSummary) {
+		summary.KnownIssues = nil
+		for _, bug := range bugs[summary.TestName] {
+			summary.KnownIssues = append(summary.KnownIssues, apitype.KnownIssue{
+				Key:     bug.Key,
+				URL:     bug.URL,
+				Summary: bug.Summary,
+			})
+		}
+	})
+	return nil
+}
+
+// forEachRegressedTest calls f with each regressed and triaged test of the report, in place.
+func forEachRegressedTest(report *apitype.ComponentReport, f func(summary *apitype.ComponentReportTestSummary)) {
+	for i := range report.Rows {
+		for j := range report.Rows[i].Columns {
+			column := &report.Rows[i].Columns[j]
+			for k := range column.RegressedTests {
+				f(&column.RegressedTests[k])
+			}
+			for k := range column.TriagedIncidents {
+				f(&column.TriagedIncidents[k].ComponentReportTestSummary)
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestAnnotateKnownIssues(t *testing.T) {
+	summary := func(testName string) apitype.ComponentReportTestSummary {
+		return apitype.ComponentReportTestSummary{
+			ComponentReportTestIdentification: apitype.ComponentReportTestIdentification{
+				ComponentReportRowIdentification: apitype.ComponentReportRowIdentification{TestName: testName},
+			},
+			Status: apitype.SignificantRegression,
+		}
+	}
+	report := func() apitype.ComponentReport {
+		return apitype.ComponentReport{
+			Rows: []apitype.ComponentReportRow{
+				{
+					Columns: []apitype.ComponentReportColumn{
+						{RegressedTests: []apitype.ComponentReportTestSummary{summary("test 1"), summary("test 2")}},
+						{Status: apitype.NotSignificant},
+					},
+				},
+				{
+					Columns: []apitype.ComponentReportColumn{
+						{
+							RegressedTests: []apitype.ComponentReportTestSummary{summary("test 1")},
+							TriagedIncidents: []apitype.ComponentReportTriageIncidentSummary{
+								{ComponentReportTestSummary: summary("test 3")},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	bug := models.Bug{Key: "OCPBUGS-1", URL: "https://issues.redhat.com/browse/OCPBUGS-1", Summary: "test 1 fails"}
+
+	t.Run("bugs of all tests are looked up at once", func(t *testing.T) {
+		calls := 0
+		annotated := report()
+		err := AnnotateKnownIssues(&annotated, func(testNames []string) (map[string][]models.Bug, error) {
+			calls++
+			assert.Equal(t, []string{"test 1", "test 2", "test 3"}, testNames)
+			return map[string][]models.Bug{"test 1": {bug}, "test 3": {bug}}, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, calls)
+
+		knownIssue := apitype.KnownIssue{Key: bug.Key, URL: bug.URL, Summary: bug.Summary}
+		assert.Equal(t, []apitype.KnownIssue{knownIssue}, annotated.Rows[0].Columns[0].RegressedTests[0].KnownIssues)
+		assert.Empty(t, annotated.Rows[0].Columns[0].RegressedTests[1].KnownIssues)
+		assert.Equal(t, []apitype.KnownIssue{knownIssue}, annotated.Rows[1].Columns[0].RegressedTests[0].KnownIssues)
+		assert.Equal(t, []apitype.KnownIssue{knownIssue}, annotated.Rows[1].Columns[0].TriagedIncidents[0].KnownIssues)
+	})
+
+	t.Run("reports without regressions are not looked up", func(t *testing.T) {
+		empty := apitype.ComponentReport{Rows: []apitype.ComponentReportRow{{Columns: []apitype.ComponentReportColumn{{Status: apitype.NotSignificant}}}}}
+		err := AnnotateKnownIssues(&empty, func(testNames []string) (map[string][]models.Bug, error) {
+			t.Fatal("unexpected bug lookup")
+			return nil, nil
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("lookup errors are returned", func(t *testing.T) {
+		annotated := report()
+		err := AnnotateKnownIssues(&annotated, func(testNames []string) (map[string][]models.Bug, error) {
+			return nil, fmt.Errorf("database unavailable")
+		})
+		assert.Error(t, err)
+		assert.Empty(t, annotated.Rows[0].Columns[0].RegressedTests[0].KnownIssues)
+	})
+}
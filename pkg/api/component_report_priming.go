@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/apis/cache"
+	bqcachedclient "github.com/openshift/sippy/pkg/bigquery"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+// primingState is persisted in the cache after each page so an interrupted prime can resume.
+// It is reset once a prime completes, so the next one regenerates every page.
+type primingState struct {
+	CompletedPages []string `json:"completed_pages"`
+}
+
+// cachePrimer generates a list of pages in order, recording its progress in the cache.
+type cachePrimer struct {
+	cache     cache.Cache
+	stateKey  string
+	pages     []string
+	primePage func(page string) []error
+	// forceRefresh starts over rather than resuming, as the completed pages are being refreshed
+	forceRefresh bool
+}
+
+// PrimeComponentReportCache generates the top level component report and each of its component
+// pages so they are cached before anyone asks for them. Progress is persisted in the cache, so a
+// prime that was interrupted resumes with the pages it had not completed. Cancelling ctx stops
+// the prime cleanly between pages. With ForceRefresh every page is regenerated.
+func PrimeComponentReportCache(ctx context.Context, client *bqcachedclient.Client, prowURL, gcsBucket string,
+	baseRelease, sampleRelease apitype.ComponentReportRequestReleaseOptions,
+	variantOption apitype.ComponentReportRequestVariantOptions,
+	excludeOption apitype.ComponentReportRequestExcludeOptions,
+	advancedOption apitype.ComponentReportRequestAdvancedOptions,
+	cacheOption cache.RequestOptions,
+) error {
+	if client == nil || client.Cache == nil {
+		return fmt.Errorf("priming requires a cache")
+	}
+	generatePage := func(testIDOption apitype.ComponentReportRequestTestIdentificationOptions) (apitype.ComponentReport, []error) {
+		return GetComponentReportFromBigQuery(client, prowURL, gcsBucket, baseRelease, sampleRelease, testIDOption,
+			variantOption, excludeOption, advancedOption, cacheOption)
+	}
+
+	// the top page is needed to know the component pages, it is cheap to regenerate when resuming
+	report, errs := generatePage(apitype.ComponentReportRequestTestIdentificationOptions{})
+	if len(errs) > 0 {
+		return errors.Errorf("error priming top level component report: %v", errs)
+	}
+	pages := []string{}
+	for _, row := range report.Rows {
+		pages = append(pages, row.Component)
+	}
+
+	generator := componentReportGenerator{
+		client:                                client,
+		cacheOption:                           cacheOption,
+		BaseRelease:                           baseRelease,
+		SampleRelease:                         sampleRelease,
+		ComponentReportRequestVariantOptions:  variantOption,
+		ComponentReportRequestExcludeOptions:  excludeOption,
+		ComponentReportRequestAdvancedOptions: advancedOption,
+	}
+	stateCacheData := generator.GetComponentReportCacheKey("ComponentReportPrimingState~")
+	stateKey, err := stateCacheData.GetCacheKey()
+	if err != nil {
+		return err
+	}
+
+	primer := cachePrimer{
+		cache:    client.Cache,
+		stateKey: string(stateKey),
+		pages:    pages,
+		primePage: func(component string) []error {
+			_, errs := generatePage(apitype.ComponentReportRequestTestIdentificationOptions{Component: component})
+			return errs
+		},
+		forceRefresh: cacheOption.ForceRefresh,
+	}
+	return primer.prime(ctx)
+}
+
+func (p *cachePrimer) prime(ctx context.Context) error {
+	state := primingState{}
+	if !p.forceRefresh {
+		state = p.loadState()
+	}
+	completed := sets.NewString(state.CompletedPages...)
+	for _, page := range p.pages {
+		if completed.Has(page) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			log.Infof("cache priming canceled with %d of %d pages completed", completed.Len(), len(p.pages))
+			return err
+		}
+		if errs := p.primePage(page); len(errs) > 0 {
+			return errors.Errorf("error priming page %q: %v", page, errs)
+		}
+		completed.Insert(page)
+		state.CompletedPages = append(state.CompletedPages, page)
+		if err := p.saveState(state); err != nil {
+			// priming can continue, it just won't be able to resume from here
+			log.WithError(err).Warningf("couldn't persist cache priming state")
+		}
+	}
+	if err := p.saveState(primingState{}); err != nil {
+		log.WithError(err).Warningf("couldn't reset cache priming state")
+	}
+	return nil
+}
+
+func (p *cachePrimer) loadState() primingState {
+	state := primingState{}
+	data, err := p.cache.Get(p.stateKey)
+	if err != nil {
+		return state
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		log.WithError(err).Warningf("ignoring invalid cache priming state")
+		return primingState{}
+	}
+	return state
+}
+
+func (p *cachePrimer) saveState(state primingState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return p.cache.Set(p.stateKey, data, defaultCacheDuration)
+}
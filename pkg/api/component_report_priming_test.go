@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachePrimerCancelAndResume(t *testing.T) {
+	c := &fakeCache{items: map[string][]byte{}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	primed := []string{}
+	primer := cachePrimer{
+		cache:    c,
+		stateKey: "ComponentReportPrimingState~test",
+		pages:    []string{"component 1", "component 2", "component 3", "component 4"},
+		primePage: func(page string) []error {
+			primed = append(primed, page)
+			// simulate a shutdown arriving while the second page is generated
+			if page == "component 2" {
+				cancel()
+			}
+			return nil
+		},
+	}
+
+	err := primer.prime(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []string{"component 1", "component 2"}, primed, "priming should stop between pages once canceled")
+	assert.Equal(t, primingState{CompletedPages: []string{"component 1", "component 2"}}, primer.loadState())
+
+	primed = []string{}
+	assert.NoError(t, primer.prime(context.Background()))
+	assert.Equal(t, []string{"component 3", "component 4"}, primed, "resumed priming should skip completed pages")
+	assert.Equal(t, primingState{}, primer.loadState(), "a completed prime should reset its progress")
+
+	primed = []string{}
+	assert.NoError(t, primer.prime(context.Background()))
+	assert.Equal(t, primer.pages, primed, "the next prime should regenerate every page")
+}
+
+func TestCachePrimerForceRefresh(t *testing.T) {
+	c := &fakeCache{items: map[string][]byte{}}
+	primed := []string{}
+	primer := cachePrimer{
+		cache:    c,
+		stateKey: "ComponentReportPrimingState~test",
+		pages:    []string{"component 1", "component 2"},
+		primePage: func(page string) []error {
+			primed = append(primed, page)
+			return nil
+		},
+	}
+	assert.NoError(t, primer.saveState(primingState{CompletedPages: []string{"component 1"}}))
+
+	primer.forceRefresh = true
+	assert.NoError(t, primer.prime(context.Background()))
+	assert.Equal(t, primer.pages, primed, "a forced refresh should not skip pages primed before")
+}
+
+func TestCachePrimerPageError(t *testing.T) {
+	c := &fakeCache{items: map[string][]byte{}}
+	failing := true
+	primed := []string{}
+	primer := cachePrimer{
+		cache:    c,
+		stateKey: "ComponentReportPrimingState~test",
+		pages:    []string{"component 1", "component 2", "component 3"},
+		primePage: func(page string) []error {
+			if page == "component 2" && failing {
+				return []error{errors.New("bigquery unavailable")}
+			}
+			primed = append(primed, page)
+			return nil
+		},
+	}
+
+	assert.Error(t, primer.prime(context.Background()))
+	assert.Equal(t, []string{"component 1"}, primed)
+
+	// the failed page is retried when resuming
+	failing = false
+	assert.NoError(t, primer.prime(context.Background()))
+	assert.Equal(t, []string{"component 1", "component 2", "component 3"}, primed)
+}
+
+func TestCachePrimerInvalidState(t *testing.T) {
+	c := &fakeCache{items: map[string][]byte{"ComponentReportPrimingState~test": []byte("not json")}}
+	primed := []string{}
+	primer := cachePrimer{
+		cache:    c,
+		stateKey: "ComponentReportPrimingState~test",
+		pages:    []string{"component 1"},
+		primePage: func(page string) []error {
+			primed = append(primed, page)
+			return nil
+		},
+	}
+
+	assert.NoError(t, primer.prime(context.Background()))
+	assert.Equal(t, []string{"component 1"}, primed, "invalid state should restart priming from scratch")
+}
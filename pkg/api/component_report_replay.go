@@ -0,0 +1,148 @@
+package api
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	bqcachedclient "github.com/openshift/sippy/pkg/bigquery"
+	"github.com/openshift/sippy/pkg/componentreadiness/tracker"
+)
+
+// ComponentReportRecording holds every input that went into generating a component report,
+// so that a report seen in production can be regenerated offline with ReplayReport.
+type ComponentReportRecording struct {
+	BaseRelease               apitype.ComponentReportRequestReleaseOptions
+	SampleRelease             apitype.ComponentReportRequestReleaseOptions
+	TestIdentificationOptions apitype.ComponentReportRequestTestIdentificationOptions
+	VariantOptions            apitype.ComponentReportRequestVariantOptions
+	ExcludeOptions            apitype.ComponentReportRequestExcludeOptions
+	AdvancedOptions           apitype.ComponentReportRequestAdvancedOptions
+
+	BaseStatus       map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus
+	SampleStatus     map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus
+	OpenRegressions  []apitype.TestRegression
+	TriagedIncidents []RecordedTriagedIncidents
+	GeneratedAt      *time.Time
+}
+
+// RecordedTriagedIncidents are the triaged incidents looked up for a single test while generating
+// the recorded report.
+type RecordedTriagedIncidents struct {
+	TestID           apitype.ComponentReportTestIdentification
+	ImpactedJobRuns  int
+	TriagedIncidents []apitype.TriagedIncident
+}
+
+// RecordComponentReport generates a component report bypassing the cache, and writes the inputs
+// it was generated from to file.
+func RecordComponentReport(client *bqcachedclient.Client, prowURL, gcsBucket string,
+	baseRelease, sampleRelease apitype.ComponentReportRequestReleaseOptions,
+	testIDOption apitype.ComponentReportRequestTestIdentificationOptions,
+	variantOption apitype.ComponentReportRequestVariantOptions,
+	excludeOption apitype.ComponentReportRequestExcludeOptions,
+	advancedOption apitype.ComponentReportRequestAdvancedOptions,
+	file string,
+) (apitype.ComponentReport, []error) {
+	generator := componentReportGenerator{
+		client:        client,
+		prowURL:       prowURL,
+		gcsBucket:     gcsBucket,
+		BaseRelease:   baseRelease,
+		SampleRelease: sampleRelease,
+		ComponentReportRequestTestIdentificationOptions: testIDOption,
+		ComponentReportRequestVariantOptions:            variantOption,
+		ComponentReportRequestExcludeOptions:            excludeOption,
+		ComponentReportRequestAdvancedOptions:           advancedOption,
+	}
+
+	componentReportTestStatus, errs := generator.GenerateComponentReportTestStatus()
+	if len(errs) > 0 {
+		return apitype.ComponentReport{}, errs
+	}
+	bqs := tracker.NewBigQueryRegressionStore(client)
+	openRegressions, err := bqs.ListCurrentRegressions(sampleRelease.Release)
+	if err != nil {
+		return apitype.ComponentReport{}, []error{err}
+	}
+
+	report, recording := generator.generateRecordedReport(componentReportTestStatus, openRegressions)
+	if err := writeComponentReportRecording(recording, file); err != nil {
+		return apitype.ComponentReport{}, []error{err}
+	}
+	return report, nil
+}
+
+// ReplayReport regenerates a component report from the inputs recorded by RecordComponentReport.
+func ReplayReport(file string) (apitype.ComponentReport, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return apitype.ComponentReport{}, errors.WithMessagef(err, "failed to read recording %s", file)
+	}
+	recording := ComponentReportRecording{}
+	if err := json.Unmarshal(data, &recording); err != nil {
+		return apitype.ComponentReport{}, errors.WithMessagef(err, "failed to parse recording %s", file)
+	}
+
+	generator := componentReportGenerator{
+		BaseRelease:   recording.BaseRelease,
+		SampleRelease: recording.SampleRelease,
+		ComponentReportRequestTestIdentificationOptions: recording.TestIdentificationOptions,
+		ComponentReportRequestVariantOptions:            recording.VariantOptions,
+		ComponentReportRequestExcludeOptions:            recording.ExcludeOptions,
+		ComponentReportRequestAdvancedOptions:           recording.AdvancedOptions,
+		replayedTriagedIncidents:                        map[apitype.ComponentReportTestIdentification]RecordedTriagedIncidents{},
+	}
+	for _, recorded := range recording.TriagedIncidents {
+		generator.replayedTriagedIncidents[recorded.TestID] = recorded
+	}
+
+	sampleStatus := recording.SampleStatus
+	if sampleStatus == nil {
+		sampleStatus = map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{}
+	}
+	report := generator.generateComponentTestReport(recording.BaseStatus, sampleStatus, recording.OpenRegressions)
+	report.GeneratedAt = recording.GeneratedAt
+	return report, nil
+}
+
+// generateRecordedReport generates the report while capturing its inputs.
+func (c *componentReportGenerator) generateRecordedReport(componentReportTestStatus apitype.ComponentReportTestStatus,
+	openRegressions []apitype.TestRegression) (apitype.ComponentReport, ComponentReportRecording) {
+	recording := ComponentReportRecording{
+		BaseRelease:               c.BaseRelease,
+		SampleRelease:             c.SampleRelease,
+		TestIdentificationOptions: c.ComponentReportRequestTestIdentificationOptions,
+		VariantOptions:            c.ComponentReportRequestVariantOptions,
+		ExcludeOptions:            c.ComponentReportRequestExcludeOptions,
+		AdvancedOptions:           c.ComponentReportRequestAdvancedOptions,
+		BaseStatus:                componentReportTestStatus.BaseStatus,
+		SampleStatus:              map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{},
+		OpenRegressions:           openRegressions,
+		GeneratedAt:               componentReportTestStatus.GeneratedAt,
+	}
+	// report generation consumes the sample status, so keep a copy of it
+	for testID, status := range componentReportTestStatus.SampleStatus {
+		recording.SampleStatus[testID] = status
+	}
+
+	c.recordedTriagedIncidents = &recording.TriagedIncidents
+	defer func() { c.recordedTriagedIncidents = nil }()
+	report := c.generateComponentTestReport(componentReportTestStatus.BaseStatus, componentReportTestStatus.SampleStatus, openRegressions)
+	report.GeneratedAt = componentReportTestStatus.GeneratedAt
+	return report, recording
+}
+
+func writeComponentReportRecording(recording ComponentReportRecording, file string) error {
+	data, err := json.Marshal(recording)
+	if err != nil {
+		return errors.WithMessage(err, "failed to marshal recording")
+	}
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		return errors.WithMessagef(err, "failed to write recording %s", file)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+)
+
+func TestRecordAndReplayReport(t *testing.T) {
+	regressedTest := apitype.ComponentTestIdentification{
+		TestID:       "1",
+		Platform:     "aws",
+		Arch:         "amd64",
+		Network:      "ovn",
+		Upgrade:      "upgrade-micro",
+		FlatVariants: "standard",
+	}
+	passingTest := regressedTest
+	passingTest.TestID = "2"
+	missingBasisTest := regressedTest
+	missingBasisTest.TestID = "3"
+	generatedAt := time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC)
+	testStatus := apitype.ComponentReportTestStatus{
+		BaseStatus: map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+			regressedTest: {TestName: "test 1", Variants: []string{"standard"}, TotalCount: 1000, SuccessCount: 900, FlakeCount: 10},
+			passingTest:   {TestName: "test 2", Variants: []string{"standard"}, TotalCount: 1000, SuccessCount: 900, FlakeCount: 10},
+		},
+		SampleStatus: map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+			regressedTest:    {TestName: "test 1", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 85},
+			passingTest:      {TestName: "test 2", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 90, FlakeCount: 1},
+			missingBasisTest: {TestName: "test 3", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 90},
+		},
+		GeneratedAt: &generatedAt,
+	}
+	openRegressions := []apitype.TestRegression{
+		{
+			Release:  "4.15",
+			TestID:   "1",
+			TestName: "test 1",
+			Opened:   generatedAt.Add(-24 * time.Hour),
+			Variants: []apitype.ComponentReportVariant{
+				{Key: "Network", Value: "ovn"},
+				{Key: "Upgrade", Value: "upgrade-micro"},
+				{Key: "Architecture", Value: "amd64"},
+				{Key: "Platform", Value: "aws"},
+				{Key: "FlatVariants", Value: "standard"},
+			},
+		},
+	}
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+
+	generator := defaultComponentReportGenerator
+	report, recording := generator.generateRecordedReport(testStatus, openRegressions)
+	assert.Equal(t, 3, len(recording.SampleStatus), "the recording must keep the sample status consumed by generation")
+
+	file := filepath.Join(t.TempDir(), "recording.json")
+	assert.NoError(t, writeComponentReportRecording(recording, file))
+
+	replayed, err := ReplayReport(file)
+	assert.NoError(t, err)
+	assert.Equal(t, report, replayed)
+
+	// triage recorded for a test is applied when replaying
+	recording.TriagedIncidents = []RecordedTriagedIncidents{
+		{
+			TestID:          report.Rows[0].Columns[0].RegressedTests[0].ComponentReportTestIdentification,
+			ImpactedJobRuns: 15,
+		},
+	}
+	assert.NoError(t, writeComponentReportRecording(recording, file))
+	replayed, err = ReplayReport(file)
+	assert.NoError(t, err)
+	for _, row := range replayed.Rows {
+		if row.Component == "component 1" {
+			assert.Equal(t, apitype.SignificantTriagedRegression, row.Columns[0].Status)
+		}
+	}
+	assert.Equal(t, "component 1", report.Rows[0].Component)
+	assert.Equal(t, apitype.SignificantRegression, report.Rows[0].Columns[0].Status)
+
+	_, err = ReplayReport(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+
+	assert.NoError(t, os.WriteFile(file, []byte("not json"), 0o600))
+	_, err = ReplayReport(file)
+	assert.Error(t, err)
+}
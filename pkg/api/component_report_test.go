@@ -2,11 +2,20 @@
 package api
 
 import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/civil"
+	fischer "github.com/glycerine/golang-fisher-exact"
 	"github.com/stretchr/testify/assert"
 
 	apitype "github.com/openshift/sippy/pkg/apis/api"
+	bqcachedclient "github.com/openshift/sippy/pkg/bigquery"
 )
 
 func fakeComponentAndCapabilityGetter(test apitype.ComponentTestIdentification, stats apitype.ComponentTestStatus) (string, []string) {
@@ -27,6 +36,10 @@ func fakeComponentAndCapabilityGetter(test apitype.ComponentTestIdentification,
 			component:    "component 1",
 			capabilities: []string{"cap1"},
 		},
+		"test 4": {
+			component:    "component 1",
+			capabilities: []string{"cap2"},
+		},
 	}
 	if comCap, ok := known[name]; ok {
 		return comCap.component, comCap.capabilities
@@ -371,7 +384,11 @@ func TestGenerateComponentReport(t *testing.T) {
 												Variant:  awsAMD64OVNTest.FlatVariants,
 											},
 										},
-										Status: apitype.ExtremeRegression,
+										Status:            apitype.ExtremeRegression,
+										SampleSuccessRate: 0.51,
+										BaseSuccessRate:   0.91,
+										DecidingFactor:    apitype.DecidingFactorFisher,
+										FisherExact:       1.8251046156331867e-21,
 									},
 									{
 										ComponentReportTestIdentification: apitype.ComponentReportTestIdentification{
@@ -387,7 +404,11 @@ func TestGenerateComponentReport(t *testing.T) {
 												Variant:  awsAMD64OVN2Test.FlatVariants,
 											},
 										},
-										Status: apitype.SignificantRegression,
+										Status:            apitype.SignificantRegression,
+										SampleSuccessRate: 0.81,
+										BaseSuccessRate:   0.91,
+										DecidingFactor:    apitype.DecidingFactorFisher,
+										FisherExact:       0.002621948654892275,
 									},
 								},
 							},
@@ -654,7 +675,11 @@ func TestGenerateComponentReport(t *testing.T) {
 												Variant:  awsAMD64OVNBaseTestStats90Percent.Variants[0],
 											},
 										},
-										Status: apitype.SignificantRegression,
+										Status:            apitype.SignificantRegression,
+										SampleSuccessRate: 0.86,
+										BaseSuccessRate:   0.91,
+										DecidingFactor:    apitype.DecidingFactorFisher,
+										FisherExact:       0.07837082801914011,
 									},
 								},
 							},
@@ -1211,11 +1236,12 @@ func TestGenerateComponentTestDetailsReport(t *testing.T) {
 
 func Test_componentReportGenerator_normalizeProwJobName(t *testing.T) {
 	tests := []struct {
-		name          string
-		sampleRelease string
-		baseRelease   string
-		jobName       string
-		want          string
+		name           string
+		sampleRelease  string
+		baseRelease    string
+		normalizations []apitype.JobNameNormalization
+		jobName        string
+		want           string
 	}{
 		{
 			name:        "base release is removed",
@@ -1229,15 +1255,65 @@ func Test_componentReportGenerator_normalizeProwJobName(t *testing.T) {
 			jobName:       "periodic-ci-openshift-release-master-ci-4.16-e2e-azure-ovn-upgrade",
 			want:          "periodic-ci-openshift-release-master-ci-X.X-e2e-azure-ovn-upgrade",
 		},
+		{
+			name:          "previous release is removed across releases",
+			baseRelease:   "4.15",
+			sampleRelease: "4.16",
+			jobName:       "periodic-ci-openshift-release-master-ci-4.16-upgrade-from-stable-4.15-e2e-aws-ovn-upgrade",
+			want:          "periodic-ci-openshift-release-master-ci-X.X-upgrade-from-stable-X.X-e2e-aws-ovn-upgrade",
+		},
+		{
+			name:          "previous release is kept within the same release",
+			baseRelease:   "4.16",
+			sampleRelease: "4.16",
+			jobName:       "periodic-ci-openshift-release-master-ci-4.16-upgrade-from-stable-4.15-e2e-aws-ovn-upgrade",
+			want:          "periodic-ci-openshift-release-master-ci-X.X-upgrade-from-stable-4.15-e2e-aws-ovn-upgrade",
+		},
 		{
 			name:    "frequency is removed",
 			jobName: "periodic-ci-openshift-release-master-ci-test-job-f27",
 			want:    "periodic-ci-openshift-release-master-ci-test-job-fXX",
 		},
+		{
+			name:        "requested normalizations replace the default",
+			baseRelease: "4.16",
+			normalizations: []apitype.JobNameNormalization{
+				{Pattern: `-nightly-\d{8}$`, Replacement: "-nightly"},
+			},
+			jobName: "release-openshift-origin-installer-e2e-4.16-nightly-20240301",
+			want:    "release-openshift-origin-installer-e2e-4.16-nightly",
+		},
+		{
+			name: "overlapping normalizations apply in order",
+			normalizations: []apitype.JobNameNormalization{
+				{Pattern: `4\.\d+`, Replacement: "X.X"},
+				{Pattern: `ci-X\.X`, Replacement: "ci"},
+			},
+			jobName: "periodic-ci-openshift-release-master-ci-4.16-e2e-aws-ovn",
+			want:    "periodic-ci-openshift-release-master-ci-e2e-aws-ovn",
+		},
+		{
+			name: "normalizations matching nothing leave the name alone",
+			normalizations: []apitype.JobNameNormalization{
+				{Pattern: `-weekly$`, Replacement: ""},
+			},
+			jobName: "periodic-ci-openshift-release-master-ci-4.16-e2e-aws-ovn-f27",
+			want:    "periodic-ci-openshift-release-master-ci-4.16-e2e-aws-ovn-f27",
+		},
+		{
+			name: "invalid normalizations are skipped",
+			normalizations: []apitype.JobNameNormalization{
+				{Pattern: `(4\.16`, Replacement: "X.X"},
+				{Pattern: `-f\d+`, Replacement: ""},
+			},
+			jobName: "periodic-ci-openshift-release-master-ci-4.16-e2e-aws-ovn-f27",
+			want:    "periodic-ci-openshift-release-master-ci-4.16-e2e-aws-ovn",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := &componentReportGenerator{}
+			c.JobNameNormalizations = tt.normalizations
 			if tt.baseRelease != "" {
 				c.BaseRelease = apitype.ComponentReportRequestReleaseOptions{Release: tt.baseRelease}
 			}
@@ -1272,7 +1348,7 @@ func Test_componentReportGenerator_assessComponentStatus(t *testing.T) {
 			baseSuccess:            14,
 			baseFlake:              1,
 			numberOfIgnoredSamples: 2,
-			expectedStatus:         -4,
+			expectedStatus:         apitype.SignificantRegression,
 			expectedFischers:       0.4827586206896551,
 		},
 		{
@@ -1284,7 +1360,7 @@ func Test_componentReportGenerator_assessComponentStatus(t *testing.T) {
 			baseSuccess:            14,
 			baseFlake:              1,
 			numberOfIgnoredSamples: 2,
-			expectedStatus:         -2,
+			expectedStatus:         apitype.SignificantTriagedRegression,
 			expectedFischers:       1,
 		},
 		{
@@ -1296,7 +1372,7 @@ func Test_componentReportGenerator_assessComponentStatus(t *testing.T) {
 			baseSuccess:            14,
 			baseFlake:              1,
 			numberOfIgnoredSamples: 0,
-			expectedStatus:         -4,
+			expectedStatus:         apitype.SignificantRegression,
 			expectedFischers:       0.2413793103448262,
 		},
 		{
@@ -1308,7 +1384,7 @@ func Test_componentReportGenerator_assessComponentStatus(t *testing.T) {
 			baseSuccess:            14,
 			baseFlake:              1,
 			numberOfIgnoredSamples: 0,
-			expectedStatus:         -5,
+			expectedStatus:         apitype.ExtremeRegression,
 			expectedFischers:       6.446725037893782e-09,
 		},
 		{
@@ -1320,7 +1396,7 @@ func Test_componentReportGenerator_assessComponentStatus(t *testing.T) {
 			baseSuccess:            14,
 			baseFlake:              1,
 			numberOfIgnoredSamples: 15,
-			expectedStatus:         -3,
+			expectedStatus:         apitype.ExtremeTriagedRegression,
 			expectedFischers:       0,
 		},
 
@@ -1333,7 +1409,7 @@ func Test_componentReportGenerator_assessComponentStatus(t *testing.T) {
 			baseSuccess:            14,
 			baseFlake:              1,
 			numberOfIgnoredSamples: 10,
-			expectedStatus:         -3,
+			expectedStatus:         apitype.ExtremeTriagedRegression,
 			expectedFischers:       1,
 		},
 
@@ -1346,7 +1422,7 @@ func Test_componentReportGenerator_assessComponentStatus(t *testing.T) {
 			baseSuccess:            14,
 			baseFlake:              1,
 			numberOfIgnoredSamples: 9,
-			expectedStatus:         -5,
+			expectedStatus:         apitype.ExtremeRegression,
 			expectedFischers:       0.285714285714284,
 		},
 	}
@@ -1354,9 +1430,3580 @@ func Test_componentReportGenerator_assessComponentStatus(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			c := &componentReportGenerator{}
 
-			status, fischers := c.assessComponentStatus(tt.sampleTotal, tt.sampleSuccess, tt.sampleFlake, tt.baseTotal, tt.baseSuccess, tt.baseFlake, nil, tt.numberOfIgnoredSamples)
+			status, fischers, _ := c.assessComponentStatus(tt.sampleTotal, tt.sampleSuccess, tt.sampleFlake, tt.baseTotal, tt.baseSuccess, tt.baseFlake, nil, tt.numberOfIgnoredSamples)
 			assert.Equalf(t, tt.expectedStatus, status, "assessComponentStatus expected status not equal")
 			assert.Equalf(t, tt.expectedFischers, fischers, "assessComponentStatus expected fischers value not equal")
 		})
 	}
 }
+
+func Test_componentReportGenerator_assessComponentStatusIdenticalStats(t *testing.T) {
+	tests := []struct {
+		name           string
+		sampleTotal    int
+		sampleSuccess  int
+		sampleFlake    int
+		baseTotal      int
+		baseSuccess    int
+		baseFlake      int
+		expectedFactor apitype.DecidingFactor
+	}{
+		{
+			name:           "identical counts",
+			sampleTotal:    100,
+			sampleSuccess:  80,
+			sampleFlake:    5,
+			baseTotal:      100,
+			baseSuccess:    80,
+			baseFlake:      5,
+			expectedFactor: apitype.DecidingFactorIdentical,
+		},
+		{
+			name:           "identical counts with all failures",
+			sampleTotal:    10,
+			sampleSuccess:  0,
+			sampleFlake:    0,
+			baseTotal:      10,
+			baseSuccess:    0,
+			baseFlake:      0,
+			expectedFactor: apitype.DecidingFactorIdentical,
+		},
+		{
+			name:           "identical rates with different totals",
+			sampleTotal:    20,
+			sampleSuccess:  15,
+			sampleFlake:    1,
+			baseTotal:      1000,
+			baseSuccess:    750,
+			baseFlake:      50,
+			expectedFactor: apitype.DecidingFactorFisher,
+		},
+		{
+			name:           "same pass rate with flakes traded for successes",
+			sampleTotal:    100,
+			sampleSuccess:  75,
+			sampleFlake:    10,
+			baseTotal:      100,
+			baseSuccess:    80,
+			baseFlake:      5,
+			expectedFactor: apitype.DecidingFactorFisher,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &componentReportGenerator{ComponentReportRequestAdvancedOptions: defaultAdvancedOption}
+
+			status, _, factor := c.assessComponentStatus(tt.sampleTotal, tt.sampleSuccess, tt.sampleFlake, tt.baseTotal, tt.baseSuccess, tt.baseFlake, nil, 0)
+			assert.Equal(t, apitype.NotSignificant, status)
+			assert.Equal(t, tt.expectedFactor, factor, "only identical counts should short circuit")
+
+			// the full computation in either direction must agree with the short circuit
+			regressed, _ := c.fischerExactTest(tt.sampleTotal, tt.sampleSuccess, tt.sampleFlake, tt.baseTotal, tt.baseSuccess, tt.baseFlake)
+			improved, _ := c.fischerExactTest(tt.baseTotal, tt.baseSuccess, tt.baseFlake, tt.sampleTotal, tt.sampleSuccess, tt.sampleFlake)
+			assert.False(t, regressed, "full computation unexpectedly found a regression")
+			assert.False(t, improved, "full computation unexpectedly found an improvement")
+		})
+	}
+}
+
+func Test_componentReportGenerator_excludeFirstPRRuns(t *testing.T) {
+	prowJob := "pull-ci-openshift-origin-master-e2e-aws-ovn"
+	run := func(pr string, hour int, success bool) apitype.ComponentJobRunTestStatusRow {
+		row := apitype.ComponentJobRunTestStatusRow{
+			ProwJob:    prowJob,
+			TotalCount: 1,
+			PROrg:      "openshift",
+			PRRepo:     "origin",
+			PRNumber:   pr,
+			StartTime:  civil.DateTime{Date: civil.Date{Year: 2024, Month: 3, Day: 1}, Time: civil.Time{Hour: hour}},
+		}
+		if success {
+			row.SuccessCount = 1
+		}
+		return row
+	}
+	baseStatus := map[string][]apitype.ComponentJobRunTestStatusRow{
+		prowJob: {run("", 1, true), run("", 2, true), run("", 3, true), run("", 4, true)},
+	}
+	sampleStatus := map[string][]apitype.ComponentJobRunTestStatusRow{
+		// each PR fails its first run and passes the retest, the run without a PR is always kept
+		prowJob: {run("2", 5, true), run("1", 2, true), run("1", 1, false), run("2", 3, false), run("", 0, false)},
+	}
+	copyStatus := func(status map[string][]apitype.ComponentJobRunTestStatusRow) map[string][]apitype.ComponentJobRunTestStatusRow {
+		copied := map[string][]apitype.ComponentJobRunTestStatusRow{}
+		for k, v := range status {
+			copied[k] = append([]apitype.ComponentJobRunTestStatusRow{}, v...)
+		}
+		return copied
+	}
+
+	generator := testDetailsGenerator
+	report := generator.generateComponentTestDetailsReport(copyStatus(baseStatus), copyStatus(sampleStatus))
+	assert.Equal(t, 2, report.SampleStats.SuccessCount)
+	assert.Equal(t, 3, report.SampleStats.FailureCount)
+	assert.Equal(t, 0.4, report.SampleStats.SuccessRate)
+
+	generator.ExcludeFirstPRRuns = true
+	report = generator.generateComponentTestDetailsReport(copyStatus(baseStatus), copyStatus(sampleStatus))
+	assert.Equal(t, 2, report.SampleStats.SuccessCount)
+	assert.Equal(t, 1, report.SampleStats.FailureCount)
+	assert.Equal(t, 2.0/3.0, report.SampleStats.SuccessRate)
+	assert.Equal(t, 4, report.BaseStats.SuccessCount, "base runs should not be excluded")
+}
+
+func Test_componentReportGenerator_jobAggregation(t *testing.T) {
+	runs := func(prowJob string, total, success int) []apitype.ComponentJobRunTestStatusRow {
+		rows := []apitype.ComponentJobRunTestStatusRow{}
+		for i := 0; i < total; i++ {
+			row := apitype.ComponentJobRunTestStatusRow{ProwJob: prowJob, ProwJobRunID: fmt.Sprintf("%s-%d", prowJob, i), TotalCount: 1}
+			if i < success {
+				row.SuccessCount = 1
+			}
+			rows = append(rows, row)
+		}
+		return rows
+	}
+	// the dominant job fails half of its many runs, the smaller jobs never fail
+	status := func(dominantSuccess int) map[string][]apitype.ComponentJobRunTestStatusRow {
+		return map[string][]apitype.ComponentJobRunTestStatusRow{
+			"dominant": runs("dominant", 100, dominantSuccess),
+			"small-1":  runs("small-1", 10, 10),
+			"small-2":  runs("small-2", 10, 10),
+		}
+	}
+
+	tests := []struct {
+		name               string
+		aggregation        apitype.JobAggregation
+		expectedSampleRate float64
+	}{
+		{
+			name:               "pooled counts are dominated by the high volume job",
+			expectedSampleRate: 70.0 / 120.0,
+		},
+		{
+			name:               "median of jobs",
+			aggregation:        apitype.JobAggregationMedian,
+			expectedSampleRate: 1,
+		},
+		{
+			name:               "mean of jobs",
+			aggregation:        apitype.JobAggregationMean,
+			expectedSampleRate: 2.5 / 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := testDetailsGenerator
+			generator.JobAggregation = tt.aggregation
+			report := generator.generateComponentTestDetailsReport(status(100), status(50))
+			assert.InDelta(t, tt.expectedSampleRate, report.SampleStats.SuccessRate, 1e-9)
+			assert.Equal(t, 1.0, report.BaseStats.SuccessRate)
+			assert.Equal(t, 70, report.SampleStats.SuccessCount, "counts should stay pooled")
+			assert.Equal(t, 50, report.SampleStats.FailureCount, "counts should stay pooled")
+		})
+	}
+}
+
+func Test_componentReportGenerator_junitAggregation(t *testing.T) {
+	prowJob := "periodic-ci-openshift-release-master-ci-4.16-e2e-aws-ovn"
+	junit := func(runID string, success, flake int) apitype.ComponentJobRunTestStatusRow {
+		return apitype.ComponentJobRunTestStatusRow{ProwJob: prowJob, ProwJobRunID: runID, TotalCount: 1, SuccessCount: success, FlakeCount: flake}
+	}
+	baseStatus := func() map[string][]apitype.ComponentJobRunTestStatusRow {
+		return map[string][]apitype.ComponentJobRunTestStatusRow{
+			prowJob: {junit("1", 1, 0), junit("2", 1, 0)},
+		}
+	}
+	sampleStatus := func() map[string][]apitype.ComponentJobRunTestStatusRow {
+		return map[string][]apitype.ComponentJobRunTestStatusRow{
+			// run 3 has two junits for the test, one passed and one failed
+			prowJob: {junit("3", 1, 0), junit("3", 0, 0), junit("4", 1, 0)},
+		}
+	}
+	tests := []struct {
+		name            string
+		aggregation     apitype.JunitAggregation
+		expectedTotal   int
+		expectedSuccess int
+	}{
+		{
+			name:            "default counts each junit",
+			expectedTotal:   3,
+			expectedSuccess: 2,
+		},
+		{
+			name:            "any pass",
+			aggregation:     apitype.JunitAggregationAnyPass,
+			expectedTotal:   2,
+			expectedSuccess: 2,
+		},
+		{
+			name:            "all pass",
+			aggregation:     apitype.JunitAggregationAllPass,
+			expectedTotal:   2,
+			expectedSuccess: 1,
+		},
+		{
+			name:            "majority fails on a tie",
+			aggregation:     apitype.JunitAggregationMajority,
+			expectedTotal:   2,
+			expectedSuccess: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := testDetailsGenerator
+			generator.JunitAggregation = tt.aggregation
+			report := generator.generateComponentTestDetailsReport(baseStatus(), sampleStatus())
+			assert.Equal(t, tt.expectedTotal, report.SampleStats.SuccessCount+report.SampleStats.FailureCount+report.SampleStats.FlakeCount)
+			assert.Equal(t, tt.expectedSuccess, report.SampleStats.SuccessCount)
+			assert.Equal(t, 2, report.BaseStats.SuccessCount)
+		})
+	}
+}
+
+func Test_junitRunOutcome(t *testing.T) {
+	tests := []struct {
+		name                      string
+		aggregation               apitype.JunitAggregation
+		passes, failures, flakes  int
+		expectPassed, expectFlake bool
+	}{
+		{name: "any pass with a pass and a failure", aggregation: apitype.JunitAggregationAnyPass, passes: 1, failures: 1, expectPassed: true},
+		{name: "any pass with a flake", aggregation: apitype.JunitAggregationAnyPass, passes: 1, flakes: 1, expectFlake: true},
+		{name: "any pass with only failures", aggregation: apitype.JunitAggregationAnyPass, failures: 2},
+		{name: "all pass with a pass and a failure", aggregation: apitype.JunitAggregationAllPass, passes: 1, failures: 1},
+		{name: "all pass with a flake", aggregation: apitype.JunitAggregationAllPass, passes: 1, flakes: 1, expectFlake: true},
+		{name: "all pass with only passes", aggregation: apitype.JunitAggregationAllPass, passes: 2, expectPassed: true},
+		{name: "majority with a pass and a failure", aggregation: apitype.JunitAggregationMajority, passes: 1, failures: 1},
+		{name: "majority with more passes", aggregation: apitype.JunitAggregationMajority, passes: 2, failures: 1, expectPassed: true},
+		{name: "majority with a flake", aggregation: apitype.JunitAggregationMajority, passes: 1, flakes: 1, failures: 1, expectFlake: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			passed, flaked := junitRunOutcome(tt.aggregation, tt.passes, tt.failures, tt.flakes)
+			assert.Equal(t, tt.expectPassed, passed)
+			assert.Equal(t, tt.expectFlake, flaked)
+		})
+	}
+}
+
+func Test_junitTable(t *testing.T) {
+	deduped := fmt.Sprintf(dedupedJunitTable, "ci_analysis_us")
+	assert.Equal(t, deduped, junitTable("", "ci_analysis_us"), "junits should only be collapsed on request")
+	for _, aggregation := range []apitype.JunitAggregation{apitype.JunitAggregationAnyPass, apitype.JunitAggregationAllPass, apitype.JunitAggregationMajority} {
+		table := junitTable(aggregation, "ci_analysis_us")
+		assert.Contains(t, table, deduped)
+		assert.Contains(t, table, "PARTITION BY prowjob_build_id, test_name, testsuite")
+		assert.Contains(t, table, "WHERE run_row_num = 1")
+	}
+}
+
+func Test_componentReportGenerator_excludeRunsWithoutTest(t *testing.T) {
+	prowJob := "periodic-ci-openshift-release-master-ci-4.16-e2e-aws-ovn"
+	otherJob := "periodic-ci-openshift-release-master-ci-4.16-e2e-aws-ovn-serial"
+	run := func(prowJob, pr string, hour, total, success int) apitype.ComponentJobRunTestStatusRow {
+		return apitype.ComponentJobRunTestStatusRow{
+			ProwJob:      prowJob,
+			TotalCount:   total,
+			SuccessCount: success,
+			PROrg:        "openshift",
+			PRRepo:       "origin",
+			PRNumber:     pr,
+			StartTime:    civil.DateTime{Date: civil.Date{Year: 2024, Month: 3, Day: 1}, Time: civil.Time{Hour: hour}},
+		}
+	}
+	baseStatus := func() map[string][]apitype.ComponentJobRunTestStatusRow {
+		return map[string][]apitype.ComponentJobRunTestStatusRow{
+			prowJob: {run(prowJob, "", 1, 1, 1), run(prowJob, "", 2, 0, 0), run(prowJob, "", 3, 1, 1)},
+		}
+	}
+	sampleStatus := func() map[string][]apitype.ComponentJobRunTestStatusRow {
+		return map[string][]apitype.ComponentJobRunTestStatusRow{
+			// the first run of the PR did not run the test, the failure after it did
+			prowJob: {run(prowJob, "1", 1, 0, 0), run(prowJob, "1", 2, 1, 0), run(prowJob, "1", 3, 1, 1)},
+			// a job that never ran the test
+			otherJob: {run(otherJob, "", 1, 0, 0)},
+		}
+	}
+
+	generator := testDetailsGenerator
+	report := generator.generateComponentTestDetailsReport(baseStatus(), sampleStatus())
+	assert.Equal(t, 0, report.RunsWithoutTest, "runs without the test should only be counted when excluded")
+	assert.Equal(t, 2, len(report.JobStats))
+	assert.Equal(t, 3, len(report.JobStats[0].BaseJobRunStats))
+
+	generator.ExcludeRunsWithoutTest = true
+	report = generator.generateComponentTestDetailsReport(baseStatus(), sampleStatus())
+	assert.Equal(t, 3, report.RunsWithoutTest)
+	assert.Equal(t, 1, len(report.JobStats), "a job that never ran the test should be dropped")
+	assert.Equal(t, 2, len(report.JobStats[0].BaseJobRunStats))
+	assert.Equal(t, 2, len(report.JobStats[0].SampleJobRunStats))
+	assert.Equal(t, 1.0, report.BaseStats.SuccessRate)
+	assert.Equal(t, 0.5, report.SampleStats.SuccessRate)
+
+	generator.ExcludeFirstPRRuns = true
+	report = generator.generateComponentTestDetailsReport(baseStatus(), sampleStatus())
+	assert.Equal(t, 1, report.SampleStats.SuccessCount)
+	assert.Equal(t, 0, report.SampleStats.FailureCount, "the first run of the PR that ran the test should be excluded")
+}
+
+func Test_componentReportGenerator_jobSetOverlap(t *testing.T) {
+	status := func(prowJobs ...string) map[string][]apitype.ComponentJobRunTestStatusRow {
+		status := map[string][]apitype.ComponentJobRunTestStatusRow{}
+		for _, prowJob := range prowJobs {
+			status[prowJob] = []apitype.ComponentJobRunTestStatusRow{{ProwJob: prowJob, TotalCount: 1, SuccessCount: 1}}
+		}
+		return status
+	}
+	tests := []struct {
+		name            string
+		base            []string
+		sample          []string
+		minimumOverlap  int
+		expectedOverlap float64
+		expectWarning   bool
+	}{
+		{
+			name:            "fully overlapping job sets",
+			base:            []string{"job-a", "job-b"},
+			sample:          []string{"job-b", "job-a"},
+			minimumOverlap:  100,
+			expectedOverlap: 1,
+		},
+		{
+			name:            "disjoint job sets",
+			base:            []string{"job-a", "job-b"},
+			sample:          []string{"job-c"},
+			minimumOverlap:  50,
+			expectedOverlap: 0,
+			expectWarning:   true,
+		},
+		{
+			name:            "partially overlapping job sets",
+			base:            []string{"job-a", "job-b", "job-c"},
+			sample:          []string{"job-b", "job-c", "job-d"},
+			minimumOverlap:  50,
+			expectedOverlap: 0.5,
+		},
+		{
+			name:            "low overlap without a minimum does not warn",
+			base:            []string{"job-a"},
+			sample:          []string{"job-b"},
+			expectedOverlap: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := testDetailsGenerator
+			generator.MinimumJobSetOverlap = tt.minimumOverlap
+			report := generator.generateComponentTestDetailsReport(status(tt.base...), status(tt.sample...))
+			assert.Equal(t, tt.expectedOverlap, report.JobSetOverlap)
+			if tt.expectWarning {
+				assert.Equal(t, 1, len(report.Warnings))
+			} else {
+				assert.Empty(t, report.Warnings)
+			}
+		})
+	}
+}
+
+func Test_componentReportGenerator_sortJobStatsByContribution(t *testing.T) {
+	row := func(prowJob string, total, success int) []apitype.ComponentJobRunTestStatusRow {
+		return []apitype.ComponentJobRunTestStatusRow{{ProwJob: prowJob, TotalCount: total, SuccessCount: success}}
+	}
+	status := func() (map[string][]apitype.ComponentJobRunTestStatusRow, map[string][]apitype.ComponentJobRunTestStatusRow) {
+		baseStatus := map[string][]apitype.ComponentJobRunTestStatusRow{
+			"job-a": row("job-a", 10, 10),
+			"job-b": row("job-b", 10, 10),
+			"job-c": row("job-c", 10, 5),
+		}
+		sampleStatus := map[string][]apitype.ComponentJobRunTestStatusRow{
+			// drops 20 points over 10 runs
+			"job-a": row("job-a", 10, 8),
+			// drops 75 points over 4 runs
+			"job-b": row("job-b", 4, 1),
+			// does not drop
+			"job-c": row("job-c", 10, 5),
+			// only in the sample, drops from the 25 of 30 runs passing in the whole base
+			"job-d": row("job-d", 2, 0),
+		}
+		return baseStatus, sampleStatus
+	}
+	jobNames := func(jobStats []apitype.ComponentReportTestDetailsJobStats) []string {
+		names := []string{}
+		for _, stats := range jobStats {
+			names = append(names, stats.JobName)
+		}
+		return names
+	}
+	tests := []struct {
+		name          string
+		sortBy        apitype.JobStatsSort
+		expectedOrder []string
+	}{
+		{
+			name:          "default orders by job name",
+			expectedOrder: []string{"job-a", "job-b", "job-c", "job-d"},
+		},
+		{
+			name:          "contribution orders the worst offender first",
+			sortBy:        apitype.JobStatsSortContribution,
+			expectedOrder: []string{"job-b", "job-a", "job-d", "job-c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := testDetailsGenerator
+			generator.SortJobStatsBy = tt.sortBy
+			report := generator.generateComponentTestDetailsReport(status())
+			assert.Equal(t, tt.expectedOrder, jobNames(report.JobStats))
+		})
+	}
+}
+
+func Test_sortJobStatsByContribution_ties(t *testing.T) {
+	stats := func(jobName string, sampleTotal, sampleSuccess int) apitype.ComponentReportTestDetailsJobStats {
+		return apitype.ComponentReportTestDetailsJobStats{
+			JobName: jobName,
+			BaseStats: apitype.ComponentReportTestDetailsTestStats{
+				SuccessRate:  1,
+				SuccessCount: 10,
+			},
+			SampleStats: apitype.ComponentReportTestDetailsTestStats{
+				SuccessRate:  float64(sampleSuccess) / float64(sampleTotal),
+				SuccessCount: sampleSuccess,
+				FailureCount: sampleTotal - sampleSuccess,
+			},
+		}
+	}
+	jobStats := []apitype.ComponentReportTestDetailsJobStats{
+		stats("job-c", 4, 2),
+		stats("job-b", 2, 0),
+		stats("job-a", 10, 10),
+	}
+	sortJobStatsByContribution(jobStats, 1)
+	assert.Equal(t, "job-b", jobStats[0].JobName)
+	assert.Equal(t, "job-c", jobStats[1].JobName)
+	assert.Equal(t, "job-a", jobStats[2].JobName)
+}
+
+func Test_componentReportGenerator_baseMaxRunAge(t *testing.T) {
+	prowJob := "periodic-ci-openshift-release-master-ci-4.15-e2e-aws-ovn"
+	windowEnd := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	run := func(daysAgo int, success bool) apitype.ComponentJobRunTestStatusRow {
+		row := apitype.ComponentJobRunTestStatusRow{
+			ProwJob:    prowJob,
+			TotalCount: 1,
+			StartTime:  civil.DateTimeOf(windowEnd.AddDate(0, 0, -daysAgo)),
+		}
+		if success {
+			row.SuccessCount = 1
+		}
+		return row
+	}
+	baseStatus := func() map[string][]apitype.ComponentJobRunTestStatusRow {
+		return map[string][]apitype.ComponentJobRunTestStatusRow{
+			// the old runs failed, the recent runs pass
+			prowJob: {run(25, false), run(20, false), run(5, true), run(1, true)},
+		}
+	}
+	sampleStatus := func() map[string][]apitype.ComponentJobRunTestStatusRow {
+		return map[string][]apitype.ComponentJobRunTestStatusRow{
+			prowJob: {run(1, true)},
+		}
+	}
+
+	generator := testDetailsGenerator
+	generator.BaseRelease = apitype.ComponentReportRequestReleaseOptions{
+		Release: "4.15",
+		Start:   windowEnd.AddDate(0, 0, -30),
+		End:     windowEnd,
+	}
+	report := generator.generateComponentTestDetailsReport(baseStatus(), sampleStatus())
+	assert.Equal(t, 2, report.BaseStats.SuccessCount)
+	assert.Equal(t, 2, report.BaseStats.FailureCount)
+	assert.Equal(t, 0.5, report.BaseStats.SuccessRate)
+
+	generator.BaseRelease.MaxRunAge = 14 * 24 * time.Hour
+	report = generator.generateComponentTestDetailsReport(baseStatus(), sampleStatus())
+	assert.Equal(t, 2, report.BaseStats.SuccessCount)
+	assert.Equal(t, 0, report.BaseStats.FailureCount)
+	assert.Equal(t, 1.0, report.BaseStats.SuccessRate)
+	assert.Equal(t, 1, report.SampleStats.SuccessCount, "sample runs should not be excluded")
+}
+
+func Test_componentReportGenerator_testDetailsBaseOverride(t *testing.T) {
+	prowJob := "periodic-ci-openshift-release-master-ci-4.15-e2e-aws-ovn"
+	windowEnd := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	run := func(daysAgo int) apitype.ComponentJobRunTestStatusRow {
+		return apitype.ComponentJobRunTestStatusRow{
+			ProwJob:      prowJob,
+			TotalCount:   1,
+			SuccessCount: 1,
+			StartTime:    civil.DateTimeOf(windowEnd.AddDate(0, 0, -daysAgo)),
+		}
+	}
+	baseStatus := func() map[string][]apitype.ComponentJobRunTestStatusRow {
+		return map[string][]apitype.ComponentJobRunTestStatusRow{
+			prowJob: {run(40), run(20), run(5)},
+		}
+	}
+	sampleStatus := func() map[string][]apitype.ComponentJobRunTestStatusRow {
+		return map[string][]apitype.ComponentJobRunTestStatusRow{
+			prowJob: {run(1)},
+		}
+	}
+	baseRelease := apitype.ComponentReportRequestReleaseOptions{
+		Release:   "4.15",
+		Start:     windowEnd.AddDate(0, 0, -30),
+		End:       windowEnd,
+		MaxRunAge: 30 * 24 * time.Hour,
+	}
+
+	tests := []struct {
+		name                         string
+		override                     apitype.ComponentReportReleaseOverride
+		expectedBaseRelease          string
+		expectedRequestedBaseRelease string
+		expectedBaseSuccess          int
+	}{
+		{
+			name:                         "override applies",
+			override:                     apitype.ComponentReportReleaseOverride{Variant: "platform", Value: "aws", Release: "4.14", Start: windowEnd.AddDate(0, 0, -60), End: windowEnd.AddDate(0, 0, -15)},
+			expectedBaseRelease:          "4.14",
+			expectedRequestedBaseRelease: "4.15",
+			expectedBaseSuccess:          3,
+		},
+		{
+			name:                "override for another variant",
+			override:            apitype.ComponentReportReleaseOverride{Variant: "platform", Value: "gcp", Release: "4.14", Start: windowEnd.AddDate(0, 0, -60), End: windowEnd},
+			expectedBaseRelease: "4.15",
+			expectedBaseSuccess: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := testDetailsGenerator
+			generator.BaseRelease = baseRelease
+			generator.BaseRelease.VariantOverrides = []apitype.ComponentReportReleaseOverride{tt.override}
+			report := generator.generateComponentTestDetailsReport(baseStatus(), sampleStatus())
+			assert.Equal(t, tt.expectedBaseRelease, report.BaseStats.Release)
+			assert.Equal(t, tt.expectedRequestedBaseRelease, report.RequestedBaseRelease)
+			// the max run age is applied to the basis actually used
+			assert.Equal(t, tt.expectedBaseSuccess, report.BaseStats.SuccessCount)
+		})
+	}
+}
+
+func Test_componentReportGenerator_assessComponentStatusExtremePassRateFloor(t *testing.T) {
+	tests := []struct {
+		name           string
+		floor          int
+		sampleTotal    int
+		sampleSuccess  int
+		sampleFlake    int
+		baseTotal      int
+		baseSuccess    int
+		baseFlake      int
+		expectedStatus apitype.ComponentReportStatus
+	}{
+		{
+			name:           "below floor with matching basis",
+			floor:          80,
+			sampleTotal:    100,
+			sampleSuccess:  79,
+			baseTotal:      100,
+			baseSuccess:    79,
+			expectedStatus: apitype.ExtremeRegression,
+		},
+		{
+			name:           "at floor is not extreme",
+			floor:          80,
+			sampleTotal:    100,
+			sampleSuccess:  78,
+			sampleFlake:    2,
+			baseTotal:      100,
+			baseSuccess:    80,
+			expectedStatus: apitype.NotSignificant,
+		},
+		{
+			name:           "below floor without basis",
+			floor:          80,
+			sampleTotal:    10,
+			sampleSuccess:  5,
+			expectedStatus: apitype.ExtremeRegression,
+		},
+		{
+			name:           "below floor with too few failures",
+			floor:          80,
+			sampleTotal:    5,
+			sampleSuccess:  3,
+			baseTotal:      5,
+			baseSuccess:    3,
+			expectedStatus: apitype.NotSignificant,
+		},
+		{
+			name:           "floor disabled",
+			sampleTotal:    100,
+			sampleSuccess:  50,
+			baseTotal:      100,
+			baseSuccess:    50,
+			expectedStatus: apitype.NotSignificant,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &componentReportGenerator{ComponentReportRequestAdvancedOptions: defaultAdvancedOption}
+			c.ExtremePassRateFloor = tt.floor
+
+			status, _, _ := c.assessComponentStatus(tt.sampleTotal, tt.sampleSuccess, tt.sampleFlake, tt.baseTotal, tt.baseSuccess, tt.baseFlake, nil, 0)
+			assert.Equalf(t, tt.expectedStatus, status, "assessComponentStatus expected status not equal")
+		})
+	}
+}
+
+func Test_componentReportGenerator_missingBasisPassRateFloor(t *testing.T) {
+	testIdentification := apitype.ComponentTestIdentification{
+		TestID:       "1",
+		Platform:     "aws",
+		Arch:         "amd64",
+		Network:      "ovn",
+		Upgrade:      "upgrade-micro",
+		FlatVariants: "standard",
+	}
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+	tests := []struct {
+		name                   string
+		floor                  int
+		sampleSuccess          int
+		expectedStatus         apitype.ComponentReportStatus
+		expectedDecidingFactor apitype.DecidingFactor
+	}{
+		{
+			name:           "floor disabled",
+			sampleSuccess:  50,
+			expectedStatus: apitype.MissingBasis,
+		},
+		{
+			name:                   "below floor",
+			floor:                  80,
+			sampleSuccess:          50,
+			expectedStatus:         apitype.ExtremeRegression,
+			expectedDecidingFactor: apitype.DecidingFactorPassRateFloor,
+		},
+		{
+			name:           "above floor",
+			floor:          80,
+			sampleSuccess:  90,
+			expectedStatus: apitype.MissingBasis,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sampleStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+				testIdentification: {TestName: "test 1", TotalCount: 100, SuccessCount: tt.sampleSuccess},
+			}
+			generator := defaultComponentReportGenerator
+			generator.ExtremePassRateFloor = tt.floor
+			report := generator.generateComponentTestReport(map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{}, sampleStatus, []apitype.TestRegression{})
+			column := report.Rows[0].Columns[0]
+			assert.Equal(t, tt.expectedStatus, column.Status)
+			if tt.expectedDecidingFactor != "" {
+				assert.Equal(t, 1, len(column.RegressedTests))
+				assert.Equal(t, tt.expectedDecidingFactor, column.RegressedTests[0].DecidingFactor)
+			} else {
+				assert.Empty(t, column.RegressedTests)
+			}
+		})
+	}
+}
+
+func Test_componentReportGenerator_weakBasis(t *testing.T) {
+	testIdentification := apitype.ComponentTestIdentification{
+		TestID:       "1",
+		Platform:     "aws",
+		Arch:         "amd64",
+		Network:      "ovn",
+		Upgrade:      "upgrade-micro",
+		FlatVariants: "standard",
+	}
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+	tests := []struct {
+		name              string
+		minimumBasisRuns  int
+		suppress          bool
+		expectedStatus    apitype.ComponentReportStatus
+		expectedWeakBasis bool
+	}{
+		{
+			name:           "check disabled",
+			suppress:       true,
+			expectedStatus: apitype.ExtremeRegression,
+		},
+		{
+			name:             "enough basis runs",
+			minimumBasisRuns: 20,
+			suppress:         true,
+			expectedStatus:   apitype.ExtremeRegression,
+		},
+		{
+			name:              "weak basis is flagged",
+			minimumBasisRuns:  30,
+			expectedStatus:    apitype.ExtremeRegression,
+			expectedWeakBasis: true,
+		},
+		{
+			name:             "weak basis is suppressed",
+			minimumBasisRuns: 30,
+			suppress:         true,
+			expectedStatus:   apitype.NotSignificant,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+				testIdentification: {TestName: "test 1", TotalCount: 20, SuccessCount: 20},
+			}
+			sampleStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+				testIdentification: {TestName: "test 1", TotalCount: 100, SuccessCount: 50},
+			}
+			generator := defaultComponentReportGenerator
+			generator.MinimumBasisRuns = tt.minimumBasisRuns
+			generator.SuppressWeakBasisRegressions = tt.suppress
+			report := generator.generateComponentTestReport(baseStatus, sampleStatus, []apitype.TestRegression{})
+			column := report.Rows[0].Columns[0]
+			assert.Equal(t, tt.expectedStatus, column.Status)
+			if tt.expectedStatus == apitype.NotSignificant {
+				assert.Empty(t, column.RegressedTests)
+				return
+			}
+			assert.Equal(t, 1, len(column.RegressedTests))
+			assert.Equal(t, tt.expectedWeakBasis, column.RegressedTests[0].WeakBasis)
+		})
+	}
+}
+
+func Test_componentReportGenerator_confidenceOverrides(t *testing.T) {
+	aws := apitype.ComponentTestIdentification{TestID: "1", Platform: "aws", Arch: "amd64", Network: "ovn", Upgrade: "upgrade-micro", FlatVariants: "standard"}
+	metal := aws
+	metal.Platform = "metal"
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+	// a drop from 100% to 92% over 50 runs, p is about 0.06
+	baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		aws:   {TestName: "test 1", TotalCount: 50, SuccessCount: 50},
+		metal: {TestName: "test 1", TotalCount: 50, SuccessCount: 50},
+	}
+	sampleStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		aws:   {TestName: "test 1", TotalCount: 50, SuccessCount: 46},
+		metal: {TestName: "test 1", TotalCount: 50, SuccessCount: 46},
+	}
+	generator := defaultComponentReportGenerator
+	generator.ConfidenceOverrides = []apitype.VariantConfidenceOverride{
+		{VariantName: "platform", VariantValue: "metal", Confidence: 90},
+	}
+	report := generator.generateComponentTestReport(baseStatus, sampleStatus, []apitype.TestRegression{})
+	assert.Equal(t, 1, len(report.Rows))
+	statuses := map[string]apitype.ComponentReportColumn{}
+	for _, column := range report.Rows[0].Columns {
+		statuses[column.Platform] = column
+	}
+	assert.Equal(t, apitype.NotSignificant, statuses["aws"].Status)
+	assert.Empty(t, statuses["aws"].RegressedTests)
+	assert.Equal(t, apitype.SignificantRegression, statuses["metal"].Status)
+	assert.Equal(t, 1, len(statuses["metal"].RegressedTests))
+	assert.Equal(t, 90, statuses["metal"].RegressedTests[0].OverriddenConfidence)
+}
+
+func Test_componentReportGenerator_minimumFailureByComponent(t *testing.T) {
+	test1 := apitype.ComponentTestIdentification{TestID: "1", Platform: "aws", Arch: "amd64", Network: "ovn", Upgrade: "upgrade-micro", FlatVariants: "standard"}
+	test2 := test1
+	test2.TestID = "2"
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+	// six failures over 50 runs against a perfect basis is a regression under the default minimum of 3
+	baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		test1: {TestName: "test 1", TotalCount: 100, SuccessCount: 100},
+		test2: {TestName: "test 2", TotalCount: 100, SuccessCount: 100},
+	}
+	sampleStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		test1: {TestName: "test 1", TotalCount: 50, SuccessCount: 44},
+		test2: {TestName: "test 2", TotalCount: 50, SuccessCount: 44},
+	}
+	generator := defaultComponentReportGenerator
+	generator.MinimumFailureByComponent = map[string]int{"component 1": 10}
+	report := generator.generateComponentTestReport(baseStatus, sampleStatus, []apitype.TestRegression{})
+	statuses := map[string]apitype.ComponentReportStatus{}
+	for _, row := range report.Rows {
+		assert.Equal(t, 1, len(row.Columns))
+		statuses[row.Component] = row.Columns[0].Status
+	}
+	assert.Equal(t, apitype.NotSignificant, statuses["component 1"])
+	assert.True(t, statuses["component 2"].WorseThan(apitype.RegressionWarning))
+
+	details := testDetailsGenerator
+	details.MinimumFailureByComponent = map[string]int{"component 1": 10}
+	assessor := details.withMinimumFailureFor("component 1")
+	status, _, factor := assessor.assessComponentStatus(50, 44, 0, 100, 100, 0, nil, 0)
+	assert.Equal(t, apitype.NotSignificant, status)
+	assert.Equal(t, apitype.DecidingFactorMinimumFailure, factor)
+	assert.Equal(t, 3, details.withMinimumFailureFor("component 2").MinimumFailure)
+}
+
+func Test_componentReportGenerator_minimumBasisRunsByVariant(t *testing.T) {
+	aws := apitype.ComponentTestIdentification{TestID: "1", Platform: "aws", Arch: "amd64", Network: "ovn", Upgrade: "upgrade-micro", FlatVariants: "standard"}
+	metal := aws
+	metal.Platform = "metal"
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+	baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		aws:   {TestName: "test 1", TotalCount: 10, SuccessCount: 10},
+		metal: {TestName: "test 1", TotalCount: 10, SuccessCount: 10},
+	}
+	sampleStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		aws:   {TestName: "test 1", TotalCount: 100, SuccessCount: 50},
+		metal: {TestName: "test 1", TotalCount: 100, SuccessCount: 50},
+	}
+
+	generator := defaultComponentReportGenerator
+	generator.MinimumBasisRuns = 20
+	generator.MinimumBasisRunsByVariant = map[string]int{"platform=metal": 5}
+	report := generator.generateComponentTestReport(baseStatus, sampleStatus, []apitype.TestRegression{})
+	weakBasis := map[string]bool{}
+	for _, column := range report.Rows[0].Columns {
+		assert.Equal(t, 1, len(column.RegressedTests))
+		weakBasis[column.Platform] = column.RegressedTests[0].WeakBasis
+	}
+	// metal runs less often, so the same number of basis runs is enough there
+	assert.Equal(t, map[string]bool{"aws": true, "metal": false}, weakBasis)
+}
+
+func Test_componentReportGenerator_minimumFailingJobs(t *testing.T) {
+	testIdentification := apitype.ComponentTestIdentification{
+		TestID:       "1",
+		Platform:     "aws",
+		Arch:         "amd64",
+		Network:      "ovn",
+		Upgrade:      "upgrade-micro",
+		FlatVariants: "standard",
+	}
+	baseStats := apitype.ComponentTestStatus{
+		TestName:        "test 1",
+		Variants:        []string{"standard"},
+		TotalCount:      1000,
+		SuccessCount:    900,
+		FlakeCount:      10,
+		FailingJobCount: 5,
+	}
+	tests := []struct {
+		name               string
+		minimumFailingJobs int
+		failingJobCount    int
+		expectedStatus     apitype.ComponentReportStatus
+		expectedSingleJob  bool
+		triagedJobRuns     int
+	}{
+		{
+			name:              "failures from a single job are annotated",
+			failingJobCount:   1,
+			expectedStatus:    apitype.ExtremeRegression,
+			expectedSingleJob: true,
+		},
+		{
+			name:               "failures from a single job are suppressed",
+			minimumFailingJobs: 2,
+			failingJobCount:    1,
+			expectedStatus:     apitype.NotSignificant,
+		},
+		{
+			name:               "failures across enough jobs are reported",
+			minimumFailingJobs: 2,
+			failingJobCount:    2,
+			expectedStatus:     apitype.ExtremeRegression,
+		},
+		{
+			name:               "triaged regressions are not suppressed",
+			minimumFailingJobs: 2,
+			failingJobCount:    1,
+			triagedJobRuns:     50,
+			expectedStatus:     apitype.ExtremeTriagedRegression,
+			expectedSingleJob:  true,
+		},
+	}
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := defaultComponentReportGenerator
+			generator.MinimumFailingJobs = tt.minimumFailingJobs
+			generator.replayedTriagedIncidents = map[apitype.ComponentReportTestIdentification]RecordedTriagedIncidents{
+				buildTestID(baseStats, testIdentification): {ImpactedJobRuns: tt.triagedJobRuns},
+			}
+			sampleStats := apitype.ComponentTestStatus{
+				TestName:        "test 1",
+				Variants:        []string{"standard"},
+				TotalCount:      100,
+				SuccessCount:    50,
+				FailingJobCount: tt.failingJobCount,
+			}
+
+			report := generator.generateComponentTestReport(
+				map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{testIdentification: baseStats},
+				map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{testIdentification: sampleStats},
+				[]apitype.TestRegression{})
+			assert.Equal(t, 1, len(report.Rows))
+			assert.Equal(t, 1, len(report.Rows[0].Columns))
+			column := report.Rows[0].Columns[0]
+			assert.Equal(t, tt.expectedStatus, column.Status)
+			switch tt.expectedStatus {
+			case apitype.NotSignificant:
+				assert.Empty(t, column.RegressedTests)
+			case apitype.ExtremeTriagedRegression:
+				assert.Equal(t, 1, len(column.TriagedIncidents))
+				assert.Equal(t, tt.expectedSingleJob, column.TriagedIncidents[0].SingleJobFailures)
+			default:
+				assert.Equal(t, 1, len(column.RegressedTests))
+				assert.Equal(t, tt.expectedSingleJob, column.RegressedTests[0].SingleJobFailures)
+			}
+		})
+	}
+}
+
+func Test_componentReportGenerator_capabilityStatuses(t *testing.T) {
+	regressedTest := apitype.ComponentTestIdentification{
+		TestID:       "1",
+		Platform:     "aws",
+		Arch:         "amd64",
+		Network:      "ovn",
+		Upgrade:      "upgrade-micro",
+		FlatVariants: "standard",
+	}
+	passingTest := regressedTest
+	passingTest.TestID = "4"
+	baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		regressedTest: {TestName: "test 1", Variants: []string{"standard"}, TotalCount: 1000, SuccessCount: 900, FlakeCount: 10},
+		passingTest:   {TestName: "test 4", Variants: []string{"standard"}, TotalCount: 1000, SuccessCount: 900, FlakeCount: 10},
+	}
+	sampleStatus := func() map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus {
+		return map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+			regressedTest: {TestName: "test 1", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 50},
+			passingTest:   {TestName: "test 4", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 90, FlakeCount: 1},
+		}
+	}
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+
+	generator := defaultComponentReportGenerator
+	report := generator.generateComponentTestReport(baseStatus, sampleStatus(), []apitype.TestRegression{})
+	assert.Equal(t, 1, len(report.Rows))
+	assert.Nil(t, report.Rows[0].Columns[0].CapabilityStatuses, "capability statuses should only be included on request")
+
+	generator.IncludeCapabilityStatuses = true
+	report = generator.generateComponentTestReport(baseStatus, sampleStatus(), []apitype.TestRegression{})
+	assert.Equal(t, 1, len(report.Rows))
+	assert.Equal(t, "component 1", report.Rows[0].Component)
+	column := report.Rows[0].Columns[0]
+	assert.Equal(t, apitype.ExtremeRegression, column.Status)
+	assert.Equal(t, map[string]apitype.ComponentReportStatus{
+		"cap1": apitype.ExtremeRegression,
+		"cap2": apitype.NotSignificant,
+	}, column.CapabilityStatuses)
+}
+
+func Test_componentReportGenerator_capabilityOverrides(t *testing.T) {
+	regressedTest := apitype.ComponentTestIdentification{
+		TestID:       "1",
+		Platform:     "aws",
+		Arch:         "amd64",
+		Network:      "ovn",
+		Upgrade:      "upgrade-micro",
+		FlatVariants: "standard",
+	}
+	passingTest := regressedTest
+	passingTest.TestID = "4"
+	baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		regressedTest: {TestName: "test 1", Variants: []string{"standard"}, TotalCount: 1000, SuccessCount: 900, FlakeCount: 10},
+		passingTest:   {TestName: "test 4", Variants: []string{"standard"}, TotalCount: 1000, SuccessCount: 900, FlakeCount: 10},
+	}
+	sampleStatus := func() map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus {
+		return map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+			regressedTest: {TestName: "test 1", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 50},
+			passingTest:   {TestName: "test 4", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 90, FlakeCount: 1},
+		}
+	}
+	capabilityStatuses := func(report apitype.ComponentReport) map[string]apitype.ComponentReportStatus {
+		statuses := map[string]apitype.ComponentReportStatus{}
+		for _, row := range report.Rows {
+			statuses[row.Capability] = row.Columns[0].Status
+		}
+		return statuses
+	}
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+
+	generator := defaultComponentReportGenerator
+	generator.Component = "component 1"
+	report := generator.generateComponentTestReport(baseStatus, sampleStatus(), []apitype.TestRegression{})
+	assert.Equal(t, map[string]apitype.ComponentReportStatus{
+		"cap1": apitype.ExtremeRegression,
+		"cap2": apitype.NotSignificant,
+	}, capabilityStatuses(report))
+
+	// the regressed test is reassigned from cap1 to cap2 and a new cap3
+	generator.CapabilityOverrides = map[string][]string{"1": {"cap2", "cap3"}}
+	report = generator.generateComponentTestReport(baseStatus, sampleStatus(), []apitype.TestRegression{})
+	assert.Equal(t, map[string]apitype.ComponentReportStatus{
+		"cap2": apitype.ExtremeRegression,
+		"cap3": apitype.ExtremeRegression,
+	}, capabilityStatuses(report))
+
+	// overrides of other tests leave the mapping alone
+	generator.CapabilityOverrides = map[string][]string{"2": {"cap3"}}
+	report = generator.generateComponentTestReport(baseStatus, sampleStatus(), []apitype.TestRegression{})
+	assert.Equal(t, map[string]apitype.ComponentReportStatus{
+		"cap1": apitype.ExtremeRegression,
+		"cap2": apitype.NotSignificant,
+	}, capabilityStatuses(report))
+}
+
+func Test_componentReportGenerator_groupBySuite(t *testing.T) {
+	serialTest := apitype.ComponentTestIdentification{
+		TestID:       "1",
+		Platform:     "aws",
+		Arch:         "amd64",
+		Network:      "ovn",
+		Upgrade:      "upgrade-micro",
+		FlatVariants: "standard",
+	}
+	parallelTest := serialTest
+	parallelTest.TestID = "4"
+	baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		serialTest:   {TestName: "test 1", TestSuite: "serial", Variants: []string{"standard"}, TotalCount: 1000, SuccessCount: 900, FlakeCount: 10},
+		parallelTest: {TestName: "test 4", TestSuite: "parallel", Variants: []string{"standard"}, TotalCount: 1000, SuccessCount: 900, FlakeCount: 10},
+	}
+	sampleStatus := func() map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus {
+		return map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+			serialTest:   {TestName: "test 1", TestSuite: "serial", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 50},
+			parallelTest: {TestName: "test 4", TestSuite: "parallel", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 90, FlakeCount: 1},
+		}
+	}
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+
+	generator := defaultComponentReportGenerator
+	report := generator.generateComponentTestReport(baseStatus, sampleStatus(), []apitype.TestRegression{})
+	assert.Equal(t, 1, len(report.Rows))
+	assert.Equal(t, 1, len(report.Rows[0].Columns), "suites should share a column unless grouped by suite")
+	assert.Equal(t, apitype.ExtremeRegression, report.Rows[0].Columns[0].Status)
+
+	generator.GroupBy = "cloud,arch,network,suite"
+	report = generator.generateComponentTestReport(baseStatus, sampleStatus(), []apitype.TestRegression{})
+	assert.Equal(t, 1, len(report.Rows))
+	columns := report.Rows[0].Columns
+	assert.Equal(t, 2, len(columns))
+	assert.Equal(t, "parallel", columns[0].Suite)
+	assert.Equal(t, apitype.NotSignificant, columns[0].Status)
+	assert.Equal(t, "serial", columns[1].Suite)
+	assert.Equal(t, apitype.ExtremeRegression, columns[1].Status)
+	assert.Equal(t, 1, len(columns[1].RegressedTests))
+	assert.Equal(t, "serial", columns[1].RegressedTests[0].Suite)
+}
+
+func Test_componentReportGenerator_emptyGroupBy(t *testing.T) {
+	awsTest := apitype.ComponentTestIdentification{
+		TestID:       "1",
+		Platform:     "aws",
+		Arch:         "amd64",
+		Network:      "ovn",
+		Upgrade:      "upgrade-micro",
+		FlatVariants: "standard",
+	}
+	gcpTest := awsTest
+	gcpTest.Platform = "gcp"
+	gcpTest.Arch = "arm64"
+	baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		awsTest: {TestName: "test 1", Variants: []string{"standard"}, TotalCount: 1000, SuccessCount: 900, FlakeCount: 10},
+		gcpTest: {TestName: "test 1", Variants: []string{"standard"}, TotalCount: 1000, SuccessCount: 900, FlakeCount: 10},
+	}
+	sampleStatus := func() map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus {
+		return map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+			awsTest: {TestName: "test 1", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 50},
+			gcpTest: {TestName: "test 1", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 90, FlakeCount: 1},
+		}
+	}
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+
+	generator := defaultComponentReportGenerator
+	report := generator.generateComponentTestReport(baseStatus, sampleStatus(), []apitype.TestRegression{})
+	assert.Equal(t, 1, len(report.Rows))
+	assert.Equal(t, 2, len(report.Rows[0].Columns), "each cloud and arch should have a column")
+
+	generator.GroupBy = ""
+	report = generator.generateComponentTestReport(baseStatus, sampleStatus(), []apitype.TestRegression{})
+	assert.Equal(t, 1, len(report.Rows))
+	columns := report.Rows[0].Columns
+	assert.Equal(t, 1, len(columns), "without grouping there should be a single aggregate column")
+	assert.Equal(t, apitype.ComponentReportColumnIdentification{}, columns[0].ComponentReportColumnIdentification)
+	assert.Equal(t, apitype.ExtremeRegression, columns[0].Status)
+	assert.Equal(t, 1, len(columns[0].RegressedTests))
+	assert.Equal(t, "aws", columns[0].RegressedTests[0].Platform, "regressed tests keep their variants")
+}
+
+func Test_componentReportGenerator_archCrossCompare(t *testing.T) {
+	amd64Test1 := apitype.ComponentTestIdentification{TestID: "1", Platform: "aws", Arch: "amd64", Network: "ovn", Upgrade: "upgrade-micro", FlatVariants: "standard"}
+	amd64Test2 := amd64Test1
+	amd64Test2.TestID = "2"
+	arm64Test1 := amd64Test1
+	arm64Test1.Arch = "arm64"
+	arm64Test2 := amd64Test2
+	arm64Test2.Arch = "arm64"
+	// the base only has amd64 results, the sample only arm64
+	baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		amd64Test1: {TestName: "test 1", TotalCount: 1000, SuccessCount: 1000},
+		amd64Test2: {TestName: "test 2", TotalCount: 1000, SuccessCount: 1000},
+	}
+	sampleStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		arm64Test1: {TestName: "test 1", TotalCount: 100, SuccessCount: 50},
+		arm64Test2: {TestName: "test 2", TotalCount: 100, SuccessCount: 100},
+	}
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+
+	generator := defaultComponentReportGenerator
+	generator.Arch = "arm64"
+	generator.BaseArch = "amd64"
+	report := generator.generateComponentTestReport(baseStatus, sampleStatus, []apitype.TestRegression{})
+	assert.Equal(t, 2, len(report.Rows))
+	statuses := map[string]apitype.ComponentReportStatus{}
+	for _, row := range report.Rows {
+		assert.Equal(t, 1, len(row.Columns), "amd64 base and arm64 sample should share a column")
+		column := row.Columns[0]
+		assert.Equal(t, apitype.ComponentReportColumnIdentification{Platform: "aws", Arch: "arm64", BaseArch: "amd64", Network: "ovn"}, column.ComponentReportColumnIdentification)
+		statuses[row.Component] = column.Status
+		for _, regressedTest := range column.RegressedTests {
+			assert.Equal(t, "arm64", regressedTest.Arch, "regressed tests should reflect the sample arch")
+		}
+	}
+	assert.Equal(t, apitype.ExtremeRegression, statuses["component 1"])
+	assert.Equal(t, apitype.NotSignificant, statuses["component 2"])
+
+	// without grouping by arch the columns carry neither side
+	generator.GroupBy = "cloud,network"
+	report = generator.generateComponentTestReport(baseStatus, sampleStatus, []apitype.TestRegression{})
+	for _, row := range report.Rows {
+		assert.Equal(t, apitype.ComponentReportColumnIdentification{Platform: "aws", Network: "ovn"}, row.Columns[0].ComponentReportColumnIdentification)
+	}
+}
+
+func Test_componentReportGenerator_archCrossCompareQuery(t *testing.T) {
+	c := &componentReportGenerator{
+		client: &bqcachedclient.Client{Dataset: "ci_analysis_us"},
+		ComponentReportRequestVariantOptions: apitype.ComponentReportRequestVariantOptions{
+			Arch:     "arm64",
+			BaseArch: "amd64",
+		},
+	}
+	queryString, _, params := c.getCommonTestStatusQuery()
+	assert.NotContains(t, queryString, "arch = @Arch", "base and sample should filter on their own arch")
+	assert.Contains(t, params, bigquery.QueryParameter{Name: "Arch", Value: "arm64"})
+	assert.Contains(t, params, bigquery.QueryParameter{Name: "BaseArch", Value: "amd64"})
+	sample := sampleQueryGenerator{commonQuery: queryString, ComponentReportGenerator: c}
+	assert.Contains(t, sample.queryString(), "arch = @Arch", "sample should filter on the sample arch")
+
+	c.BaseArch = ""
+	queryString, _, params = c.getCommonTestStatusQuery()
+	assert.Contains(t, queryString, "arch = @Arch")
+	sample = sampleQueryGenerator{commonQuery: queryString, ComponentReportGenerator: c}
+	assert.Equal(t, 1, strings.Count(sample.queryString(), "arch = @Arch"))
+	assert.NotContains(t, params, bigquery.QueryParameter{Name: "BaseArch", Value: ""})
+}
+
+func Test_componentReportGenerator_variantAliases(t *testing.T) {
+	sdnTest := apitype.ComponentTestIdentification{
+		TestID:       "1",
+		Platform:     "aws",
+		Arch:         "amd64",
+		Network:      "sdn",
+		Upgrade:      "upgrade-micro",
+		FlatVariants: "standard",
+	}
+	ovnTest := sdnTest
+	ovnTest.Network = "ovn"
+	// the basis only ran on sdn, the sample only on ovn after the rename
+	baseStatus := func() map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus {
+		return map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+			sdnTest: {TestName: "test 1", Variants: []string{"standard"}, TotalCount: 1000, SuccessCount: 900, FlakeCount: 10},
+		}
+	}
+	sampleStatus := func() map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus {
+		return map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+			ovnTest: {TestName: "test 1", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 50},
+		}
+	}
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+
+	generator := defaultComponentReportGenerator
+	report := generator.generateComponentTestReport(baseStatus(), sampleStatus(), []apitype.TestRegression{})
+	assert.Equal(t, 1, len(report.Rows))
+	assert.Equal(t, 2, len(report.Rows[0].Columns), "without aliases sdn and ovn should be apart")
+
+	generator.VariantAliases = map[string]map[string]string{"network": {"sdn": "ovn"}}
+	report = generator.generateComponentTestReport(baseStatus(), sampleStatus(), []apitype.TestRegression{})
+	assert.Equal(t, 1, len(report.Rows))
+	columns := report.Rows[0].Columns
+	assert.Equal(t, 1, len(columns))
+	assert.Equal(t, "ovn", columns[0].Network)
+	assert.Equal(t, apitype.ExtremeRegression, columns[0].Status, "sdn basis should be compared with the ovn sample")
+
+	// stats of both names within the same window are merged
+	merged := aliasVariants(generator.VariantAliases, map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		sdnTest: {TestName: "test 1", TotalCount: 10, SuccessCount: 8, FlakeCount: 1},
+		ovnTest: {TestName: "test 1", TotalCount: 20, SuccessCount: 19},
+	})
+	assert.Equal(t, 1, len(merged))
+	assert.Equal(t, 30, merged[ovnTest].TotalCount)
+	assert.Equal(t, 27, merged[ovnTest].SuccessCount)
+	assert.Equal(t, 1, merged[ovnTest].FlakeCount)
+}
+
+func Test_twoProportionZTest(t *testing.T) {
+	tests := []struct {
+		name           string
+		sampleTotal    int
+		samplePass     int
+		baseTotal      int
+		basePass       int
+		expectedZ      float64
+		expectedPValue float64
+		undefined      bool
+	}{
+		{
+			name:           "regression that is not significant",
+			sampleTotal:    100,
+			samplePass:     50,
+			baseTotal:      100,
+			basePass:       60,
+			expectedZ:      -1.421338,
+			expectedPValue: 0.155218,
+		},
+		{
+			name:           "significant improvement",
+			sampleTotal:    200,
+			samplePass:     190,
+			baseTotal:      200,
+			basePass:       170,
+			expectedZ:      3.333333,
+			expectedPValue: 0.000858,
+		},
+		{
+			name:        "empty sample",
+			sampleTotal: 0,
+			baseTotal:   100,
+			basePass:    90,
+			undefined:   true,
+		},
+		{
+			name:        "everything passed",
+			sampleTotal: 100,
+			samplePass:  100,
+			baseTotal:   100,
+			basePass:    100,
+			undefined:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z, p := twoProportionZTest(tt.sampleTotal, tt.samplePass, tt.baseTotal, tt.basePass)
+			if tt.undefined {
+				assert.Nil(t, z)
+				assert.Nil(t, p)
+				return
+			}
+			assert.InDelta(t, tt.expectedZ, *z, 0.000001)
+			assert.InDelta(t, tt.expectedPValue, *p, 0.000001)
+		})
+	}
+}
+
+func Test_componentReportGenerator_includeZTest(t *testing.T) {
+	testIdentification := apitype.ComponentTestIdentification{
+		TestID:       "1",
+		Platform:     "aws",
+		Arch:         "amd64",
+		Network:      "ovn",
+		Upgrade:      "upgrade-micro",
+		FlatVariants: "standard",
+	}
+	baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		testIdentification: {TestName: "test 1", Variants: []string{"standard"}, TotalCount: 1000, SuccessCount: 900, FlakeCount: 10},
+	}
+	sampleStatus := func() map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus {
+		return map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+			testIdentification: {TestName: "test 1", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 50},
+		}
+	}
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+
+	generator := defaultComponentReportGenerator
+	report := generator.generateComponentTestReport(baseStatus, sampleStatus(), []apitype.TestRegression{})
+	regressedTests := report.Rows[0].Columns[0].RegressedTests
+	assert.Equal(t, 1, len(regressedTests))
+	assert.Nil(t, regressedTests[0].ZScore, "the z-test should only be included on request")
+	assert.Nil(t, regressedTests[0].ZPValue)
+
+	generator.IncludeZTest = true
+	report = generator.generateComponentTestReport(baseStatus, sampleStatus(), []apitype.TestRegression{})
+	column := report.Rows[0].Columns[0]
+	assert.Equal(t, apitype.ExtremeRegression, column.Status, "the z-test should not change the status")
+	assert.Equal(t, 1, len(column.RegressedTests))
+	assert.InDelta(t, -11.729529, *column.RegressedTests[0].ZScore, 0.000001)
+	assert.Less(t, *column.RegressedTests[0].ZPValue, 0.05)
+}
+
+func Test_componentReportGenerator_sortColumnsBySeverity(t *testing.T) {
+	testOn := func(platform string) apitype.ComponentTestIdentification {
+		return apitype.ComponentTestIdentification{
+			TestID:       "1",
+			Platform:     platform,
+			Arch:         "amd64",
+			Network:      "ovn",
+			Upgrade:      "upgrade-micro",
+			FlatVariants: "standard",
+		}
+	}
+	baseStats := apitype.ComponentTestStatus{TestName: "test 1", Variants: []string{"standard"}, TotalCount: 1000, SuccessCount: 900, FlakeCount: 10}
+	baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		testOn("aws"):   baseStats,
+		testOn("azure"): baseStats,
+		testOn("gcp"):   baseStats,
+		testOn("metal"): baseStats,
+	}
+	sampleStatus := func() map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus {
+		return map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+			testOn("aws"):   {TestName: "test 1", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 90, FlakeCount: 1},
+			testOn("azure"): {TestName: "test 1", Variants: []string{"standard"}, TotalCount: 20, SuccessCount: 10},
+			testOn("gcp"):   {TestName: "test 1", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 80},
+			testOn("metal"): {TestName: "test 1", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 50},
+		}
+	}
+	platforms := func(report apitype.ComponentReport) []string {
+		result := []string{}
+		for _, column := range report.Rows[0].Columns {
+			result = append(result, column.Platform)
+		}
+		return result
+	}
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+
+	generator := defaultComponentReportGenerator
+	report := generator.generateComponentTestReport(baseStatus, sampleStatus(), []apitype.TestRegression{})
+	assert.Equal(t, 1, len(report.Rows))
+	assert.Equal(t, []string{"aws", "azure", "gcp", "metal"}, platforms(report), "columns should be ordered by variant by default")
+
+	generator.SortColumnsBy = apitype.ColumnSortSeverity
+	report = generator.generateComponentTestReport(baseStatus, sampleStatus(), []apitype.TestRegression{})
+	assert.Equal(t, 1, len(report.Rows))
+	// metal and azure are both extreme regressions, metal has far more runs so is the more significant
+	assert.Equal(t, []string{"metal", "azure", "gcp", "aws"}, platforms(report))
+	statuses := []apitype.ComponentReportStatus{}
+	for _, column := range report.Rows[0].Columns {
+		statuses = append(statuses, column.Status)
+	}
+	assert.Equal(t, []apitype.ComponentReportStatus{apitype.ExtremeRegression, apitype.ExtremeRegression, apitype.SignificantRegression, apitype.NotSignificant}, statuses)
+}
+
+func TestFlakeToggleImpact(t *testing.T) {
+	testOn := func(testID, platform string) apitype.ComponentTestIdentification {
+		return apitype.ComponentTestIdentification{
+			TestID:       testID,
+			Platform:     platform,
+			Arch:         "amd64",
+			Network:      "ovn",
+			Upgrade:      "upgrade-micro",
+			FlatVariants: "standard",
+		}
+	}
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+	stableBase := apitype.ComponentTestStatus{TestName: "test 1", TotalCount: 1000, SuccessCount: 950}
+	unflakedBase := apitype.ComponentTestStatus{TestName: "test 2", TotalCount: 1000, SuccessCount: 950}
+	status := apitype.ComponentReportTestStatus{
+		BaseStatus: map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+			// flakes only in the sample, a regression once they count as failures
+			testOn("1", "aws"): stableBase,
+			testOn("1", "gcp"): stableBase,
+			// no flakes, unaffected by the policy
+			testOn("2", "aws"): unflakedBase,
+			// flaky on both sides equally, unaffected by the policy
+			testOn("3", "aws"): {TestName: "test 3", TotalCount: 1000, SuccessCount: 700, FlakeCount: 250},
+			// no sample
+			testOn("4", "aws"): stableBase,
+		},
+		SampleStatus: map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+			testOn("1", "aws"): {TestName: "test 1", TotalCount: 100, SuccessCount: 70, FlakeCount: 25},
+			testOn("1", "gcp"): {TestName: "test 1", TotalCount: 100, SuccessCount: 60, FlakeCount: 35},
+			testOn("2", "aws"): {TestName: "test 2", TotalCount: 100, SuccessCount: 95},
+			testOn("3", "aws"): {TestName: "test 3", TotalCount: 100, SuccessCount: 70, FlakeCount: 25},
+		},
+	}
+
+	changed, examples := FlakeToggleImpact(status, defaultAdvancedOption)
+	assert.Equal(t, 2, changed)
+	assert.Equal(t, []apitype.ComponentTestIdentification{testOn("1", "aws"), testOn("1", "gcp")}, examples)
+
+	// 30 failures once flakes count is below the minimum of the component, 40 are not
+	options := defaultAdvancedOption
+	options.MinimumFailureByComponent = map[string]int{"component 1": 35}
+	changed, examples = FlakeToggleImpact(status, options)
+	assert.Equal(t, 1, changed)
+	assert.Equal(t, []apitype.ComponentTestIdentification{testOn("1", "gcp")}, examples)
+
+	// a drop from 95% to 87% over 30 runs once flakes count, p is about 0.07
+	gcp := apitype.ComponentTestIdentification{TestID: "2", Platform: "gcp", Arch: "amd64", Network: "ovn", Upgrade: "upgrade-micro", FlatVariants: "standard"}
+	status = apitype.ComponentReportTestStatus{
+		BaseStatus: map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+			gcp: unflakedBase,
+		},
+		SampleStatus: map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+			gcp: {TestName: "test 2", TotalCount: 30, SuccessCount: 26, FlakeCount: 4},
+		},
+	}
+	changed, _ = FlakeToggleImpact(status, defaultAdvancedOption)
+	assert.Equal(t, 0, changed)
+	options = defaultAdvancedOption
+	options.ConfidenceOverrides = []apitype.VariantConfidenceOverride{{VariantName: "platform", VariantValue: "gcp", Confidence: 90}}
+	changed, _ = FlakeToggleImpact(status, options)
+	assert.Equal(t, 1, changed)
+}
+
+func TestResolveGroupingPreset(t *testing.T) {
+	presets := map[string][]string{
+		"by-platform":  {"cloud"},
+		"by-installer": {"cloud", "arch", "network", "variants"},
+		"broken":       {"cloud", "installer"},
+	}
+	tests := []struct {
+		name            string
+		preset          string
+		expectedGroupBy string
+		expectedErr     bool
+	}{
+		{
+			name:            "single grouping",
+			preset:          "by-platform",
+			expectedGroupBy: "cloud",
+		},
+		{
+			name:            "several groupings",
+			preset:          "by-installer",
+			expectedGroupBy: "cloud,arch,network,variants",
+		},
+		{
+			name:        "unknown preset",
+			preset:      "by-team",
+			expectedErr: true,
+		},
+		{
+			name:        "unknown grouping in preset",
+			preset:      "broken",
+			expectedErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groupBy, err := ResolveGroupingPreset(presets, tt.preset)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedGroupBy, groupBy)
+		})
+	}
+}
+
+func Test_componentReportGenerator_multipleComparisonCorrection(t *testing.T) {
+	testOn := func(platform string) apitype.ComponentTestIdentification {
+		return apitype.ComponentTestIdentification{
+			TestID:       "1",
+			Platform:     platform,
+			Arch:         "amd64",
+			Network:      "ovn",
+			Upgrade:      "upgrade-micro",
+			FlatVariants: "standard",
+		}
+	}
+	sampleSuccesses := map[string]int{
+		"aws":     84, // borderline regression, p=0.024
+		"azure":   78, // strong regression, p=0.0002
+		"gcp":     93,
+		"metal":   94,
+		"vsphere": 95,
+	}
+	baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{}
+	sampleStatus := func() map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus {
+		status := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{}
+		for platform, successes := range sampleSuccesses {
+			status[testOn(platform)] = apitype.ComponentTestStatus{TestName: "test 1", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: successes}
+		}
+		return status
+	}
+	for platform := range sampleSuccesses {
+		baseStatus[testOn(platform)] = apitype.ComponentTestStatus{TestName: "test 1", Variants: []string{"standard"}, TotalCount: 1000, SuccessCount: 910}
+	}
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+
+	tests := []struct {
+		name             string
+		correction       apitype.MultipleComparisonCorrection
+		expectedStatuses map[string]apitype.ComponentReportStatus
+	}{
+		{
+			name: "no correction",
+			expectedStatuses: map[string]apitype.ComponentReportStatus{
+				"aws":   apitype.SignificantRegression,
+				"azure": apitype.SignificantRegression,
+			},
+		},
+		{
+			name:       "bonferroni clears the borderline regression",
+			correction: apitype.CorrectionBonferroni,
+			expectedStatuses: map[string]apitype.ComponentReportStatus{
+				"aws":   apitype.NotSignificant,
+				"azure": apitype.SignificantRegression,
+			},
+		},
+		{
+			name:       "benjamini-hochberg clears the borderline regression",
+			correction: apitype.CorrectionBenjaminiHochberg,
+			expectedStatuses: map[string]apitype.ComponentReportStatus{
+				"aws":   apitype.NotSignificant,
+				"azure": apitype.SignificantRegression,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := defaultComponentReportGenerator
+			generator.MultipleComparisonCorrection = tt.correction
+			report := generator.generateComponentTestReport(baseStatus, sampleStatus(), []apitype.TestRegression{})
+			assert.Equal(t, 1, len(report.Rows))
+			statuses := map[string]apitype.ComponentReportStatus{}
+			for _, column := range report.Rows[0].Columns {
+				if _, ok := tt.expectedStatuses[column.Platform]; ok {
+					statuses[column.Platform] = column.Status
+				}
+			}
+			assert.Equal(t, tt.expectedStatuses, statuses)
+			assert.Nil(t, generator.significanceLevel, "the corrected level should not outlive the report")
+		})
+	}
+}
+
+func Test_componentReportGenerator_correctedSignificanceLevel(t *testing.T) {
+	testOn := func(platform string) apitype.ComponentTestIdentification {
+		return apitype.ComponentTestIdentification{TestID: "1", Platform: platform, Arch: "amd64", Network: "ovn", Upgrade: "upgrade-micro", FlatVariants: "standard"}
+	}
+	baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		testOn("aws"):   {TestName: "test 1", TotalCount: 5000, SuccessCount: 5000},
+		testOn("azure"): {TestName: "test 1", TotalCount: 100, SuccessCount: 90},
+		testOn("gcp"):   {TestName: "test 1", TotalCount: 1000, SuccessCount: 910},
+	}
+	sampleStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		// so clear a regression that its p-value is exactly zero
+		testOn("aws"): {TestName: "test 1", TotalCount: 5000, SuccessCount: 0},
+		// identical counts skip the significance test
+		testOn("azure"): {TestName: "test 1", TotalCount: 100, SuccessCount: 90},
+		testOn("gcp"):   {TestName: "test 1", TotalCount: 100, SuccessCount: 84},
+	}
+	generator := defaultComponentReportGenerator
+	generator.MultipleComparisonCorrection = apitype.CorrectionBonferroni
+	assert.InDelta(t, 0.05/2, generator.correctedSignificanceLevel(baseStatus, sampleStatus), 1e-12,
+		"the zero p-value should be counted and the identical counts left out")
+}
+
+func Test_correctSignificanceLevel(t *testing.T) {
+	pValues := []float64{0.05, 0.01, 0.04, 0.02, 0.03}
+	assert.InDelta(t, 0.05, correctSignificanceLevel("", 0.05, pValues), 0.000001)
+	assert.InDelta(t, 0.01, correctSignificanceLevel(apitype.CorrectionBonferroni, 0.05, pValues), 0.000001)
+	// every p-value is within its rank's threshold
+	assert.InDelta(t, 0.05, correctSignificanceLevel(apitype.CorrectionBenjaminiHochberg, 0.05, pValues), 0.000001)
+	// only the smallest is within its threshold
+	assert.InDelta(t, 0.001, correctSignificanceLevel(apitype.CorrectionBenjaminiHochberg, 0.05, []float64{0.5, 0.001, 0.4, 0.3}), 0.000001)
+	assert.Negative(t, correctSignificanceLevel(apitype.CorrectionBenjaminiHochberg, 0.05, []float64{0.5, 0.4}), "nothing should be significant")
+	assert.InDelta(t, 0.05, correctSignificanceLevel(apitype.CorrectionBonferroni, 0.05, nil), 0.000001)
+}
+
+func Test_componentReportGenerator_baseSampleGap(t *testing.T) {
+	testIdentification := apitype.ComponentTestIdentification{
+		TestID:       "1",
+		Platform:     "aws",
+		Arch:         "amd64",
+		Network:      "ovn",
+		Upgrade:      "upgrade-micro",
+		FlatVariants: "standard",
+	}
+	baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		testIdentification: {TestName: "test 1", Variants: []string{"standard"}, TotalCount: 1000, SuccessCount: 900, FlakeCount: 10},
+	}
+	sampleStatus := func() map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus {
+		return map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+			testIdentification: {TestName: "test 1", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 50},
+		}
+	}
+	sampleStart := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+
+	tests := []struct {
+		name        string
+		baseEnd     time.Time
+		maxGap      time.Duration
+		expectedGap time.Duration
+	}{
+		{
+			name:    "large gap is annotated",
+			baseEnd: sampleStart.Add(-90 * day),
+			maxGap:  30 * day,
+			// the cell shows the full gap, not the excess over the maximum
+			expectedGap: 90 * day,
+		},
+		{
+			name:    "small gap is not annotated",
+			baseEnd: sampleStart.Add(-7 * day),
+			maxGap:  30 * day,
+		},
+		{
+			name:    "large gap without a maximum is not annotated",
+			baseEnd: sampleStart.Add(-90 * day),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := defaultComponentReportGenerator
+			generator.BaseRelease = apitype.ComponentReportRequestReleaseOptions{Release: "4.15", Start: tt.baseEnd.Add(-30 * day), End: tt.baseEnd}
+			generator.SampleRelease = apitype.ComponentReportRequestReleaseOptions{Release: "4.16", Start: sampleStart, End: sampleStart.Add(7 * day)}
+			generator.MaxBaseSampleGap = tt.maxGap
+			report := generator.generateComponentTestReport(baseStatus, sampleStatus(), []apitype.TestRegression{})
+			regressedTests := report.Rows[0].Columns[0].RegressedTests
+			assert.Equal(t, 1, len(regressedTests))
+			assert.Equal(t, tt.expectedGap, regressedTests[0].BaseSampleGap)
+		})
+	}
+}
+
+func TestMinimumDetectableEffect(t *testing.T) {
+	base := apitype.ComponentReportTestDetailsTestStats{SuccessCount: 900, FailureCount: 100}
+	tests := []struct {
+		name        string
+		base        apitype.ComponentReportTestDetailsTestStats
+		sampleTotal int
+		expected    float64
+	}{
+		{
+			name:        "tiny sample can only detect a huge drop",
+			base:        base,
+			sampleTotal: 5,
+			expected:    0.5,
+		},
+		{
+			name:        "small sample",
+			base:        base,
+			sampleTotal: 10,
+			expected:    0.3,
+		},
+		{
+			name:        "medium sample",
+			base:        base,
+			sampleTotal: 100,
+			expected:    0.07,
+		},
+		{
+			name:        "large sample",
+			base:        base,
+			sampleTotal: 1000,
+			expected:    0.025,
+		},
+		{
+			name:        "no sample",
+			base:        base,
+			sampleTotal: 0,
+			expected:    1,
+		},
+		{
+			name:        "no basis",
+			sampleTotal: 100,
+			expected:    1,
+		},
+		{
+			name:        "nothing short of always failing is detectable",
+			base:        apitype.ComponentReportTestDetailsTestStats{SuccessCount: 1, FailureCount: 1},
+			sampleTotal: 2,
+			expected:    0.5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sample := apitype.ComponentReportTestDetailsTestStats{SuccessCount: tt.sampleTotal}
+			assert.InDelta(t, tt.expected, MinimumDetectableEffect(tt.base, sample, 95), 0.000001)
+		})
+	}
+}
+
+func Test_componentReportGenerator_includeMinimumDetectableEffect(t *testing.T) {
+	prowJob := "periodic-ci-openshift-release-master-ci-4.15-e2e-aws-ovn"
+	rows := func(total, success int) map[string][]apitype.ComponentJobRunTestStatusRow {
+		return map[string][]apitype.ComponentJobRunTestStatusRow{
+			prowJob: {{ProwJob: prowJob, TotalCount: total, SuccessCount: success}},
+		}
+	}
+
+	generator := testDetailsGenerator
+	report := generator.generateComponentTestDetailsReport(rows(1000, 900), rows(100, 100))
+	assert.Nil(t, report.MinimumDetectableEffect, "the minimum detectable effect should only be included on request")
+
+	generator.IncludeMinimumDetectableEffect = true
+	report = generator.generateComponentTestDetailsReport(rows(1000, 900), rows(100, 100))
+	assert.NotNil(t, report.MinimumDetectableEffect)
+	assert.InDelta(t, 0.07, *report.MinimumDetectableEffect, 0.000001)
+}
+
+func Test_componentReportGenerator_suiteMigration(t *testing.T) {
+	migratedTest := apitype.ComponentTestIdentification{
+		TestID:       "1",
+		Platform:     "aws",
+		Arch:         "amd64",
+		Network:      "ovn",
+		Upgrade:      "upgrade-micro",
+		FlatVariants: "standard",
+	}
+	steadyTest := migratedTest
+	steadyTest.TestID = "4"
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+
+	t.Run("merged stats are annotated", func(t *testing.T) {
+		baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+			migratedTest: {TestName: "test 1", TestSuite: "serial", Variants: []string{"standard"}, TotalCount: 1000, SuccessCount: 900, SuiteCount: 1},
+			steadyTest:   {TestName: "test 4", TestSuite: "serial", Variants: []string{"standard"}, TotalCount: 1000, SuccessCount: 900, SuiteCount: 1},
+		}
+		sampleStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+			// moved from serial to parallel part way through the sample window
+			migratedTest: {TestName: "test 1", TestSuite: "parallel", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 50, SuiteCount: 2},
+			steadyTest:   {TestName: "test 4", TestSuite: "serial", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 50, SuiteCount: 1},
+		}
+
+		generator := defaultComponentReportGenerator
+		report := generator.generateComponentTestReport(baseStatus, sampleStatus, []apitype.TestRegression{})
+		regressedTests := report.Rows[0].Columns[0].RegressedTests
+		assert.Equal(t, 2, len(regressedTests))
+		migrations := map[string]bool{}
+		for _, regressedTest := range regressedTests {
+			migrations[regressedTest.TestID] = regressedTest.SuiteMigration
+		}
+		assert.Equal(t, map[string]bool{"1": true, "4": false}, migrations)
+	})
+
+	t.Run("separate stats are compared by suite", func(t *testing.T) {
+		serial := migratedTest
+		serial.TestSuite = "serial"
+		parallel := migratedTest
+		parallel.TestSuite = "parallel"
+		baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+			serial: {TestName: "test 1", TestSuite: "serial", Variants: []string{"standard"}, TotalCount: 1000, SuccessCount: 900, SuiteCount: 1},
+		}
+		sampleStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+			serial:   {TestName: "test 1", TestSuite: "serial", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 50, SuiteCount: 1},
+			parallel: {TestName: "test 1", TestSuite: "parallel", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 100, SuiteCount: 1},
+		}
+
+		generator := defaultComponentReportGenerator
+		generator.SeparateMigratedSuites = true
+		report := generator.generateComponentTestReport(baseStatus, sampleStatus, []apitype.TestRegression{})
+		column := report.Rows[0].Columns[0]
+		// the passing parallel runs have no basis, so they can not hide the serial regression
+		assert.Equal(t, apitype.ExtremeRegression, column.Status)
+		assert.Equal(t, 1, len(column.RegressedTests))
+		assert.Equal(t, "serial", column.RegressedTests[0].TestSuite)
+		assert.True(t, column.RegressedTests[0].SuiteMigration)
+	})
+}
+
+func Test_componentReportGenerator_jobRunSuiteMigration(t *testing.T) {
+	prowJob := "periodic-ci-openshift-release-master-ci-4.15-e2e-aws-ovn"
+	run := func(suite string) apitype.ComponentJobRunTestStatusRow {
+		return apitype.ComponentJobRunTestStatusRow{ProwJob: prowJob, TestSuite: suite, TotalCount: 1, SuccessCount: 1}
+	}
+
+	generator := testDetailsGenerator
+	report := generator.generateComponentTestDetailsReport(
+		map[string][]apitype.ComponentJobRunTestStatusRow{prowJob: {run("serial"), run("serial")}},
+		map[string][]apitype.ComponentJobRunTestStatusRow{prowJob: {run("serial"), run("parallel")}})
+	assert.True(t, report.SuiteMigration)
+
+	report = generator.generateComponentTestDetailsReport(
+		map[string][]apitype.ComponentJobRunTestStatusRow{prowJob: {run("serial"), run("serial")}},
+		map[string][]apitype.ComponentJobRunTestStatusRow{prowJob: {run("serial")}})
+	assert.False(t, report.SuiteMigration)
+}
+
+func Test_componentReportGenerator_minimumImprovementDelta(t *testing.T) {
+	// over 100000 runs each, half a point of improvement from 90% is significant
+	tests := []struct {
+		name                    string
+		sampleSuccess           int
+		minimumImprovementDelta float64
+		expectedStatus          apitype.ComponentReportStatus
+		expectedFactor          apitype.DecidingFactor
+	}{
+		{
+			name:           "disabled",
+			sampleSuccess:  90500,
+			expectedStatus: apitype.SignificantImprovement,
+			expectedFactor: apitype.DecidingFactorFisher,
+		},
+		{
+			name:                    "tiny improvement below delta",
+			sampleSuccess:           90500,
+			minimumImprovementDelta: 1,
+			expectedStatus:          apitype.NotSignificant,
+			expectedFactor:          apitype.DecidingFactorMinimumImprovement,
+		},
+		{
+			name:                    "improvement above delta",
+			sampleSuccess:           95000,
+			minimumImprovementDelta: 1,
+			expectedStatus:          apitype.SignificantImprovement,
+			expectedFactor:          apitype.DecidingFactorFisher,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &componentReportGenerator{ComponentReportRequestAdvancedOptions: defaultAdvancedOption}
+			c.MinimumImprovementDelta = tt.minimumImprovementDelta
+
+			status, _, factor := c.assessComponentStatus(100000, tt.sampleSuccess, 0, 100000, 90000, 0, nil, 0)
+			assert.Equal(t, tt.expectedStatus, status)
+			assert.Equal(t, tt.expectedFactor, factor)
+		})
+	}
+}
+
+func Test_componentReportGenerator_warningMargin(t *testing.T) {
+	// against a basis of 97/100, a sample of 91/100 has a fisher exact p-value of 0.0669
+	tests := []struct {
+		name           string
+		sampleSuccess  int
+		baseSuccess    int
+		warningMargin  int
+		expectedStatus apitype.ComponentReportStatus
+	}{
+		{
+			name:           "disabled",
+			sampleSuccess:  91,
+			baseSuccess:    97,
+			warningMargin:  0,
+			expectedStatus: apitype.NotSignificant,
+		},
+		{
+			name:           "just inside margin",
+			sampleSuccess:  91,
+			baseSuccess:    97,
+			warningMargin:  2,
+			expectedStatus: apitype.RegressionWarning,
+		},
+		{
+			name:           "just outside margin",
+			sampleSuccess:  91,
+			baseSuccess:    97,
+			warningMargin:  1,
+			expectedStatus: apitype.NotSignificant,
+		},
+		{
+			name:           "significant regression is unaffected",
+			sampleSuccess:  90,
+			baseSuccess:    97,
+			warningMargin:  5,
+			expectedStatus: apitype.SignificantRegression,
+		},
+		{
+			name:           "drop within pity factor does not warn",
+			sampleSuccess:  92,
+			baseSuccess:    97,
+			warningMargin:  50,
+			expectedStatus: apitype.NotSignificant,
+		},
+		{
+			name:           "improvement does not warn",
+			sampleSuccess:  97,
+			baseSuccess:    91,
+			warningMargin:  50,
+			expectedStatus: apitype.NotSignificant,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &componentReportGenerator{ComponentReportRequestAdvancedOptions: defaultAdvancedOption}
+			c.WarningMargin = tt.warningMargin
+
+			status, _, _ := c.assessComponentStatus(100, tt.sampleSuccess, 0, 100, tt.baseSuccess, 0, nil, 0)
+			assert.Equal(t, tt.expectedStatus, status)
+		})
+	}
+}
+
+func Test_getNewCellStatus_regressionWarning(t *testing.T) {
+	testSummary := apitype.ComponentReportTestSummary{Status: apitype.RegressionWarning}
+	cell := getNewCellStatus(testSummary, nil, nil, nil)
+	assert.Equal(t, apitype.RegressionWarning, cell.status)
+	assert.Empty(t, cell.regressedTests)
+	assert.Empty(t, cell.triagedIncidents)
+}
+
+func Test_componentReportStatusSeverityOrder(t *testing.T) {
+	assert.True(t, apitype.SignificantRegression.WorseThan(apitype.RegressionWarning))
+	assert.True(t, apitype.SignificantTriagedRegression.WorseThan(apitype.RegressionWarning))
+	assert.True(t, apitype.RegressionWarning.WorseThan(apitype.NotSignificant))
+	assert.True(t, apitype.RegressionWarning.WorseThan(apitype.MissingSample))
+	assert.False(t, apitype.NotSignificant.WorseThan(apitype.RegressionWarning))
+	assert.Equal(t, apitype.RegressionWarning, combineCellStatus(apitype.NotSignificant, apitype.RegressionWarning))
+	assert.Equal(t, apitype.SignificantRegression, combineCellStatus(apitype.SignificantRegression, apitype.RegressionWarning))
+}
+
+func Test_componentReportGenerator_highFlakeRate(t *testing.T) {
+	// the basis of 100 runs passes 95, flakes 2 and fails 3
+	tests := []struct {
+		name           string
+		sampleSuccess  int
+		sampleFlake    int
+		flag           bool
+		expectedStatus apitype.ComponentReportStatus
+		expectedFactor apitype.DecidingFactor
+	}{
+		{
+			name:           "flaky but stable pass rate",
+			sampleSuccess:  75,
+			sampleFlake:    22,
+			flag:           true,
+			expectedStatus: apitype.HighFlakeRate,
+			expectedFactor: apitype.DecidingFactorFlakeRate,
+		},
+		{
+			name:           "disabled",
+			sampleSuccess:  75,
+			sampleFlake:    22,
+			expectedStatus: apitype.NotSignificant,
+			expectedFactor: apitype.DecidingFactorFisher,
+		},
+		{
+			name:           "flake rate increase within pity factor",
+			sampleSuccess:  92,
+			sampleFlake:    5,
+			flag:           true,
+			expectedStatus: apitype.NotSignificant,
+			expectedFactor: apitype.DecidingFactorFisher,
+		},
+		{
+			name:           "regression takes precedence",
+			sampleSuccess:  70,
+			sampleFlake:    20,
+			flag:           true,
+			expectedStatus: apitype.SignificantRegression,
+			expectedFactor: apitype.DecidingFactorFisher,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &componentReportGenerator{ComponentReportRequestAdvancedOptions: defaultAdvancedOption}
+			c.FlagHighFlakeRates = tt.flag
+
+			status, _, decidingFactor := c.assessComponentStatus(100, tt.sampleSuccess, tt.sampleFlake, 100, 95, 2, nil, 0)
+			assert.Equal(t, tt.expectedStatus, status)
+			assert.Equal(t, tt.expectedFactor, decidingFactor)
+		})
+	}
+
+	assert.True(t, apitype.RegressionWarning.WorseThan(apitype.HighFlakeRate))
+	assert.True(t, apitype.HighFlakeRate.WorseThan(apitype.MissingSample))
+	assert.True(t, apitype.SignificantTriagedRegression.WorseThan(apitype.HighFlakeRate))
+	assert.Equal(t, apitype.HighFlakeRate, combineCellStatus(apitype.NotSignificant, apitype.HighFlakeRate))
+}
+
+func Test_componentReportGenerator_includeFlakeRates(t *testing.T) {
+	flakyTest := apitype.ComponentTestIdentification{
+		TestID:       "1",
+		Platform:     "aws",
+		Arch:         "amd64",
+		Network:      "ovn",
+		Upgrade:      "upgrade-micro",
+		FlatVariants: "standard",
+	}
+	stableTest := flakyTest
+	stableTest.TestID = "4"
+	baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		flakyTest:  {TestName: "test 1", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 95, FlakeCount: 2},
+		stableTest: {TestName: "test 4", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 100},
+	}
+	sampleStatus := func() map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus {
+		return map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+			flakyTest:  {TestName: "test 1", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 75, FlakeCount: 22},
+			stableTest: {TestName: "test 4", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 100},
+		}
+	}
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+
+	generator := defaultComponentReportGenerator
+	report := generator.generateComponentTestReport(baseStatus, sampleStatus(), []apitype.TestRegression{})
+	column := report.Rows[0].Columns[0]
+	assert.Equal(t, apitype.NotSignificant, column.Status)
+	assert.Nil(t, column.SampleFlakeRate, "flake rates should only be included on request")
+	assert.Nil(t, column.BaseFlakeRate, "flake rates should only be included on request")
+
+	generator.IncludeFlakeRates = true
+	generator.FlagHighFlakeRates = true
+	report = generator.generateComponentTestReport(baseStatus, sampleStatus(), []apitype.TestRegression{})
+	assert.Equal(t, 1, len(report.Rows))
+	column = report.Rows[0].Columns[0]
+	assert.Equal(t, apitype.HighFlakeRate, column.Status)
+	if assert.NotNil(t, column.SampleFlakeRate) && assert.NotNil(t, column.BaseFlakeRate) {
+		assert.InDelta(t, 0.11, *column.SampleFlakeRate, 0.0001)
+		assert.InDelta(t, 0.01, *column.BaseFlakeRate, 0.0001)
+	}
+}
+
+func Test_componentReportGenerator_newImprovementsSince(t *testing.T) {
+	testIdentification := func(id string) apitype.ComponentTestIdentification {
+		return apitype.ComponentTestIdentification{
+			TestID:       id,
+			Platform:     "aws",
+			Arch:         "amd64",
+			Network:      "ovn",
+			Upgrade:      "upgrade-micro",
+			FlatVariants: "standard",
+		}
+	}
+	regression := func(release, id, platform string) apitype.TestRegression {
+		return apitype.TestRegression{
+			Release: release,
+			TestID:  id,
+			Variants: []apitype.ComponentReportVariant{
+				{Key: "Platform", Value: platform},
+				{Key: "Architecture", Value: "amd64"},
+				{Key: "Network", Value: "ovn"},
+				{Key: "Upgrade", Value: "upgrade-micro"},
+				{Key: "Variant", Value: "standard"},
+			},
+		}
+	}
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+	baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		testIdentification("1"): {TestName: "test 1", TotalCount: 1000, SuccessCount: 950},
+		testIdentification("2"): {TestName: "test 2", TotalCount: 1000, SuccessCount: 950},
+		testIdentification("3"): {TestName: "test 3", TotalCount: 1000, SuccessCount: 950},
+		testIdentification("4"): {TestName: "test 4", TotalCount: 1000, SuccessCount: 800},
+	}
+	sampleStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		// recovered
+		testIdentification("1"): {TestName: "test 1", TotalCount: 100, SuccessCount: 95},
+		// still regressed
+		testIdentification("2"): {TestName: "test 2", TotalCount: 100, SuccessCount: 50},
+		// never regressed
+		testIdentification("3"): {TestName: "test 3", TotalCount: 100, SuccessCount: 95},
+		// better than ever
+		testIdentification("4"): {TestName: "test 4", TotalCount: 100, SuccessCount: 96},
+	}
+
+	tests := []struct {
+		name          string
+		snapshot      []apitype.TestRegression
+		expectedTests map[string]apitype.ComponentReportStatus
+	}{
+		{
+			name: "recovered tests",
+			snapshot: []apitype.TestRegression{
+				regression("4.16", "1", "aws"),
+				regression("4.16", "2", "aws"),
+				regression("4.16", "4", "aws"),
+			},
+			expectedTests: map[string]apitype.ComponentReportStatus{
+				"1": apitype.NotSignificant,
+				"4": apitype.SignificantImprovement,
+			},
+		},
+		{
+			name: "regressed on another variant",
+			snapshot: []apitype.TestRegression{
+				regression("4.16", "1", "gcp"),
+			},
+			expectedTests: map[string]apitype.ComponentReportStatus{},
+		},
+		{
+			name: "regressed in another release",
+			snapshot: []apitype.TestRegression{
+				regression("4.15", "1", "aws"),
+			},
+			expectedTests: map[string]apitype.ComponentReportStatus{},
+		},
+		{
+			name:          "empty snapshot",
+			expectedTests: map[string]apitype.ComponentReportStatus{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := defaultComponentReportGenerator
+			generator.SampleRelease = apitype.ComponentReportRequestReleaseOptions{Release: "4.16"}
+			improvements := generator.newImprovementsSince(baseStatus, sampleStatus, tt.snapshot)
+			actual := map[string]apitype.ComponentReportStatus{}
+			for _, improvement := range improvements {
+				actual[improvement.TestID] = improvement.Status
+			}
+			assert.Equal(t, tt.expectedTests, actual)
+		})
+	}
+
+	// a basis recorded under a renamed variant is aligned with the sample
+	sdnTest := testIdentification("1")
+	sdnTest.Network = "sdn"
+	generator := defaultComponentReportGenerator
+	generator.SampleRelease = apitype.ComponentReportRequestReleaseOptions{Release: "4.16"}
+	generator.VariantAliases = map[string]map[string]string{"network": {"sdn": "ovn"}}
+	improvements := generator.newImprovementsSince(
+		map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{sdnTest: baseStatus[testIdentification("1")]},
+		map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{testIdentification("1"): sampleStatus[testIdentification("1")]},
+		[]apitype.TestRegression{regression("4.16", "1", "aws")})
+	assert.Equal(t, 1, len(improvements))
+}
+
+func Test_componentReportGenerator_newImprovementsSinceOverrides(t *testing.T) {
+	metal := apitype.ComponentTestIdentification{TestID: "1", Platform: "metal", Arch: "amd64", Network: "ovn", Upgrade: "upgrade-micro", FlatVariants: "standard"}
+	snapshot := []apitype.TestRegression{{
+		Release: "4.16",
+		TestID:  "1",
+		Variants: []apitype.ComponentReportVariant{
+			{Key: "Platform", Value: "metal"},
+			{Key: "Architecture", Value: "amd64"},
+			{Key: "Network", Value: "ovn"},
+			{Key: "Upgrade", Value: "upgrade-micro"},
+			{Key: "Variant", Value: "standard"},
+		},
+	}}
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+	tests := []struct {
+		name                      string
+		sampleSuccess             int
+		confidenceOverrides       []apitype.VariantConfidenceOverride
+		minimumFailureByComponent map[string]int
+		expectedImproved          bool
+	}{
+		{
+			// a drop from 100% to 92% over 50 runs, p is about 0.06
+			name:             "not significant at the default confidence",
+			sampleSuccess:    46,
+			expectedImproved: true,
+		},
+		{
+			name:                "still regressed at the confidence of the variant",
+			sampleSuccess:       46,
+			confidenceOverrides: []apitype.VariantConfidenceOverride{{VariantName: "platform", VariantValue: "metal", Confidence: 90}},
+		},
+		{
+			name:          "still regressed at the default minimum failure",
+			sampleSuccess: 44,
+		},
+		{
+			name:                      "below the minimum failure of the component",
+			sampleSuccess:             44,
+			minimumFailureByComponent: map[string]int{"component 1": 10},
+			expectedImproved:          true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+				metal: {TestName: "test 1", TotalCount: 50, SuccessCount: 50},
+			}
+			sampleStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+				metal: {TestName: "test 1", TotalCount: 50, SuccessCount: tt.sampleSuccess},
+			}
+			generator := defaultComponentReportGenerator
+			generator.SampleRelease = apitype.ComponentReportRequestReleaseOptions{Release: "4.16"}
+			generator.ConfidenceOverrides = tt.confidenceOverrides
+			generator.MinimumFailureByComponent = tt.minimumFailureByComponent
+			improvements := generator.newImprovementsSince(baseStatus, sampleStatus, snapshot)
+			if tt.expectedImproved {
+				assert.Equal(t, 1, len(improvements))
+			} else {
+				assert.Empty(t, improvements)
+			}
+		})
+	}
+}
+
+func Test_componentReportGenerator_failingRuns(t *testing.T) {
+	run := func(job, id string, hour, total, success, flake int) apitype.ComponentJobRunTestStatusRow {
+		return apitype.ComponentJobRunTestStatusRow{
+			ProwJob:      job,
+			ProwJobRunID: id,
+			StartTime:    civil.DateTime{Date: civil.Date{Year: 2024, Month: 3, Day: 1}, Time: civil.Time{Hour: hour}},
+			TotalCount:   total,
+			SuccessCount: success,
+			FlakeCount:   flake,
+		}
+	}
+	sampleStatus := map[string][]apitype.ComponentJobRunTestStatusRow{
+		"job-a": {
+			run("job-a", "1", 1, 1, 1, 0), // passed
+			run("job-a", "2", 2, 1, 0, 0), // failed
+			run("job-a", "3", 3, 1, 0, 1), // flaked
+		},
+		"job-b": {
+			run("job-b", "4", 4, 1, 0, 0), // failed
+			run("job-b", "5", 0, 1, 0, 0), // failed, start time unknown
+			run("job-b", "6", 5, 1, 1, 0), // passed
+		},
+	}
+
+	generator := testDetailsGenerator
+	failed := generator.failingRuns(sampleStatus)
+	ids := []string{}
+	for _, row := range failed {
+		ids = append(ids, row.ProwJobRunID)
+	}
+	assert.Equal(t, []string{"4", "2", "5"}, ids)
+}
+
+func Test_componentReportGenerator_bucketGranularity(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	prowJob := "periodic-ci-openshift-release-master-ci-4.16-e2e-aws-ovn"
+	sampleStatus := map[string][]apitype.ComponentJobRunTestStatusRow{
+		prowJob: {
+			{ProwJob: prowJob, StartTime: civil.DateTimeOf(start.Add(12 * time.Hour)), TotalCount: 1, SuccessCount: 1},
+			{ProwJob: prowJob, StartTime: civil.DateTimeOf(start.Add(8*day + 12*time.Hour)), TotalCount: 1},
+		},
+	}
+	tests := []struct {
+		name           string
+		granularity    apitype.BucketGranularity
+		window         time.Duration
+		expectedStarts []time.Time
+		expectedLastTo time.Time
+	}{
+		{
+			name:           "daily",
+			granularity:    apitype.BucketGranularityDay,
+			window:         3 * day,
+			expectedStarts: []time.Time{start, start.Add(day), start.Add(2 * day)},
+			expectedLastTo: start.Add(3 * day),
+		},
+		{
+			name:           "weekly",
+			granularity:    apitype.BucketGranularityWeek,
+			window:         10 * day,
+			expectedStarts: []time.Time{start, start.Add(7 * day)},
+			expectedLastTo: start.Add(10 * day),
+		},
+		{
+			name:           "auto short window is daily",
+			granularity:    apitype.BucketGranularityAuto,
+			window:         2 * day,
+			expectedStarts: []time.Time{start, start.Add(day)},
+			expectedLastTo: start.Add(2 * day),
+		},
+		{
+			name:           "auto long window is weekly",
+			granularity:    apitype.BucketGranularityAuto,
+			window:         30 * day,
+			expectedStarts: []time.Time{start, start.Add(7 * day), start.Add(14 * day), start.Add(21 * day), start.Add(28 * day)},
+			expectedLastTo: start.Add(30 * day),
+		},
+		{
+			name:   "not requested",
+			window: 30 * day,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := testDetailsGenerator
+			generator.BucketGranularity = tt.granularity
+			generator.SampleRelease = apitype.ComponentReportRequestReleaseOptions{Release: "4.16", Start: start, End: start.Add(tt.window)}
+			report := generator.generateComponentTestDetailsReport(map[string][]apitype.ComponentJobRunTestStatusRow{}, sampleStatus)
+			if len(tt.expectedStarts) == 0 {
+				assert.Nil(t, report.SampleSeries)
+				return
+			}
+			starts := []time.Time{}
+			for _, point := range report.SampleSeries {
+				starts = append(starts, point.Start.In(time.UTC))
+			}
+			assert.Equal(t, tt.expectedStarts, starts)
+			assert.Equal(t, civil.DateTimeOf(tt.expectedLastTo), report.SampleSeries[len(report.SampleSeries)-1].End)
+			assert.Equal(t, 1, report.SampleSeries[0].Total)
+			assert.Equal(t, 1.0, report.SampleSeries[0].PassRate)
+		})
+	}
+
+	t.Run("sub-window", func(t *testing.T) {
+		generator := testDetailsGenerator
+		generator.BucketGranularity = apitype.BucketGranularityDay
+		generator.SampleRelease = apitype.ComponentReportRequestReleaseOptions{Release: "4.16", Start: start, End: start.Add(10 * day)}
+		generator.SampleSubWindowStart = start.Add(8 * day)
+		report := generator.generateComponentTestDetailsReport(map[string][]apitype.ComponentJobRunTestStatusRow{}, sampleStatus)
+		assert.Len(t, report.SampleSeries, 2, "the series should only span the sub-window")
+		assert.Equal(t, civil.DateTimeOf(start.Add(8*day)), report.SampleSeries[0].Start)
+		assert.Equal(t, 1, report.SampleSeries[0].Total)
+		assert.Equal(t, 0.0, report.SampleSeries[0].PassRate)
+		assert.True(t, report.SampleSeries[1].Empty)
+	})
+}
+
+func Test_componentReportGenerator_chiSquaredThreshold(t *testing.T) {
+	tests := []struct {
+		name                   string
+		threshold              int
+		sampleTotal            int
+		sampleSuccess          int
+		baseTotal              int
+		baseSuccess            int
+		expectedStatus         apitype.ComponentReportStatus
+		expectedDecidingFactor apitype.DecidingFactor
+	}{
+		{
+			name:                   "disabled",
+			sampleTotal:            2000,
+			sampleSuccess:          1780,
+			baseTotal:              2000,
+			baseSuccess:            1900,
+			expectedStatus:         apitype.SignificantRegression,
+			expectedDecidingFactor: apitype.DecidingFactorFisher,
+		},
+		{
+			name:                   "both totals above threshold",
+			threshold:              1000,
+			sampleTotal:            2000,
+			sampleSuccess:          1780,
+			baseTotal:              2000,
+			baseSuccess:            1900,
+			expectedStatus:         apitype.SignificantRegression,
+			expectedDecidingFactor: apitype.DecidingFactorChiSquared,
+		},
+		{
+			name:                   "improvement above threshold",
+			threshold:              1000,
+			sampleTotal:            2000,
+			sampleSuccess:          1900,
+			baseTotal:              2000,
+			baseSuccess:            1780,
+			expectedStatus:         apitype.SignificantImprovement,
+			expectedDecidingFactor: apitype.DecidingFactorChiSquared,
+		},
+		{
+			name:                   "sample total below threshold",
+			threshold:              1000,
+			sampleTotal:            500,
+			sampleSuccess:          445,
+			baseTotal:              2000,
+			baseSuccess:            1900,
+			expectedStatus:         apitype.SignificantRegression,
+			expectedDecidingFactor: apitype.DecidingFactorFisher,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &componentReportGenerator{ComponentReportRequestAdvancedOptions: defaultAdvancedOption}
+			c.ChiSquaredThreshold = tt.threshold
+
+			status, p, decidingFactor := c.assessComponentStatus(tt.sampleTotal, tt.sampleSuccess, 0, tt.baseTotal, tt.baseSuccess, 0, nil, 0)
+			assert.Equal(t, tt.expectedStatus, status)
+			assert.Equal(t, tt.expectedDecidingFactor, decidingFactor)
+			assert.Greater(t, p, 0.0)
+		})
+	}
+}
+
+func Test_componentReportGenerator_chiSquaredTest(t *testing.T) {
+	c := &componentReportGenerator{ComponentReportRequestAdvancedOptions: defaultAdvancedOption}
+	for _, tt := range []struct{ sampleSuccess, baseSuccess int }{
+		{1780, 1900},
+		{1880, 1900},
+		{1900, 1900},
+		{1900, 1780},
+	} {
+		_, chiSquared := c.chiSquaredTest(2000, tt.sampleSuccess, 0, 2000, tt.baseSuccess, 0)
+		_, fisher := c.fischerExactTest(2000, tt.sampleSuccess, 0, 2000, tt.baseSuccess, 0)
+		assert.InDelta(t, fisher, chiSquared, 0.05, "chi-squared should approximate fisher for large totals")
+	}
+
+	_, p := c.chiSquaredTest(0, 0, 0, 0, 0, 0)
+	assert.Equal(t, 1.0, p, "an empty table is never significant")
+}
+
+func Test_componentReportGenerator_includePValues(t *testing.T) {
+	regressedTest := apitype.ComponentTestIdentification{
+		TestID:       "1",
+		Platform:     "aws",
+		Arch:         "amd64",
+		Network:      "ovn",
+		Upgrade:      "upgrade-micro",
+		FlatVariants: "standard",
+	}
+	steadyTest := regressedTest
+	steadyTest.TestID = "4"
+	identicalTest := regressedTest
+	identicalTest.TestID = "3"
+	baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		regressedTest: {TestName: "test 1", Variants: []string{"standard"}, TotalCount: 1000, SuccessCount: 900, FlakeCount: 10},
+		steadyTest:    {TestName: "test 4", Variants: []string{"standard"}, TotalCount: 1000, SuccessCount: 900, FlakeCount: 10},
+		identicalTest: {TestName: "test 3", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 90},
+	}
+	sampleStatus := func() map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus {
+		return map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+			regressedTest: {TestName: "test 1", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 50},
+			steadyTest:    {TestName: "test 4", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 90, FlakeCount: 1},
+			identicalTest: {TestName: "test 3", Variants: []string{"standard"}, TotalCount: 100, SuccessCount: 90},
+		}
+	}
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+
+	generator := defaultComponentReportGenerator
+	report := generator.generateComponentTestReport(baseStatus, sampleStatus(), []apitype.TestRegression{})
+	assert.Nil(t, report.Rows[0].Columns[0].PValues, "p-values should only be included on request")
+
+	generator.IncludePValues = true
+	report = generator.generateComponentTestReport(baseStatus, sampleStatus(), []apitype.TestRegression{})
+	assert.Equal(t, 1, len(report.Rows))
+	pValues := report.Rows[0].Columns[0].PValues
+	sort.Float64s(pValues)
+	assert.Equal(t, 2, len(pValues), "identical counts run no significance test, so have no p-value")
+	assert.Less(t, pValues[0], 0.05, "regressed test should have a significant p-value")
+	assert.Greater(t, pValues[1], 0.5, "an unchanged pass rate should be far from significant")
+	assert.Equal(t, []int{1, 0, 1, 0}, report.PValueHistogram(4))
+}
+
+func Test_componentReportGenerator_streamComponentTestReport(t *testing.T) {
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+	aws := apitype.ComponentTestIdentification{TestID: "1", Platform: "aws", Arch: "amd64", Network: "ovn", Upgrade: "upgrade-micro", FlatVariants: "standard"}
+	metal := aws
+	metal.Platform = "metal"
+	test2 := aws
+	test2.TestID = "2"
+	other := aws
+	other.TestID = "3"
+	statuses := func() (map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus, map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus) {
+		baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+			aws:   {TestName: "test 1", TotalCount: 100, SuccessCount: 100},
+			metal: {TestName: "test 1", TotalCount: 100, SuccessCount: 100},
+			test2: {TestName: "test 2", TotalCount: 100, SuccessCount: 100},
+			other: {TestName: "test 5", TotalCount: 100, SuccessCount: 100},
+		}
+		sampleStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+			aws:   {TestName: "test 1", TotalCount: 100, SuccessCount: 100},
+			metal: {TestName: "test 1", TotalCount: 100, SuccessCount: 100},
+			test2: {TestName: "test 2", TotalCount: 100, SuccessCount: 50},
+			other: {TestName: "test 5", TotalCount: 100, SuccessCount: 100},
+		}
+		return baseStatus, sampleStatus
+	}
+
+	baseStatus, sampleStatus := statuses()
+	expected := defaultComponentReportGenerator.generateComponentTestReport(baseStatus, sampleStatus, []apitype.TestRegression{})
+	assert.Equal(t, 3, len(expected.Rows))
+	// the regressed row comes first even though it sorts last
+	assert.Equal(t, "component 2", expected.Rows[0].Component)
+
+	t.Run("all rows are streamed in report order", func(t *testing.T) {
+		baseStatus, sampleStatus := statuses()
+		rows := make(chan apitype.ComponentReportRow)
+		go defaultComponentReportGenerator.streamComponentTestReport(context.Background(), baseStatus, sampleStatus, []apitype.TestRegression{}, rows)
+		streamed := []apitype.ComponentReportRow{}
+		for row := range rows {
+			streamed = append(streamed, row)
+		}
+		assert.Equal(t, expected.Rows, streamed)
+	})
+
+	t.Run("streaming stops when the context is done", func(t *testing.T) {
+		baseStatus, sampleStatus := statuses()
+		ctx, cancel := context.WithCancel(context.Background())
+		rows := make(chan apitype.ComponentReportRow)
+		done := make(chan struct{})
+		go func() {
+			defaultComponentReportGenerator.streamComponentTestReport(ctx, baseStatus, sampleStatus, []apitype.TestRegression{}, rows)
+			close(done)
+		}()
+		assert.Equal(t, expected.Rows[0], <-rows)
+		cancel()
+		<-done
+		_, ok := <-rows
+		assert.False(t, ok)
+	})
+}
+
+func Test_componentReportGenerator_includeImprovementsInList(t *testing.T) {
+	improved := apitype.ComponentTestIdentification{TestID: "1", Platform: "aws", Arch: "amd64", Network: "ovn", Upgrade: "upgrade-micro", FlatVariants: "standard"}
+	regressed := improved
+	regressed.TestID = "3"
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+	for _, include := range []bool{false, true} {
+		t.Run(fmt.Sprintf("include %t", include), func(t *testing.T) {
+			// tests 1 and 3 share a component and capability, so a cell
+			baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+				improved:  {TestName: "test 1", TotalCount: 100, SuccessCount: 70},
+				regressed: {TestName: "test 3", TotalCount: 100, SuccessCount: 100},
+			}
+			sampleStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+				improved:  {TestName: "test 1", TotalCount: 100, SuccessCount: 95},
+				regressed: {TestName: "test 3", TotalCount: 100, SuccessCount: 50},
+			}
+			generator := defaultComponentReportGenerator
+			generator.IncludeImprovementsInList = include
+			report := generator.generateComponentTestReport(baseStatus, sampleStatus, []apitype.TestRegression{})
+			assert.Equal(t, 1, len(report.Rows))
+			column := report.Rows[0].Columns[0]
+			assert.Equal(t, apitype.ExtremeRegression, column.Status)
+			assert.Equal(t, 1, len(column.RegressedTests))
+			assert.Equal(t, "3", column.RegressedTests[0].TestID)
+			if !include {
+				assert.Empty(t, column.ImprovedTests)
+				return
+			}
+			assert.Equal(t, 1, len(column.ImprovedTests))
+			assert.Equal(t, "1", column.ImprovedTests[0].TestID)
+			assert.Equal(t, apitype.SignificantImprovement, column.ImprovedTests[0].Status)
+		})
+	}
+}
+
+func Test_componentReportGenerator_regressionAge(t *testing.T) {
+	testIdentification := apitype.ComponentTestIdentification{TestID: "1", Platform: "aws", Arch: "amd64", Network: "ovn", Upgrade: "upgrade-micro", FlatVariants: "standard"}
+	end := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+	regression := func(opened time.Time, closed bool) []apitype.TestRegression {
+		return []apitype.TestRegression{
+			{
+				Release: "4.16",
+				TestID:  "1",
+				Opened:  opened,
+				Closed:  bigquery.NullTimestamp{Timestamp: end, Valid: closed},
+				Variants: []apitype.ComponentReportVariant{
+					{Key: "Platform", Value: "aws"},
+					{Key: "Architecture", Value: "amd64"},
+					{Key: "Network", Value: "ovn"},
+					{Key: "Upgrade", Value: "upgrade-micro"},
+					{Key: "Variant", Value: "standard"},
+				},
+			},
+		}
+	}
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+	tests := []struct {
+		name            string
+		regressions     []apitype.TestRegression
+		minimumAgeDays  int
+		expectedAgeDays int
+		expectedStatus  apitype.ComponentReportStatus
+	}{
+		{
+			name:            "age without a filter",
+			regressions:     regression(end.Add(-50*time.Hour), false),
+			expectedAgeDays: 2,
+			expectedStatus:  apitype.ExtremeRegression,
+		},
+		{
+			name:            "chronic regression is kept",
+			regressions:     regression(end.AddDate(0, 0, -10), false),
+			minimumAgeDays:  7,
+			expectedAgeDays: 10,
+			expectedStatus:  apitype.ExtremeRegression,
+		},
+		{
+			name:           "new regression is suppressed",
+			regressions:    regression(end.AddDate(0, 0, -2), false),
+			minimumAgeDays: 7,
+			expectedStatus: apitype.NotSignificant,
+		},
+		{
+			name:           "closed regression has no age",
+			regressions:    regression(end.AddDate(0, 0, -10), true),
+			minimumAgeDays: 7,
+			expectedStatus: apitype.NotSignificant,
+		},
+		{
+			name:           "regression opened after the sample has no age",
+			regressions:    regression(end.AddDate(0, 0, 10), false),
+			minimumAgeDays: 7,
+			expectedStatus: apitype.NotSignificant,
+		},
+		{
+			name:           "untracked regression is suppressed",
+			regressions:    []apitype.TestRegression{},
+			minimumAgeDays: 7,
+			expectedStatus: apitype.NotSignificant,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+				testIdentification: {TestName: "test 1", TotalCount: 100, SuccessCount: 100},
+			}
+			sampleStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+				testIdentification: {TestName: "test 1", TotalCount: 100, SuccessCount: 50},
+			}
+			generator := defaultComponentReportGenerator
+			generator.SampleRelease = apitype.ComponentReportRequestReleaseOptions{Release: "4.16", End: end}
+			generator.MinimumRegressionAgeDays = tt.minimumAgeDays
+			report := generator.generateComponentTestReport(baseStatus, sampleStatus, tt.regressions)
+			column := report.Rows[0].Columns[0]
+			assert.Equal(t, tt.expectedStatus, column.Status)
+			if tt.expectedStatus == apitype.NotSignificant {
+				assert.Empty(t, column.RegressedTests)
+				return
+			}
+			assert.Equal(t, 1, len(column.RegressedTests))
+			assert.Equal(t, tt.expectedAgeDays, column.RegressedTests[0].RegressionAgeDays)
+		})
+	}
+}
+
+func Test_componentReportGenerator_alternativeHypothesis(t *testing.T) {
+	tests := []struct {
+		name           string
+		alternative    apitype.AlternativeHypothesis
+		sampleSuccess  int
+		baseSuccess    int
+		expectedStatus apitype.ComponentReportStatus
+		expectedP      float64
+	}{
+		{
+			name:           "one sided regression",
+			sampleSuccess:  90,
+			baseSuccess:    97,
+			expectedStatus: apitype.SignificantRegression,
+			expectedP:      0.0409,
+		},
+		{
+			name:           "two sided p-value is doubled for the same drop",
+			alternative:    apitype.AlternativeTwoSided,
+			sampleSuccess:  90,
+			baseSuccess:    97,
+			expectedStatus: apitype.NotSignificant,
+			expectedP:      0.0818,
+		},
+		{
+			name:           "two sided regression",
+			alternative:    apitype.AlternativeTwoSided,
+			sampleSuccess:  88,
+			baseSuccess:    97,
+			expectedStatus: apitype.SignificantRegression,
+			expectedP:      0.0287,
+		},
+		{
+			name:           "two sided improvement",
+			alternative:    apitype.AlternativeTwoSided,
+			sampleSuccess:  95,
+			baseSuccess:    70,
+			expectedStatus: apitype.SignificantImprovement,
+			expectedP:      0.0000035,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &componentReportGenerator{ComponentReportRequestAdvancedOptions: defaultAdvancedOption}
+			c.AlternativeHypothesis = tt.alternative
+			status, p, decidingFactor := c.assessComponentStatus(100, tt.sampleSuccess, 0, 100, tt.baseSuccess, 0, nil, 0)
+			assert.Equal(t, tt.expectedStatus, status)
+			assert.Equal(t, apitype.DecidingFactorFisher, decidingFactor)
+			assert.InDelta(t, tt.expectedP, p, 0.0001)
+		})
+	}
+}
+
+func Test_componentReportGenerator_twoSidedChiSquaredTest(t *testing.T) {
+	c := &componentReportGenerator{ComponentReportRequestAdvancedOptions: defaultAdvancedOption}
+	_, oneSided := c.chiSquaredTest(1000, 900, 0, 1000, 930, 0)
+	c.AlternativeHypothesis = apitype.AlternativeTwoSided
+	_, twoSided := c.chiSquaredTest(1000, 900, 0, 1000, 930, 0)
+	assert.InDelta(t, 2*oneSided, twoSided, 1e-9)
+	// the two sided test is symmetric in the direction of the change
+	_, flipped := c.chiSquaredTest(1000, 930, 0, 1000, 900, 0)
+	assert.InDelta(t, twoSided, flipped, 1e-9)
+}
+
+func Test_componentReportGenerator_bayesianComparison(t *testing.T) {
+	tests := []struct {
+		name           string
+		sampleSuccess  int
+		baseSuccess    int
+		expectedStatus apitype.ComponentReportStatus
+	}{
+		{
+			name:           "regression",
+			sampleSuccess:  80,
+			baseSuccess:    100,
+			expectedStatus: apitype.ExtremeRegression,
+		},
+		{
+			name:           "interval includes zero",
+			sampleSuccess:  89,
+			baseSuccess:    95,
+			expectedStatus: apitype.NotSignificant,
+		},
+		{
+			name:           "improvement",
+			sampleSuccess:  95,
+			baseSuccess:    70,
+			expectedStatus: apitype.SignificantImprovement,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &componentReportGenerator{ComponentReportRequestAdvancedOptions: defaultAdvancedOption}
+			c.Comparison = apitype.ComparisonBayesian
+			status, p, decidingFactor := c.assessComponentStatus(100, tt.sampleSuccess, 0, 100, tt.baseSuccess, 0, nil, 0)
+			assert.Equal(t, tt.expectedStatus, status)
+			assert.Equal(t, apitype.DecidingFactorBayesian, decidingFactor)
+
+			lower, upper := credibleInterval(100, tt.sampleSuccess, 100, tt.baseSuccess, 95)
+			switch tt.expectedStatus {
+			case apitype.NotSignificant:
+				assert.GreaterOrEqual(t, p, 0.05)
+				assert.Less(t, *lower, 0.0)
+				assert.Greater(t, *upper, 0.0)
+			case apitype.SignificantImprovement:
+				assert.Greater(t, *lower, 0.0)
+			default:
+				assert.Less(t, p, 0.05)
+				assert.Less(t, *upper, 0.0)
+			}
+		})
+	}
+}
+
+func Test_componentReportGenerator_bayesianCredibleInterval(t *testing.T) {
+	testIdentification := apitype.ComponentTestIdentification{TestID: "1", Platform: "aws", Arch: "amd64", Network: "ovn", Upgrade: "upgrade-micro", FlatVariants: "standard"}
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+	baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		testIdentification: {TestName: "test 1", TotalCount: 100, SuccessCount: 100},
+	}
+	sampleStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		testIdentification: {TestName: "test 1", TotalCount: 100, SuccessCount: 80},
+	}
+	generator := defaultComponentReportGenerator
+	generator.Comparison = apitype.ComparisonBayesian
+	report := generator.generateComponentTestReport(baseStatus, sampleStatus, []apitype.TestRegression{})
+	regressedTest := report.Rows[0].Columns[0].RegressedTests[0]
+	assert.Equal(t, apitype.DecidingFactorBayesian, regressedTest.DecidingFactor)
+	assert.NotNil(t, regressedTest.CredibleIntervalLower)
+	assert.NotNil(t, regressedTest.CredibleIntervalUpper)
+	// the posterior means are 81/102 and 101/102
+	assert.InDelta(t, -20.0/102, (*regressedTest.CredibleIntervalLower+*regressedTest.CredibleIntervalUpper)/2, 1e-9)
+
+	// identical pass rates give an interval centred on zero
+	lower, upper := credibleInterval(50, 45, 50, 45, 95)
+	assert.InDelta(t, 0, *lower+*upper, 1e-9)
+}
+
+func Test_componentReportGenerator_testDetailsQueryCacheKey(t *testing.T) {
+	generator := testDetailsGenerator
+	generator.SampleRelease = apitype.ComponentReportRequestReleaseOptions{Release: "4.16"}
+	generator.BaseRelease = apitype.ComponentReportRequestReleaseOptions{Release: "4.15"}
+	key := func(g componentReportGenerator) string {
+		cacheData := g.testDetailsQueryCacheKey("SampleJobRunTestStatus~", g.ExcludeFirstPRRuns)
+		b, err := cacheData.GetCacheKey()
+		assert.NoError(t, err)
+		return string(b)
+	}
+	expected := key(generator)
+
+	modified := time.Now()
+	unrelated := generator
+	unrelated.ReportModified = &modified
+	unrelated.Confidence = 99
+	unrelated.Comparison = apitype.ComparisonBayesian
+	assert.Equal(t, expected, key(unrelated), "report options and triage changes should reuse the queries")
+
+	otherTest := generator
+	otherTest.TestID = "2"
+	assert.NotEqual(t, expected, key(otherTest))
+
+	otherVariant := generator
+	otherVariant.Platform = "metal"
+	assert.NotEqual(t, expected, key(otherVariant))
+
+	otherWindow := generator
+	otherWindow.SampleRelease.End = time.Now()
+	assert.NotEqual(t, expected, key(otherWindow))
+
+	presubmits := generator
+	presubmits.ExcludeFirstPRRuns = true
+	assert.NotEqual(t, expected, key(presubmits), "sample queries including presubmits should be cached apart")
+}
+
+func Test_componentReportGenerator_excludedJobRunURLs(t *testing.T) {
+	prowJob := "periodic-ci-openshift-release-master-ci-4.16-e2e-aws-ovn"
+	run := func(id string, success bool) apitype.ComponentJobRunTestStatusRow {
+		row := apitype.ComponentJobRunTestStatusRow{ProwJob: prowJob, ProwJobRunID: id, TotalCount: 1}
+		if success {
+			row.SuccessCount = 1
+		}
+		return row
+	}
+	baseStatus := map[string][]apitype.ComponentJobRunTestStatusRow{
+		prowJob: {run("1", true), run("2", false), run("3", true)},
+	}
+	sampleStatus := map[string][]apitype.ComponentJobRunTestStatusRow{
+		prowJob: {run("4", false), run("5", true), run("6", false)},
+	}
+
+	generator := testDetailsGenerator
+	generator.ExcludedJobRunURLs = []string{
+		"https://prow.ci.openshift.org/view/gs/test-platform-results/logs/" + prowJob + "/2",
+		"https://prow.ci.openshift.org/view/gs/test-platform-results/logs/" + prowJob + "/6/",
+		"https://prow.ci.openshift.org/view/gs/test-platform-results/logs/" + prowJob + "/99",
+	}
+	report := generator.generateComponentTestDetailsReport(baseStatus, sampleStatus)
+	assert.Equal(t, 2, report.ExcludedJobRuns)
+	assert.Equal(t, 2, report.BaseStats.SuccessCount)
+	assert.Equal(t, 0, report.BaseStats.FailureCount)
+	assert.Equal(t, 1, report.SampleStats.SuccessCount)
+	assert.Equal(t, 1, report.SampleStats.FailureCount)
+}
+
+func Test_componentReportGenerator_ignoreInfraFailures(t *testing.T) {
+	prowJob := "periodic-ci-openshift-release-master-ci-4.16-e2e-aws-ovn"
+	run := func(id string, success, infraFailure bool) apitype.ComponentJobRunTestStatusRow {
+		row := apitype.ComponentJobRunTestStatusRow{ProwJob: prowJob, ProwJobRunID: id, TotalCount: 1, InfrastructureFailure: infraFailure}
+		if success {
+			row.SuccessCount = 1
+		}
+		return row
+	}
+	statuses := func() (map[string][]apitype.ComponentJobRunTestStatusRow, map[string][]apitype.ComponentJobRunTestStatusRow) {
+		base := map[string][]apitype.ComponentJobRunTestStatusRow{
+			prowJob: {run("1", true, false), run("2", false, true), run("3", true, false)},
+		}
+		sample := map[string][]apitype.ComponentJobRunTestStatusRow{
+			prowJob: {run("4", false, true), run("5", false, true), run("6", true, false), run("7", false, false)},
+		}
+		return base, sample
+	}
+
+	generator := testDetailsGenerator
+	report := generator.generateComponentTestDetailsReport(statuses())
+	assert.Equal(t, 0, report.InfraFailedJobRuns)
+	assert.Equal(t, 1, report.BaseStats.FailureCount)
+	assert.Equal(t, 3, report.SampleStats.FailureCount)
+
+	generator.IgnoreInfraFailures = true
+	report = generator.generateComponentTestDetailsReport(statuses())
+	assert.Equal(t, 3, report.InfraFailedJobRuns)
+	assert.Equal(t, 2, report.BaseStats.SuccessCount)
+	assert.Equal(t, 0, report.BaseStats.FailureCount)
+	assert.Equal(t, 1, report.SampleStats.SuccessCount)
+	assert.Equal(t, 1, report.SampleStats.FailureCount)
+}
+
+func Test_componentReportGenerator_includeRawStats(t *testing.T) {
+	prowJob := "periodic-ci-openshift-release-master-ci-4.16-e2e-aws-ovn"
+	runs := func(successes, flakes, failures int) []apitype.ComponentJobRunTestStatusRow {
+		rows := []apitype.ComponentJobRunTestStatusRow{}
+		for i := 0; i < successes; i++ {
+			rows = append(rows, apitype.ComponentJobRunTestStatusRow{ProwJob: prowJob, TotalCount: 1, SuccessCount: 1})
+		}
+		for i := 0; i < flakes; i++ {
+			rows = append(rows, apitype.ComponentJobRunTestStatusRow{ProwJob: prowJob, TotalCount: 1, FlakeCount: 1})
+		}
+		for i := 0; i < failures; i++ {
+			rows = append(rows, apitype.ComponentJobRunTestStatusRow{ProwJob: prowJob, TotalCount: 1})
+		}
+		return rows
+	}
+	statuses := func() (map[string][]apitype.ComponentJobRunTestStatusRow, map[string][]apitype.ComponentJobRunTestStatusRow) {
+		return map[string][]apitype.ComponentJobRunTestStatusRow{prowJob: runs(95, 2, 3)},
+			map[string][]apitype.ComponentJobRunTestStatusRow{prowJob: runs(18, 1, 6)}
+	}
+
+	generator := testDetailsGenerator
+	report := generator.generateComponentTestDetailsReport(statuses())
+	assert.Nil(t, report.ContingencyTable, "raw stats should only be included when requested")
+
+	generator.IncludeRawStats = true
+	report = generator.generateComponentTestDetailsReport(statuses())
+	assert.Equal(t, &apitype.ContingencyTable{SamplePass: 19, SampleFail: 6, BasePass: 97, BaseFail: 3}, report.ContingencyTable)
+	_, _, p, _ := fischer.FisherExactTest(report.ContingencyTable.SampleFail, report.ContingencyTable.SamplePass,
+		report.ContingencyTable.BaseFail, report.ContingencyTable.BasePass)
+	assert.Equal(t, report.FisherExact, p, "the p-value should be reproducible from the table")
+
+	assert.Equal(t, &apitype.ContingencyTable{SamplePass: 19, SampleFail: 2, BasePass: 97, BaseFail: 3},
+		contingencyTable(25, 18, 1, 100, 95, 2, 4), "failures of triaged incidents should be left out")
+}
+
+func Test_componentReportGenerator_sampleSubWindow(t *testing.T) {
+	prowJob := "periodic-ci-openshift-release-master-ci-4.16-e2e-aws-ovn"
+	windowEnd := time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)
+	run := func(daysAgo int, success bool) apitype.ComponentJobRunTestStatusRow {
+		row := apitype.ComponentJobRunTestStatusRow{
+			ProwJob:    prowJob,
+			TotalCount: 1,
+			StartTime:  civil.DateTimeOf(windowEnd.AddDate(0, 0, -daysAgo)),
+		}
+		if success {
+			row.SuccessCount = 1
+		}
+		return row
+	}
+	statuses := func() (map[string][]apitype.ComponentJobRunTestStatusRow, map[string][]apitype.ComponentJobRunTestStatusRow) {
+		base := map[string][]apitype.ComponentJobRunTestStatusRow{
+			prowJob: {run(40, true), run(20, true), run(2, false)},
+		}
+		sample := map[string][]apitype.ComponentJobRunTestStatusRow{
+			// passing early in the window, failing in the last few days
+			prowJob: {run(10, true), run(8, true), run(6, true), run(3, false), run(2, false), run(1, true)},
+		}
+		return base, sample
+	}
+
+	generator := testDetailsGenerator
+	generator.SampleRelease = apitype.ComponentReportRequestReleaseOptions{
+		Release: "4.16",
+		Start:   windowEnd.AddDate(0, 0, -14),
+		End:     windowEnd,
+	}
+
+	tests := []struct {
+		name            string
+		start, end      time.Time
+		expectedSuccess int
+		expectedFailure int
+	}{
+		{
+			name:            "no sub-window",
+			expectedSuccess: 4,
+			expectedFailure: 2,
+		},
+		{
+			name:            "last few days",
+			start:           windowEnd.AddDate(0, 0, -4),
+			expectedSuccess: 1,
+			expectedFailure: 2,
+		},
+		{
+			name:            "bounded on both sides",
+			start:           windowEnd.AddDate(0, 0, -9),
+			end:             windowEnd.AddDate(0, 0, -3),
+			expectedSuccess: 2,
+			expectedFailure: 1,
+		},
+		{
+			name:            "intersected with the release window",
+			start:           windowEnd.AddDate(0, 0, -30),
+			end:             windowEnd.AddDate(0, 0, 30),
+			expectedSuccess: 4,
+			expectedFailure: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := generator
+			g.SampleSubWindowStart = tt.start
+			g.SampleSubWindowEnd = tt.end
+			base, sample := statuses()
+			report := g.generateComponentTestDetailsReport(base, sample)
+			assert.Equal(t, tt.expectedSuccess, report.SampleStats.SuccessCount)
+			assert.Equal(t, tt.expectedFailure, report.SampleStats.FailureCount)
+			assert.Equal(t, 2, report.BaseStats.SuccessCount, "base runs should not be sliced")
+		})
+	}
+}
+
+func Test_observedVariants(t *testing.T) {
+	rows := []observedVariantRow{
+		{Network: "ovn", Upgrade: "none", Arch: "amd64", Platform: "aws", Variants: []string{"standard"}},
+		{Network: "ovn", Upgrade: "minor", Arch: "amd64", Platform: "aws", Variants: []string{"standard"}},
+		{Network: "sdn", Upgrade: "none", Arch: "arm64", Platform: "gcp", Variants: []string{"serial", "techpreview"}},
+		{Network: "ovn", Upgrade: "none", Arch: "amd64", Platform: "metal"},
+	}
+	assert.Equal(t, map[string][]string{
+		"network":  {"ovn", "sdn"},
+		"upgrade":  {"minor", "none"},
+		"arch":     {"amd64", "arm64"},
+		"platform": {"aws", "gcp", "metal"},
+		"variant":  {"serial", "standard", "techpreview"},
+	}, observedVariants(rows))
+
+	assert.Equal(t, map[string][]string{
+		"network":  {"ovn"},
+		"arch":     {"amd64"},
+		"platform": {"aws"},
+	}, observedVariants([]observedVariantRow{{Network: "ovn", Arch: "amd64", Platform: "aws"}}), "variants without values should not be keyed")
+	assert.Empty(t, observedVariants(nil))
+}
+
+func Test_componentReportGenerator_sameReleaseWindows(t *testing.T) {
+	minorUpgrade := "periodic-ci-openshift-release-master-ci-4.16-upgrade-from-stable-4.15-e2e-aws-ovn-upgrade"
+	microUpgrade := "periodic-ci-openshift-release-master-ci-4.16-e2e-aws-ovn-upgrade"
+	run := func(prowJob string, success bool) apitype.ComponentJobRunTestStatusRow {
+		row := apitype.ComponentJobRunTestStatusRow{ProwJob: prowJob, TotalCount: 1}
+		if success {
+			row.SuccessCount = 1
+		}
+		return row
+	}
+	runs := func(prowJob string, successes, failures int) []apitype.ComponentJobRunTestStatusRow {
+		rows := []apitype.ComponentJobRunTestStatusRow{}
+		for i := 0; i < successes; i++ {
+			rows = append(rows, run(prowJob, true))
+		}
+		for i := 0; i < failures; i++ {
+			rows = append(rows, run(prowJob, false))
+		}
+		return rows
+	}
+
+	generator := testDetailsGenerator
+	generator.BaseRelease = apitype.ComponentReportRequestReleaseOptions{
+		Release: "4.16",
+		Start:   time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+		End:     time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC),
+	}
+	generator.SampleRelease = apitype.ComponentReportRequestReleaseOptions{
+		Release: "4.16",
+		Start:   time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC),
+		End:     time.Date(2024, 4, 29, 0, 0, 0, 0, time.UTC),
+	}
+	assert.NotEqual(t, generator.normalizeProwJobName(minorUpgrade), generator.normalizeProwJobName(microUpgrade))
+
+	baseStatus := map[string][]apitype.ComponentJobRunTestStatusRow{
+		generator.normalizeProwJobName(minorUpgrade): runs(minorUpgrade, 50, 0),
+		generator.normalizeProwJobName(microUpgrade): runs(microUpgrade, 50, 0),
+	}
+	sampleStatus := map[string][]apitype.ComponentJobRunTestStatusRow{
+		generator.normalizeProwJobName(minorUpgrade): runs(minorUpgrade, 30, 20),
+		generator.normalizeProwJobName(microUpgrade): runs(microUpgrade, 50, 0),
+	}
+	report := generator.generateComponentTestDetailsReport(baseStatus, sampleStatus)
+	assert.Equal(t, apitype.ExtremeRegression, report.ReportStatus)
+	assert.Equal(t, "4.16", report.BaseStats.Release)
+	assert.Equal(t, "4.16", report.SampleStats.Release)
+	assert.Len(t, report.JobStats, 2, "the minor and micro upgrade jobs should be compared separately")
+}
+
+func Test_componentReportGenerator_collapsedVariants(t *testing.T) {
+	type entry struct {
+		platform, upgrade, suite string
+	}
+	status := func(entries ...entry) map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus {
+		m := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{}
+		for _, e := range entries {
+			id := apitype.ComponentTestIdentification{
+				TestID:       "1",
+				Platform:     e.platform,
+				Arch:         "amd64",
+				Network:      "ovn",
+				Upgrade:      e.upgrade,
+				FlatVariants: "standard",
+			}
+			m[id] = apitype.ComponentTestStatus{TestName: "test 1", TestSuite: e.suite, TotalCount: 10, SuccessCount: 10}
+		}
+		return m
+	}
+	awsNone := entry{"aws", "none", "suite-a"}
+	gcpMinor := entry{"gcp", "minor", "suite-a"}
+	awsMicro := entry{"aws", "micro", "suite-b"}
+
+	tests := []struct {
+		name     string
+		groupBy  string
+		testID   string
+		base     map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus
+		sample   map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus
+		expected []string
+	}{
+		{
+			name:     "grouping leaves out upgrade",
+			groupBy:  "cloud,arch,network",
+			base:     status(awsNone, gcpMinor),
+			sample:   status(awsNone),
+			expected: []string{"upgrade"},
+		},
+		{
+			name:     "values seen only in the sample count",
+			groupBy:  "arch,network",
+			base:     status(awsNone),
+			sample:   status(gcpMinor, awsMicro),
+			expected: []string{"cloud", "upgrade", "suite"},
+		},
+		{
+			name:    "every varying key is grouped",
+			groupBy: "cloud,upgrade",
+			base:    status(awsNone, gcpMinor),
+			sample:  status(gcpMinor),
+		},
+		{
+			name:    "a single test is not collapsed",
+			groupBy: "arch",
+			testID:  "1",
+			base:    status(awsNone, gcpMinor),
+			sample:  status(awsMicro),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := componentReportGenerator{}
+			c.GroupBy = tt.groupBy
+			c.TestID = tt.testID
+			assert.Equal(t, tt.expected, c.collapsedVariants(tt.base, tt.sample))
+		})
+	}
+}
+
+func Test_getSuccessRate(t *testing.T) {
+	tests := []struct {
+		name          string
+		success       int
+		failure       int
+		flake         int
+		flakeHandling apitype.FlakeHandling
+		want          float64
+	}{
+		{name: "flakes pass", success: 5, failure: 5, flake: 10, want: 0.75},
+		{name: "flakes excluded", success: 5, failure: 5, flake: 10, flakeHandling: apitype.FlakeHandlingExclude, want: 0.5},
+		{name: "flaky without failures passes", success: 10, flake: 10, flakeHandling: apitype.FlakeHandlingExclude, want: 1},
+		{name: "no runs", want: 0},
+		{name: "no runs with flakes excluded", flakeHandling: apitype.FlakeHandlingExclude, want: 0},
+		{name: "only flakes", flake: 10, want: 1},
+		{name: "only flakes excluded", flake: 10, flakeHandling: apitype.FlakeHandlingExclude, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, getSuccessRate(tt.success, tt.failure, tt.flake, tt.flakeHandling))
+			stats := apitype.ComponentTestStatus{TotalCount: tt.success + tt.failure + tt.flake, SuccessCount: tt.success, FlakeCount: tt.flake}
+			assert.Equal(t, tt.want, getPassRate(stats, tt.flakeHandling))
+			row := apitype.ComponentJobRunTestStatusRow{TotalCount: stats.TotalCount, SuccessCount: tt.success, FlakeCount: tt.flake}
+			assert.Equal(t, tt.want, getJobRunStats(row, "https://prow.ci.openshift.org", "test-platform-results", tt.flakeHandling).TestStats.SuccessRate)
+		})
+	}
+}
+
+func Test_componentReportGenerator_flakeHandling(t *testing.T) {
+	test := apitype.ComponentTestIdentification{TestID: "1", Platform: "aws", Arch: "amd64", Network: "ovn", Upgrade: "upgrade-micro", FlatVariants: "standard"}
+	baseStatus := func() map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus {
+		return map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+			test: {TestName: "test 1", TotalCount: 100, SuccessCount: 100},
+		}
+	}
+	// half the sample runs flaked and a fifth of the rest failed
+	sampleStatus := func() map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus {
+		return map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+			test: {TestName: "test 1", TotalCount: 100, SuccessCount: 40, FlakeCount: 50},
+		}
+	}
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+
+	generator := defaultComponentReportGenerator
+	report := generator.generateComponentTestReport(baseStatus(), sampleStatus(), []apitype.TestRegression{})
+	regressedTests := report.Rows[0].Columns[0].RegressedTests
+	assert.Equal(t, 1, len(regressedTests))
+	assert.Equal(t, 0.9, regressedTests[0].SampleSuccessRate)
+
+	generator.FlakeHandling = apitype.FlakeHandlingExclude
+	report = generator.generateComponentTestReport(baseStatus(), sampleStatus(), []apitype.TestRegression{})
+	regressedTests = report.Rows[0].Columns[0].RegressedTests
+	assert.Equal(t, 1, len(regressedTests), "regressions should still be assessed counting flakes as passes")
+	assert.Equal(t, 0.8, regressedTests[0].SampleSuccessRate)
+	assert.Equal(t, 1.0, regressedTests[0].BaseSuccessRate)
+
+	newTestStats := generator.newTests(map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{}, sampleStatus())
+	assert.Equal(t, 1, len(newTestStats))
+	assert.Equal(t, 0.8, newTestStats[0].SampleStats.SuccessRate)
+}
+
+func Test_newTests(t *testing.T) {
+	id := func(testID, platform string) apitype.ComponentTestIdentification {
+		return apitype.ComponentTestIdentification{TestID: testID, Platform: platform, Arch: "amd64", Network: "ovn", Upgrade: "none", FlatVariants: "standard"}
+	}
+	stats := func(name, component string, total, success, flake int) apitype.ComponentTestStatus {
+		return apitype.ComponentTestStatus{TestName: name, TestSuite: "suite", Component: component, TotalCount: total, SuccessCount: success, FlakeCount: flake}
+	}
+	baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		id("1", "aws"): stats("test 1", "component 1", 100, 100, 0),
+		id("2", "aws"): stats("test 2", "component 2", 100, 90, 0),
+	}
+	sampleStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		id("1", "aws"): stats("test 1", "component 1", 100, 95, 0),
+		id("1", "gcp"): stats("test 1", "component 1", 10, 8, 1),
+		id("3", "aws"): stats("test 3", "component 1", 20, 5, 0),
+		id("2", "aws"): stats("test 2", "component 2", 100, 92, 0),
+		id("4", "aws"): stats("test 4", "component 0", 4, 4, 0),
+	}
+
+	generator := componentReportGenerator{}
+	tests := generator.newTests(baseStatus, sampleStatus)
+	names := []string{}
+	for _, test := range tests {
+		names = append(names, test.TestName+"/"+test.Platform)
+	}
+	assert.Equal(t, []string{"test 4/aws", "test 1/gcp", "test 3/aws"}, names)
+	assert.Equal(t, apitype.ComponentReportTestDetailsTestStats{SuccessRate: 0.9, SuccessCount: 8, FailureCount: 1, FlakeCount: 1}, tests[1].SampleStats)
+	assert.Equal(t, "standard", tests[1].Variant)
+	assert.Equal(t, 0.25, tests[2].SampleStats.SuccessRate, "new tests should be listed regardless of pass rate")
+	assert.Empty(t, generator.newTests(baseStatus, baseStatus))
+
+	// renamed variants and a cross compared base arch are aligned before diffing
+	generator.VariantAliases = map[string]map[string]string{"network": {"sdn": "ovn"}}
+	generator.Arch = "arm64"
+	generator.BaseArch = "amd64"
+	sdnBase := id("1", "aws")
+	sdnBase.Network = "sdn"
+	armSample := id("1", "aws")
+	armSample.Arch = "arm64"
+	assert.Empty(t, generator.newTests(
+		map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{sdnBase: stats("test 1", "component 1", 100, 100, 0)},
+		map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{armSample: stats("test 1", "component 1", 100, 95, 0)}))
+}
+
+func Test_getBasisQueries(t *testing.T) {
+	start415 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	start414 := time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC)
+	release415 := apitype.ComponentReportRequestReleaseOptions{Release: "4.15", Start: start415, End: start415.AddDate(0, 1, 0)}
+	release414 := apitype.ComponentReportRequestReleaseOptions{Release: "4.14", Start: start414, End: start414.AddDate(0, 1, 0)}
+
+	tests := []struct {
+		name          string
+		baseRelease   apitype.ComponentReportRequestReleaseOptions
+		expected      []basisQuery
+		expectedError bool
+	}{
+		{
+			name:        "no overrides",
+			baseRelease: release415,
+			expected: []basisQuery{
+				{release: release415, parameters: []bigquery.QueryParameter{}},
+			},
+		},
+		{
+			name: "arm64 uses a different basis than amd64",
+			baseRelease: apitype.ComponentReportRequestReleaseOptions{
+				Release: release415.Release,
+				Start:   release415.Start,
+				End:     release415.End,
+				VariantOverrides: []apitype.ComponentReportReleaseOverride{
+					{Variant: "arch", Value: "arm64", Release: release414.Release, Start: release414.Start, End: release414.End},
+				},
+			},
+			expected: []basisQuery{
+				{
+					release:    release414,
+					filter:     " AND arch = @BasisOverride0",
+					parameters: []bigquery.QueryParameter{{Name: "BasisOverride0", Value: "arm64"}},
+				},
+				{
+					release:    release415,
+					filter:     " AND arch != @BasisOverride0",
+					parameters: []bigquery.QueryParameter{{Name: "BasisOverride0", Value: "arm64"}},
+				},
+			},
+		},
+		{
+			name: "later overrides exclude earlier ones",
+			baseRelease: apitype.ComponentReportRequestReleaseOptions{
+				Release: release415.Release,
+				Start:   release415.Start,
+				End:     release415.End,
+				VariantOverrides: []apitype.ComponentReportReleaseOverride{
+					{Variant: "arch", Value: "arm64", Release: release414.Release, Start: release414.Start, End: release414.End},
+					{Variant: "platform", Value: "metal", Release: release414.Release, Start: release414.Start, End: release414.End},
+				},
+			},
+			expected: []basisQuery{
+				{
+					release:    release414,
+					filter:     " AND arch = @BasisOverride0",
+					parameters: []bigquery.QueryParameter{{Name: "BasisOverride0", Value: "arm64"}},
+				},
+				{
+					release:    release414,
+					filter:     " AND arch != @BasisOverride0 AND platform = @BasisOverride1",
+					parameters: []bigquery.QueryParameter{{Name: "BasisOverride0", Value: "arm64"}, {Name: "BasisOverride1", Value: "metal"}},
+				},
+				{
+					release:    release415,
+					filter:     " AND arch != @BasisOverride0 AND platform != @BasisOverride1",
+					parameters: []bigquery.QueryParameter{{Name: "BasisOverride0", Value: "arm64"}, {Name: "BasisOverride1", Value: "metal"}},
+				},
+			},
+		},
+		{
+			name: "unsupported variant",
+			baseRelease: apitype.ComponentReportRequestReleaseOptions{
+				Release:          release415.Release,
+				VariantOverrides: []apitype.ComponentReportReleaseOverride{{Variant: "arch; DROP TABLE", Value: "arm64"}},
+			},
+			expectedError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queries, err := getBasisQueries(tt.baseRelease)
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, queries)
+		})
+	}
+}
+
+func Test_sortJobRunStats(t *testing.T) {
+	older := civil.DateTime{Date: civil.Date{Year: 2024, Month: 2, Day: 1}, Time: civil.Time{Hour: 10}}
+	newer := civil.DateTime{Date: civil.Date{Year: 2024, Month: 2, Day: 2}, Time: civil.Time{Hour: 8}}
+	unknown := civil.DateTime{}
+
+	tests := []struct {
+		name     string
+		stats    []apitype.ComponentReportTestDetailsJobRunStats
+		expected []string
+	}{
+		{
+			name: "most recent first",
+			stats: []apitype.ComponentReportTestDetailsJobRunStats{
+				{JobURL: "older", StartTime: older},
+				{JobURL: "newer", StartTime: newer},
+			},
+			expected: []string{"newer", "older"},
+		},
+		{
+			name: "unknown start times sort as oldest",
+			stats: []apitype.ComponentReportTestDetailsJobRunStats{
+				{JobURL: "unknown1", StartTime: unknown},
+				{JobURL: "older", StartTime: older},
+				{JobURL: "unknown2", StartTime: unknown},
+				{JobURL: "newer", StartTime: newer},
+			},
+			expected: []string{"newer", "older", "unknown1", "unknown2"},
+		},
+		{
+			name: "ties keep their original order",
+			stats: []apitype.ComponentReportTestDetailsJobRunStats{
+				{JobURL: "first", StartTime: older},
+				{JobURL: "second", StartTime: older},
+				{JobURL: "third", StartTime: unknown},
+				{JobURL: "fourth", StartTime: unknown},
+			},
+			expected: []string{"first", "second", "third", "fourth"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sortJobRunStats(tt.stats)
+			urls := []string{}
+			for _, stats := range tt.stats {
+				urls = append(urls, stats.JobURL)
+			}
+			assert.Equal(t, tt.expected, urls)
+		})
+	}
+}
+
+func Test_componentReportGenerator_assessComponentStatusDecidingFactor(t *testing.T) {
+	tests := []struct {
+		name           string
+		passRateFloor  int
+		sampleTotal    int
+		sampleSuccess  int
+		baseTotal      int
+		baseSuccess    int
+		expectedStatus apitype.ComponentReportStatus
+		expectedFactor apitype.DecidingFactor
+	}{
+		{
+			name:           "fisher regression",
+			sampleTotal:    100,
+			sampleSuccess:  50,
+			baseTotal:      1000,
+			baseSuccess:    900,
+			expectedStatus: apitype.ExtremeRegression,
+			expectedFactor: apitype.DecidingFactorFisher,
+		},
+		{
+			name:           "fisher not significant",
+			sampleTotal:    100,
+			sampleSuccess:  91,
+			baseTotal:      1000,
+			baseSuccess:    900,
+			expectedStatus: apitype.NotSignificant,
+			expectedFactor: apitype.DecidingFactorFisher,
+		},
+		{
+			name:           "within pity factor",
+			sampleTotal:    1000,
+			sampleSuccess:  890,
+			baseTotal:      1000,
+			baseSuccess:    900,
+			expectedStatus: apitype.NotSignificant,
+			expectedFactor: apitype.DecidingFactorPity,
+		},
+		{
+			name:           "too few failures",
+			sampleTotal:    4,
+			sampleSuccess:  2,
+			baseTotal:      1000,
+			baseSuccess:    900,
+			expectedStatus: apitype.NotSignificant,
+			expectedFactor: apitype.DecidingFactorMinimumFailure,
+		},
+		{
+			name:           "below pass rate floor",
+			passRateFloor:  80,
+			sampleTotal:    100,
+			sampleSuccess:  70,
+			expectedStatus: apitype.ExtremeRegression,
+			expectedFactor: apitype.DecidingFactorPassRateFloor,
+		},
+		{
+			name:           "nothing to compare",
+			sampleTotal:    100,
+			sampleSuccess:  70,
+			expectedStatus: apitype.MissingBasis,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &componentReportGenerator{ComponentReportRequestAdvancedOptions: defaultAdvancedOption}
+			c.ExtremePassRateFloor = tt.passRateFloor
+
+			status, _, factor := c.assessComponentStatus(tt.sampleTotal, tt.sampleSuccess, 0, tt.baseTotal, tt.baseSuccess, 0, nil, 0)
+			assert.Equal(t, tt.expectedStatus, status)
+			assert.Equal(t, tt.expectedFactor, factor)
+		})
+	}
+}
+
+func Test_componentReportGenerator_getIncludeJobsFilter(t *testing.T) {
+	c := &componentReportGenerator{
+		client:        &bqcachedclient.Client{Dataset: "ci_analysis_us"},
+		BaseRelease:   apitype.ComponentReportRequestReleaseOptions{Release: "4.15"},
+		SampleRelease: apitype.ComponentReportRequestReleaseOptions{Release: "4.16"},
+	}
+
+	queryString, _, params := c.getCommonTestStatusQuery()
+	assert.NotContains(t, queryString, "@IncludeJobs", "jobs should only be filtered on request")
+	for _, param := range params {
+		assert.NotEqual(t, "IncludeJobs", param.Name)
+	}
+
+	c.IncludeJobs = []string{"periodic-ci-openshift-release-master-ci-X.X-e2e-aws-ovn-upgrade"}
+	filter, filterParams := c.getIncludeJobsFilter()
+	assert.Equal(t, ` AND REGEXP_REPLACE(REGEXP_REPLACE(REGEXP_REPLACE(REGEXP_REPLACE(REGEXP_REPLACE(prowjob_name, @NormalizePattern0, @NormalizeReplacement0), @NormalizePattern1, @NormalizeReplacement1), @NormalizePattern2, @NormalizeReplacement2), @NormalizePattern3, @NormalizeReplacement3), @NormalizePattern4, @NormalizeReplacement4) IN UNNEST(@IncludeJobs)`, filter)
+	assert.Equal(t, []bigquery.QueryParameter{
+		{Name: "NormalizePattern0", Value: `4\.15`},
+		{Name: "NormalizeReplacement0", Value: "X.X"},
+		{Name: "NormalizePattern1", Value: `4\.14`},
+		{Name: "NormalizeReplacement1", Value: "X.X"},
+		{Name: "NormalizePattern2", Value: `4\.16`},
+		{Name: "NormalizeReplacement2", Value: "X.X"},
+		{Name: "NormalizePattern3", Value: `4\.15`},
+		{Name: "NormalizeReplacement3", Value: "X.X"},
+		{Name: "NormalizePattern4", Value: `-f\d+`},
+		{Name: "NormalizeReplacement4", Value: "-fXX"},
+		{Name: "IncludeJobs", Value: c.IncludeJobs},
+	}, filterParams)
+
+	// both the report and test details queries are restricted
+	queryString, _, params = c.getCommonTestStatusQuery()
+	assert.Contains(t, queryString, filter)
+	assert.Subset(t, params, filterParams)
+	queryString, _, params = c.getCommonJobRunTestStatusQuery()
+	assert.Contains(t, queryString, filter)
+	assert.Subset(t, params, filterParams)
+}
+
+type fakeTestRegressionLister []apitype.TestRegression
+
+func (f fakeTestRegressionLister) ListTestRegressions(_ context.Context, testID string) ([]apitype.TestRegression, error) {
+	regs := []apitype.TestRegression{}
+	for _, reg := range f {
+		if reg.TestID == testID {
+			regs = append(regs, reg)
+		}
+	}
+	return regs, nil
+}
+
+func Test_componentReportGenerator_TestRegressionHistory(t *testing.T) {
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	closed := func(opened time.Time) bigquery.NullTimestamp {
+		return bigquery.NullTimestamp{Timestamp: opened.Add(7 * 24 * time.Hour), Valid: true}
+	}
+	awsOVN := []apitype.ComponentReportVariant{{Key: "Platform", Value: "aws"}, {Key: "Network", Value: "ovn"}}
+	generator := componentReportGenerator{regressionLister: fakeTestRegressionLister{
+		{RegressionID: "third", Release: "4.16", TestID: "test-1", Opened: jan.AddDate(0, 6, 0), Variants: awsOVN},
+		{RegressionID: "first", Release: "4.15", TestID: "test-1", Opened: jan, Closed: closed(jan), Variants: awsOVN},
+		{RegressionID: "other-test", Release: "4.15", TestID: "test-2", Opened: jan, Variants: awsOVN},
+		{
+			RegressionID: "second",
+			Release:      "4.15",
+			TestID:       "test-1",
+			Opened:       jan.AddDate(0, 2, 0),
+			Closed:       closed(jan.AddDate(0, 2, 0)),
+			Variants:     []apitype.ComponentReportVariant{{Key: "Network", Value: "ovn"}, {Key: "Platform", Value: "aws"}},
+		},
+		{RegressionID: "other-variant", Release: "4.15", TestID: "test-1", Opened: jan, Variants: []apitype.ComponentReportVariant{{Key: "Platform", Value: "gcp"}, {Key: "Network", Value: "ovn"}}},
+		{RegressionID: "fewer-variants", Release: "4.15", TestID: "test-1", Opened: jan, Variants: []apitype.ComponentReportVariant{{Key: "Platform", Value: "aws"}}},
+	}}
+
+	history, err := generator.TestRegressionHistory(context.Background(), "test-1", map[string]string{"Platform": "aws", "Network": "ovn"})
+	assert.NoError(t, err)
+	ids := []string{}
+	for _, reg := range history {
+		ids = append(ids, reg.RegressionID)
+	}
+	assert.Equal(t, []string{"first", "second", "third"}, ids)
+
+	history, err = generator.TestRegressionHistory(context.Background(), "test-3", map[string]string{"Platform": "aws", "Network": "ovn"})
+	assert.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func Test_componentReportGenerator_regressionStatuses(t *testing.T) {
+	testOn := func(id string) apitype.ComponentTestIdentification {
+		return apitype.ComponentTestIdentification{TestID: id, Platform: "aws", Arch: "amd64", Network: "ovn", Upgrade: "upgrade-micro", FlatVariants: "standard"}
+	}
+	regression := func(regressionID, testID string) apitype.TestRegression {
+		return apitype.TestRegression{
+			RegressionID: regressionID,
+			Release:      "4.16",
+			TestID:       testID,
+			Variants: []apitype.ComponentReportVariant{
+				{Key: "Platform", Value: "aws"},
+				{Key: "Architecture", Value: "amd64"},
+				{Key: "Network", Value: "ovn"},
+				{Key: "Upgrade", Value: "upgrade-micro"},
+				{Key: "Variant", Value: "standard"},
+			},
+		}
+	}
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+	baseStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		testOn("1"): {TestName: "test 1", TotalCount: 1000, SuccessCount: 950},
+		testOn("2"): {TestName: "test 2", TotalCount: 1000, SuccessCount: 950},
+		testOn("3"): {TestName: "test 3", TotalCount: 1000, SuccessCount: 950},
+	}
+	sampleStatus := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		testOn("1"): {TestName: "test 1", TotalCount: 100, SuccessCount: 95},
+		testOn("2"): {TestName: "test 2", TotalCount: 100, SuccessCount: 50},
+	}
+	generator := defaultComponentReportGenerator
+	generator.SampleRelease = apitype.ComponentReportRequestReleaseOptions{Release: "4.16"}
+	report := generator.generateComponentTestReport(baseStatus, sampleStatus, []apitype.TestRegression{
+		regression("cleared", "1"),
+		regression("regressed", "2"),
+		regression("no-sample", "3"),
+		regression("no-data", "4"),
+	})
+	assert.Equal(t, map[string]apitype.ComponentReportStatus{
+		"cleared":   apitype.NotSignificant,
+		"regressed": apitype.ExtremeRegression,
+		"no-sample": apitype.MissingSample,
+	}, report.RegressionStatuses)
+
+	report = generator.generateComponentTestReport(map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		testOn("1"): {TestName: "test 1", TotalCount: 1000, SuccessCount: 950},
+	}, map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{
+		testOn("1"): {TestName: "test 1", TotalCount: 100, SuccessCount: 95},
+	}, []apitype.TestRegression{})
+	assert.Nil(t, report.RegressionStatuses, "without tracked regressions there is nothing to report")
+}
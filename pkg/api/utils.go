@@ -77,6 +77,9 @@ func getDataFromCacheOrGenerate[T any](c cache.Cache, cacheOptions cache.Request
 			}
 			log.Infof("cache miss for cache key: %s", string(cacheKey))
 		}
+		if cacheOptions.PeekOnly {
+			return defaultVal, []error{cache.ErrCacheMiss}
+		}
 		result, errs := generateFn()
 		if len(errs) == 0 {
 			cr, err := json.Marshal(result)
@@ -97,6 +100,9 @@ func getDataFromCacheOrGenerate[T any](c cache.Cache, cacheOptions cache.Request
 		return result, errs
 	}
 
+	if cacheOptions.PeekOnly {
+		return defaultVal, []error{cache.ErrCacheMiss}
+	}
 	return generateFn()
 }
 
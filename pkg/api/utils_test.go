@@ -0,0 +1,76 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/apis/cache"
+)
+
+type fakeCache struct {
+	items map[string][]byte
+}
+
+func (f *fakeCache) Get(key string) ([]byte, error) {
+	if item, ok := f.items[key]; ok {
+		return item, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (f *fakeCache) Set(key string, content []byte, _ time.Duration) error {
+	f.items[key] = content
+	return nil
+}
+
+func TestGetDataFromCacheOrGeneratePeekOnly(t *testing.T) {
+	type data struct {
+		Value string
+	}
+	cacheKey := GetPrefixedCacheKey("peek~", data{Value: "key"})
+
+	tests := []struct {
+		name          string
+		cached        bool
+		expected      data
+		expectedError error
+	}{
+		{
+			name:     "hit",
+			cached:   true,
+			expected: data{Value: "cached"},
+		},
+		{
+			name:          "miss",
+			expectedError: cache.ErrCacheMiss,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &fakeCache{items: map[string][]byte{}}
+			if tt.cached {
+				key, err := cacheKey.GetCacheKey()
+				assert.NoError(t, err)
+				c.items[string(key)] = []byte(`{"Value":"cached"}`)
+			}
+			generated := false
+			generateFn := func() (data, []error) {
+				generated = true
+				return data{Value: "generated"}, nil
+			}
+
+			result, errs := getDataFromCacheOrGenerate(c, cache.RequestOptions{PeekOnly: true}, cacheKey, generateFn, data{})
+			assert.False(t, generated, "peek only requests must not generate data")
+			assert.Equal(t, tt.expected, result)
+			if tt.expectedError != nil {
+				assert.Equal(t, []error{tt.expectedError}, errs)
+				assert.Empty(t, c.items, "peek only requests must not populate the cache")
+			} else {
+				assert.Empty(t, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,964 @@
+package api
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/civil"
+)
+
+// Merge combines the counts of the same test reported by another query, such as a
+// different shard of the same report. Capabilities and variants are unioned.
+func (s ComponentTestStatus) Merge(other ComponentTestStatus) ComponentTestStatus {
+	merged := s
+	merged.TotalCount += other.TotalCount
+	merged.SuccessCount += other.SuccessCount
+	merged.FlakeCount += other.FlakeCount
+	// distinct jobs may be counted by both parts, the larger count is the safe lower bound
+	if other.FailingJobCount > merged.FailingJobCount {
+		merged.FailingJobCount = other.FailingJobCount
+	}
+	if other.SuiteCount > merged.SuiteCount {
+		merged.SuiteCount = other.SuiteCount
+	}
+	merged.Capabilities = unionStrings(s.Capabilities, other.Capabilities)
+	merged.Variants = unionStrings(s.Variants, other.Variants)
+	return merged
+}
+
+// MergeReportTestStatus merges the base and sample status of reports generated by sharded
+// queries. Tests present in several shards are combined with ComponentTestStatus.Merge, and
+// the result carries the latest GeneratedAt of all parts.
+func MergeReportTestStatus(parts ...ComponentReportTestStatus) ComponentReportTestStatus {
+	merged := ComponentReportTestStatus{
+		BaseStatus:   map[ComponentTestIdentification]ComponentTestStatus{},
+		SampleStatus: map[ComponentTestIdentification]ComponentTestStatus{},
+	}
+	for _, part := range parts {
+		mergeTestStatusMap(merged.BaseStatus, part.BaseStatus)
+		mergeTestStatusMap(merged.SampleStatus, part.SampleStatus)
+		if part.GeneratedAt != nil && (merged.GeneratedAt == nil || part.GeneratedAt.After(*merged.GeneratedAt)) {
+			merged.GeneratedAt = part.GeneratedAt
+		}
+	}
+	return merged
+}
+
+func mergeTestStatusMap(dst, src map[ComponentTestIdentification]ComponentTestStatus) {
+	for key, status := range src {
+		if existing, ok := dst[key]; ok {
+			dst[key] = existing.Merge(status)
+			continue
+		}
+		dst[key] = status
+	}
+}
+
+// unionStrings returns the elements of a followed by those of b not already present, preserving order.
+func unionStrings(a, b []string) []string {
+	if len(b) == 0 {
+		return a
+	}
+	seen := make(map[string]bool, len(a)+len(b))
+	result := make([]string, 0, len(a)+len(b))
+	for _, v := range append(append([]string{}, a...), b...) {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// CompareReleaseHealth counts the regressed tests, including triaged ones, in two reports
+// and the net change between them.
+func CompareReleaseHealth(a, b ComponentReport) HealthComparison {
+	comparison := HealthComparison{
+		RegressionsA: countRegressionsByStatus(a),
+		RegressionsB: countRegressionsByStatus(b),
+		NetChange:    map[ComponentReportStatus]int{},
+	}
+	for status, count := range comparison.RegressionsA {
+		comparison.TotalA += count
+		comparison.NetChange[status] -= count
+	}
+	for status, count := range comparison.RegressionsB {
+		comparison.TotalB += count
+		comparison.NetChange[status] += count
+	}
+	for status, change := range comparison.NetChange {
+		if change == 0 {
+			delete(comparison.NetChange, status)
+		}
+	}
+	comparison.TotalNetChange = comparison.TotalB - comparison.TotalA
+	return comparison
+}
+
+// countRegressionsByStatus counts each regressed test in a column once, even when it is
+// listed under several rows because it belongs to multiple capabilities.
+func countRegressionsByStatus(report ComponentReport) map[ComponentReportStatus]int {
+	type regressionKey struct {
+		testID string
+		column ComponentReportColumnIdentification
+	}
+	seen := map[regressionKey]bool{}
+	counts := map[ComponentReportStatus]int{}
+	count := func(summary ComponentReportTestSummary) {
+		key := regressionKey{testID: summary.TestID, column: summary.ComponentReportColumnIdentification}
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		counts[summary.Status]++
+	}
+	for _, row := range report.Rows {
+		for _, column := range row.Columns {
+			for _, regressedTest := range column.RegressedTests {
+				count(regressedTest)
+			}
+			for _, triagedIncident := range column.TriagedIncidents {
+				count(triagedIncident.ComponentReportTestSummary)
+			}
+		}
+	}
+	return counts
+}
+
+// UniquePRCount counts the distinct pull requests behind the sample job runs. Runs not
+// associated with a pull request are not counted.
+func (s ComponentJobRunTestReportStatus) UniquePRCount() int {
+	type pullRequest struct {
+		org, repo, number string
+	}
+	prs := map[pullRequest]bool{}
+	for _, rows := range s.SampleStatus {
+		for _, row := range rows {
+			if row.PRNumber == "" {
+				continue
+			}
+			prs[pullRequest{org: row.PROrg, repo: row.PRRepo, number: row.PRNumber}] = true
+		}
+	}
+	return len(prs)
+}
+
+// promRegressionSeverities are the severity label values of the exposition, by status.
+var promRegressionSeverities = []struct {
+	status   ComponentReportStatus
+	severity string
+}{
+	{ExtremeRegression, "extreme"},
+	{SignificantRegression, "significant"},
+	{ExtremeTriagedRegression, "extreme_triaged"},
+	{SignificantTriagedRegression, "significant_triaged"},
+}
+
+// WritePromExposition writes the number of regressed tests of each component by severity as gauges
+// in the Prometheus text exposition format, labelled with the given view. Tests are counted once per
+// column, as in CompareReleaseHealth. Every component of the report is written with every severity,
+// so series drop to zero rather than disappear once regressions clear.
+func (r ComponentReport) WritePromExposition(w io.Writer, view string) error {
+	type regressionKey struct {
+		testID string
+		column ComponentReportColumnIdentification
+	}
+	components := []string{}
+	seen := map[string]map[regressionKey]bool{}
+	counts := map[string]map[ComponentReportStatus]int{}
+	for _, row := range r.Rows {
+		if counts[row.Component] == nil {
+			components = append(components, row.Component)
+			seen[row.Component] = map[regressionKey]bool{}
+			counts[row.Component] = map[ComponentReportStatus]int{}
+		}
+		count := func(summary ComponentReportTestSummary) {
+			key := regressionKey{testID: summary.TestID, column: summary.ComponentReportColumnIdentification}
+			if seen[row.Component][key] {
+				return
+			}
+			seen[row.Component][key] = true
+			counts[row.Component][summary.Status]++
+		}
+		for _, column := range row.Columns {
+			for _, regressedTest := range column.RegressedTests {
+				count(regressedTest)
+			}
+			for _, triagedIncident := range column.TriagedIncidents {
+				count(triagedIncident.ComponentReportTestSummary)
+			}
+		}
+	}
+	sort.Strings(components)
+
+	if _, err := fmt.Fprint(w, "# HELP sippy_component_readiness_report_regressions Number of regressed tests per component and severity\n"+
+		"# TYPE sippy_component_readiness_report_regressions gauge\n"); err != nil {
+		return err
+	}
+	for _, component := range components {
+		for _, s := range promRegressionSeverities {
+			if _, err := fmt.Fprintf(w, "sippy_component_readiness_report_regressions{view=\"%s\",component=\"%s\",severity=\"%s\"} %d\n",
+				promLabelEscaper.Replace(view), promLabelEscaper.Replace(component), s.severity, counts[component][s.status]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// promLabelEscaper escapes label values for the text exposition format.
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WriteCSV writes one row per job run of the test details, sample runs before base runs within
+// each job. The first column names the release of the run, as a job can have a different number
+// of runs, or none at all, in the sample and the base.
+func (d ComponentReportTestDetails) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"release", "job_name", "job_run_id", "job_url", "start_time", "success_count", "failure_count", "flake_count"}); err != nil {
+		return err
+	}
+	for _, jobStats := range d.JobStats {
+		for _, side := range []struct {
+			release string
+			runs    []ComponentReportTestDetailsJobRunStats
+		}{
+			{release: d.SampleStats.Release, runs: jobStats.SampleJobRunStats},
+			{release: d.BaseStats.Release, runs: jobStats.BaseJobRunStats},
+		} {
+			for _, run := range side.runs {
+				startTime := ""
+				if !run.StartTime.IsZero() {
+					startTime = run.StartTime.String()
+				}
+				if err := writer.Write([]string{
+					side.release,
+					jobStats.JobName,
+					run.JobRunID,
+					run.JobURL,
+					startTime,
+					strconv.Itoa(run.TestStats.SuccessCount),
+					strconv.Itoa(run.TestStats.FailureCount),
+					strconv.Itoa(run.TestStats.FlakeCount),
+				}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Onset returns the start of the earliest failing sample run of the test, the likely onset of a
+// regression. False is returned when no sample run with a known start time failed.
+func (d ComponentReportTestDetails) Onset() (time.Time, bool) {
+	var onset time.Time
+	found := false
+	for _, jobStats := range d.JobStats {
+		for _, run := range jobStats.SampleJobRunStats {
+			if run.TestStats.FailureCount == 0 || run.StartTime.IsZero() {
+				continue
+			}
+			started := run.StartTime.In(time.UTC)
+			if !found || started.Before(onset) {
+				onset = started
+				found = true
+			}
+		}
+	}
+	return onset, found
+}
+
+// IsSignificanceTest returns true when a significance test, so a p-value, decided the status.
+func (d DecidingFactor) IsSignificanceTest() bool {
+	return d == DecidingFactorFisher || d == DecidingFactorChiSquared || d == DecidingFactorBayesian
+}
+
+// WithVariantAliases returns the identification with each variant value replaced by its alias,
+// see ComponentReportRequestVariantOptions.VariantAliases, so the keys of renamed variants match.
+func (s ComponentTestIdentification) WithVariantAliases(aliases map[string]map[string]string) ComponentTestIdentification {
+	alias := func(variant, value string) string {
+		if canonical, ok := aliases[variant][value]; ok {
+			return canonical
+		}
+		return value
+	}
+	s.Platform = alias("platform", s.Platform)
+	s.Arch = alias("arch", s.Arch)
+	s.Network = alias("network", s.Network)
+	s.Upgrade = alias("upgrade", s.Upgrade)
+	s.FlatVariants = alias("variant", s.FlatVariants)
+	return s
+}
+
+// ReleaseForVariants resolves the release options to use for a cell with the given variants,
+// applying the first matching override. The result never carries overrides of its own.
+func (r ComponentReportRequestReleaseOptions) ReleaseForVariants(platform, arch, network, upgrade string) ComponentReportRequestReleaseOptions {
+	variants := map[string]string{
+		"platform": platform,
+		"arch":     arch,
+		"network":  network,
+		"upgrade":  upgrade,
+	}
+	for _, override := range r.VariantOverrides {
+		if value, ok := variants[override.Variant]; ok && value != "" && value == override.Value {
+			return ComponentReportRequestReleaseOptions{
+				Release:   override.Release,
+				Start:     override.Start,
+				End:       override.End,
+				MaxRunAge: r.MaxRunAge,
+			}
+		}
+	}
+	return ComponentReportRequestReleaseOptions{
+		Release:   r.Release,
+		Start:     r.Start,
+		End:       r.End,
+		MaxRunAge: r.MaxRunAge,
+	}
+}
+
+// ResolveRollingBaseline returns the release options with Start and End set to the
+// RollingBaseline immediately preceding sampleStart, so the basis is queried like any fixed
+// window. Options without a RollingBaseline are returned unchanged.
+func (r ComponentReportRequestReleaseOptions) ResolveRollingBaseline(sampleStart time.Time) ComponentReportRequestReleaseOptions {
+	if r.RollingBaseline <= 0 {
+		return r
+	}
+	r.Start = sampleStart.Add(-r.RollingBaseline)
+	r.End = sampleStart
+	return r
+}
+
+// RollingBaselineWindow returns the window a RollingBaseline resolved to, nil without one.
+func (r ComponentReportRequestReleaseOptions) RollingBaselineWindow() *ReleaseWindow {
+	if r.RollingBaseline <= 0 {
+		return nil
+	}
+	return &ReleaseWindow{Start: r.Start, End: r.End}
+}
+
+// EffectiveStart is the start of the window after applying MaxRunAge.
+func (r ComponentReportRequestReleaseOptions) EffectiveStart() time.Time {
+	if r.MaxRunAge > 0 {
+		if oldest := r.End.Add(-r.MaxRunAge); oldest.After(r.Start) {
+			return oldest
+		}
+	}
+	return r.Start
+}
+
+// variantValues returns the variant key and value pairs of a column in display order.
+func (c ComponentReportColumnIdentification) variantValues() [][2]string {
+	return [][2]string{
+		{"platform", c.Platform},
+		{"arch", c.Arch},
+		{"network", c.Network},
+		{"upgrade", c.Upgrade},
+		{"variant", c.Variant},
+		{"suite", c.Suite},
+	}
+}
+
+// VariantOverlap returns the variant key and value pairs the two variant sets share, and how
+// similar the sets are as the Jaccard index of their pairs: the shared pairs over all distinct
+// pairs. A key with different values in each set counts as two distinct pairs. Two empty sets
+// share nothing and score zero.
+func VariantOverlap(a, b map[string]string) (map[string]string, float64) {
+	shared := map[string]string{}
+	for key, value := range a {
+		if other, ok := b[key]; ok && other == value {
+			shared[key] = value
+		}
+	}
+	union := len(a) + len(b) - len(shared)
+	if union == 0 {
+		return shared, 0
+	}
+	return shared, float64(len(shared)) / float64(union)
+}
+
+// DistinguishingVariants returns, for each column, the values of only those variants that
+// differ somewhere across the set, in platform, arch, network, upgrade, variant order. The
+// remaining variants are common to all columns and can be shown once.
+func DistinguishingVariants(cols []ComponentReportColumnIdentification) map[ComponentReportColumnIdentification][]string {
+	distinctValues := map[string]map[string]bool{}
+	for _, col := range cols {
+		for _, kv := range col.variantValues() {
+			if distinctValues[kv[0]] == nil {
+				distinctValues[kv[0]] = map[string]bool{}
+			}
+			distinctValues[kv[0]][kv[1]] = true
+		}
+	}
+
+	result := map[ComponentReportColumnIdentification][]string{}
+	for _, col := range cols {
+		values := []string{}
+		for _, kv := range col.variantValues() {
+			if len(distinctValues[kv[0]]) > 1 {
+				values = append(values, kv[1])
+			}
+		}
+		result[col] = values
+	}
+	return result
+}
+
+// RequiredCoverage reports which of the required variant combinations have at least one cell
+// with sample data for the component. Combinations use the same keys as the columns: platform,
+// arch, network, upgrade and variant. Unknown keys never match.
+func (r ComponentReport) RequiredCoverage(component string, required []map[string]string) (covered, missing []map[string]string) {
+	tested := []ComponentReportColumnIdentification{}
+	for _, row := range r.Rows {
+		if row.Component != component {
+			continue
+		}
+		for _, column := range row.Columns {
+			if column.Status != MissingSample && column.Status != MissingBasisAndSample {
+				tested = append(tested, column.ComponentReportColumnIdentification)
+			}
+		}
+	}
+
+	for _, combination := range required {
+		found := false
+		for _, column := range tested {
+			if column.matchesVariants(combination) {
+				found = true
+				break
+			}
+		}
+		if found {
+			covered = append(covered, combination)
+		} else {
+			missing = append(missing, combination)
+		}
+	}
+	return covered, missing
+}
+
+// MinimumBasisRunsFor returns the minimum basis runs for a cell, taking the most specific
+// match of MinimumBasisRunsByVariant, or MinimumBasisRuns when nothing matches. Of equally
+// specific matches the lowest minimum wins. Pairs without an = never match.
+func (o ComponentReportRequestAdvancedOptions) MinimumBasisRunsFor(column ComponentReportColumnIdentification) int {
+	minimum := o.MinimumBasisRuns
+	specificity := 0
+	for key, runs := range o.MinimumBasisRunsByVariant {
+		variants := map[string]string{}
+		for _, pair := range strings.Split(key, ",") {
+			kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+			if len(kv) != 2 {
+				// an impossible variant so the key never matches
+				kv = []string{"", pair}
+			}
+			variants[kv[0]] = kv[1]
+		}
+		if !column.matchesVariants(variants) {
+			continue
+		}
+		if len(variants) > specificity || (len(variants) == specificity && runs < minimum) {
+			minimum = runs
+			specificity = len(variants)
+		}
+	}
+	return minimum
+}
+
+// MinimumFailureFor returns the minimum failures for the tests of a component, its entry of
+// MinimumFailureByComponent, or MinimumFailure when it has none.
+func (o ComponentReportRequestAdvancedOptions) MinimumFailureFor(component string) int {
+	if minimum, ok := o.MinimumFailureByComponent[component]; ok {
+		return minimum
+	}
+	return o.MinimumFailure
+}
+
+// ConfidenceFor returns the confidence for a cell, taking the first of the ConfidenceOverrides
+// matching the cell's variants, or Confidence when nothing matches.
+func (o ComponentReportRequestAdvancedOptions) ConfidenceFor(column ComponentReportColumnIdentification) int {
+	for _, override := range o.ConfidenceOverrides {
+		if column.matchesVariants(map[string]string{override.VariantName: override.VariantValue}) {
+			return override.Confidence
+		}
+	}
+	return o.Confidence
+}
+
+// matchesVariants returns true if the column has every one of the given variant values.
+func (c ComponentReportColumnIdentification) matchesVariants(variants map[string]string) bool {
+	values := map[string]string{}
+	for _, kv := range c.variantValues() {
+		values[kv[0]] = kv[1]
+	}
+	for key, value := range variants {
+		if columnValue, ok := values[key]; !ok || columnValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+// BucketedPassRateWithBands splits the time spanned by the job runs into equally sized buckets and
+// returns the pass rate of each with a Wilson score interval, z being the standard score of the
+// wanted confidence (1.96 for 95%). Flakes count as passes unless flakeAsFailure is set. Runs
+// without a start time are ignored.
+func BucketedPassRateWithBands(runs []ComponentJobRunTestStatusRow, buckets int, z float64, flakeAsFailure bool) []BandedPoint {
+	if buckets <= 0 {
+		return nil
+	}
+	var first, last time.Time
+	for _, run := range runs {
+		if !run.StartTime.IsValid() {
+			continue
+		}
+		started := run.StartTime.In(time.UTC)
+		if first.IsZero() || started.Before(first) {
+			first = started
+		}
+		if last.IsZero() || started.After(last) {
+			last = started
+		}
+	}
+	if first.IsZero() {
+		return nil
+	}
+
+	width := last.Sub(first) / time.Duration(buckets)
+	totals := make([]int, buckets)
+	passes := make([]int, buckets)
+	for _, run := range runs {
+		if !run.StartTime.IsValid() {
+			continue
+		}
+		bucket := buckets - 1
+		if width > 0 {
+			bucket = int(run.StartTime.In(time.UTC).Sub(first) / width)
+			if bucket >= buckets {
+				// the latest run is the end of the last bucket
+				bucket = buckets - 1
+			}
+		}
+		totals[bucket] += run.TotalCount
+		passes[bucket] += run.SuccessCount
+		if !flakeAsFailure {
+			passes[bucket] += run.FlakeCount
+		}
+	}
+
+	points := make([]BandedPoint, 0, buckets)
+	for i := 0; i < buckets; i++ {
+		points = append(points, bandedPoint(first.Add(width*time.Duration(i)), first.Add(width*time.Duration(i+1)), totals[i], passes[i], z))
+	}
+	return points
+}
+
+// regressionPatternStep is the smallest change of pass rate between buckets, or over the whole
+// series, that ClassifyRegressionPattern considers meaningful.
+const regressionPatternStep = 0.1
+
+// ClassifyRegressionPattern classifies the shape of a bucketed pass rate series, oldest bucket
+// first, with rates between 0 and 1. Empty buckets can be passed as NaN and are skipped. A series
+// changing direction by at least regressionPatternStep twice or more is intermittent. Otherwise a
+// series ending at least regressionPatternStep below where it started is sudden when a single step
+// accounts for two thirds of the decline, gradual when it does not. Anything else, including an
+// improving series or one with fewer than three buckets, is stable.
+func ClassifyRegressionPattern(bucketed []float64) RegressionPattern {
+	rates := make([]float64, 0, len(bucketed))
+	for _, rate := range bucketed {
+		if !math.IsNaN(rate) {
+			rates = append(rates, rate)
+		}
+	}
+	if len(rates) < 3 {
+		return RegressionPatternStable
+	}
+
+	reversals := 0
+	lastDirection := 0.0
+	largestDrop := 0.0
+	for i := 1; i < len(rates); i++ {
+		step := rates[i] - rates[i-1]
+		if -step > largestDrop {
+			largestDrop = -step
+		}
+		if math.Abs(step) < regressionPatternStep {
+			continue
+		}
+		direction := math.Copysign(1, step)
+		if lastDirection != 0 && direction != lastDirection {
+			reversals++
+		}
+		lastDirection = direction
+	}
+	if reversals >= 2 {
+		return RegressionPatternIntermittent
+	}
+
+	decline := rates[0] - rates[len(rates)-1]
+	switch {
+	case decline < regressionPatternStep:
+		return RegressionPatternStable
+	case largestDrop >= decline*2/3:
+		return RegressionPatternSudden
+	default:
+		return RegressionPatternGradual
+	}
+}
+
+// bucketGranularityAutoDailyLimit is the longest window BucketGranularityAuto uses daily buckets for.
+const bucketGranularityAutoDailyLimit = 28 * 24 * time.Hour
+
+// BucketWidth returns the width of the buckets for a window of the given length.
+func (g BucketGranularity) BucketWidth(window time.Duration) time.Duration {
+	switch g {
+	case BucketGranularityDay:
+		return 24 * time.Hour
+	case BucketGranularityWeek:
+		return 7 * 24 * time.Hour
+	case BucketGranularityAuto:
+		if window <= bucketGranularityAutoDailyLimit {
+			return 24 * time.Hour
+		}
+		return 7 * 24 * time.Hour
+	}
+	return 0
+}
+
+// PassRateSeries splits the window from start to end into buckets of the given width, counted from
+// start, and returns the pass rate of each with a Wilson score interval as BucketedPassRateWithBands
+// does. The last bucket ends at end, so may be shorter. Runs outside the window are ignored.
+func PassRateSeries(runs []ComponentJobRunTestStatusRow, start, end time.Time, width time.Duration, z float64, flakeAsFailure bool) []BandedPoint {
+	if width <= 0 || !end.After(start) {
+		return nil
+	}
+	buckets := int((end.Sub(start) + width - 1) / width)
+	totals := make([]int, buckets)
+	passes := make([]int, buckets)
+	for _, run := range runs {
+		if !run.StartTime.IsValid() {
+			continue
+		}
+		started := run.StartTime.In(time.UTC)
+		if started.Before(start) || !started.Before(end) {
+			continue
+		}
+		bucket := int(started.Sub(start) / width)
+		totals[bucket] += run.TotalCount
+		passes[bucket] += run.SuccessCount
+		if !flakeAsFailure {
+			passes[bucket] += run.FlakeCount
+		}
+	}
+
+	points := make([]BandedPoint, 0, buckets)
+	for i := 0; i < buckets; i++ {
+		bucketEnd := start.Add(width * time.Duration(i+1))
+		if bucketEnd.After(end) {
+			bucketEnd = end
+		}
+		points = append(points, bandedPoint(start.Add(width*time.Duration(i)), bucketEnd, totals[i], passes[i], z))
+	}
+	return points
+}
+
+// bandedPoint returns the point for a bucket with the given number of runs and passes.
+func bandedPoint(start, end time.Time, total, passes int, z float64) BandedPoint {
+	point := BandedPoint{
+		Start: civil.DateTimeOf(start),
+		End:   civil.DateTimeOf(end),
+		Total: total,
+	}
+	if total == 0 {
+		point.Empty = true
+	} else {
+		point.PassRate = float64(passes) / float64(total)
+		point.Low, point.High = wilsonInterval(passes, total, z)
+	}
+	return point
+}
+
+// wilsonInterval returns the bounds of the Wilson score interval of a pass rate.
+func wilsonInterval(passes, total int, z float64) (float64, float64) {
+	n := float64(total)
+	p := float64(passes) / n
+	denominator := 1 + z*z/n
+	center := (p + z*z/(2*n)) / denominator
+	margin := z * math.Sqrt(p*(1-p)/n+z*z/(4*n*n)) / denominator
+	return math.Max(0, center-margin), math.Min(1, center+margin)
+}
+
+// ToColumnTree nests the report columns by the variant keys in order, such as platform then
+// network, using the keys of the column identification. Children keep the order the columns
+// have in the report. Keys a column does not have are grouped under an empty value.
+func (r ComponentReport) ToColumnTree(order []string) ColumnTree {
+	root := ColumnTree{}
+	if len(r.Rows) == 0 {
+		return root
+	}
+	// every row has the same columns in the same order
+	for i, column := range r.Rows[0].Columns {
+		values := map[string]string{}
+		for _, kv := range column.variantValues() {
+			values[kv[0]] = kv[1]
+		}
+		node := &root
+		for _, key := range order {
+			node = node.child(key, values[key])
+		}
+		leaf := ColumnTreeLeaf{ComponentReportColumnIdentification: column.ComponentReportColumnIdentification}
+		for _, row := range r.Rows {
+			leaf.Statuses = append(leaf.Statuses, row.Columns[i].Status)
+		}
+		node.Leaves = append(node.Leaves, leaf)
+	}
+	return root
+}
+
+// child returns the child node for the variant value, adding it if needed.
+func (t *ColumnTree) child(key, value string) *ColumnTree {
+	for _, child := range t.Children {
+		if child.Value == value {
+			return child
+		}
+	}
+	child := &ColumnTree{Key: key, Value: value}
+	t.Children = append(t.Children, child)
+	return child
+}
+
+// RegressionCountsByVariantValue counts, for each value of the variant key, the cells whose
+// status is a regression, including triaged ones, across every row. Values without regressions
+// are included with a count of zero. The key must be part of the column grouping, nil is
+// returned when no column has a value for it.
+func (r ComponentReport) RegressionCountsByVariantValue(key string) map[string]int {
+	var counts map[string]int
+	for _, row := range r.Rows {
+		for _, column := range row.Columns {
+			value := ""
+			for _, kv := range column.variantValues() {
+				if kv[0] == key {
+					value = kv[1]
+				}
+			}
+			if value == "" {
+				continue
+			}
+			if counts == nil {
+				counts = map[string]int{}
+			}
+			if _, ok := counts[value]; !ok {
+				counts[value] = 0
+			}
+			if column.Status <= SignificantTriagedRegression {
+				counts[value]++
+			}
+		}
+	}
+	return counts
+}
+
+// PValueHistogram buckets the p-values retained in the cells of the report, see IncludePValues,
+// into bins of equal width over [0, 1]. A well calibrated report has a roughly uniform histogram,
+// while a pile up at either end points at a systematic bias in the comparison. A test is counted
+// once for each cell it belongs to. Nil is returned for fewer than one bin.
+func (r ComponentReport) PValueHistogram(bins int) []int {
+	if bins < 1 {
+		return nil
+	}
+	histogram := make([]int, bins)
+	for _, row := range r.Rows {
+		for _, column := range row.Columns {
+			for _, p := range column.PValues {
+				bin := int(p * float64(bins))
+				// a p-value of exactly one belongs to the last bin
+				if bin >= bins {
+					bin = bins - 1
+				}
+				if bin < 0 {
+					bin = 0
+				}
+				histogram[bin]++
+			}
+		}
+	}
+	return histogram
+}
+
+// WeightedRegressionLoad sums the weight of the status of every regressed and triaged test in
+// the report into a single number to compare against an alert threshold, so one extreme
+// regression and many minor ones can page consistently. A test is counted once per column even
+// when several rows include it, statuses without a weight count as zero.
+func (r ComponentReport) WeightedRegressionLoad(weights map[ComponentReportStatus]float64) float64 {
+	type regressionKey struct {
+		testID string
+		column ComponentReportColumnIdentification
+	}
+	seen := map[regressionKey]bool{}
+	load := 0.0
+	add := func(summary ComponentReportTestSummary) {
+		key := regressionKey{testID: summary.TestID, column: summary.ComponentReportColumnIdentification}
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		load += weights[summary.Status]
+	}
+	for _, row := range r.Rows {
+		for _, column := range row.Columns {
+			for _, regressedTest := range column.RegressedTests {
+				add(regressedTest)
+			}
+			for _, triagedIncident := range column.TriagedIncidents {
+				add(triagedIncident.ComponentReportTestSummary)
+			}
+		}
+	}
+	return load
+}
+
+// regressionSeverityWeights weigh the regressed statuses for ConfidenceWeightedSeverity.
+var regressionSeverityWeights = map[ComponentReportStatus]float64{
+	ExtremeRegression:     2,
+	SignificantRegression: 1,
+}
+
+// ConfidenceWeightedSeverity averages the severity of the regressed tests of a component, each
+// weighted by the confidence of its regression, one minus its fisher exact p-value. Borderline
+// regressions so count for less than clear ones. A test is counted once per column even when
+// several rows of the component include it. Zero is returned without regressed tests.
+func (r ComponentReport) ConfidenceWeightedSeverity(component string) float64 {
+	type regressionKey struct {
+		testID string
+		column ComponentReportColumnIdentification
+	}
+	seen := map[regressionKey]bool{}
+	total := 0.0
+	count := 0
+	for _, row := range r.Rows {
+		if row.Component != component {
+			continue
+		}
+		for _, column := range row.Columns {
+			for _, regressedTest := range column.RegressedTests {
+				key := regressionKey{testID: regressedTest.TestID, column: regressedTest.ComponentReportColumnIdentification}
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				total += (1 - regressedTest.FisherExact) * regressionSeverityWeights[regressedTest.Status]
+				count++
+			}
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return total / float64(count)
+}
+
+// UntestedComponents returns the components of allComponents without any row in the report, in
+// the order given. Unlike cells missing a sample, these components had no test results at all.
+func (r ComponentReport) UntestedComponents(allComponents []string) []string {
+	tested := map[string]bool{}
+	for _, row := range r.Rows {
+		tested[row.Component] = true
+	}
+	untested := []string{}
+	for _, component := range allComponents {
+		if tested[component] {
+			continue
+		}
+		// only list a component given more than once the first time
+		tested[component] = true
+		untested = append(untested, component)
+	}
+	return untested
+}
+
+// RegressionsByArchitecture counts the regressed cells, including triaged ones, for each
+// architecture, as RegressionCountsByVariantValue does. Architecture must be part of the column
+// grouping, nil is returned otherwise.
+func (r ComponentReport) RegressionsByArchitecture() map[string]int {
+	return r.RegressionCountsByVariantValue("arch")
+}
+
+// ExpectedFalsePositives estimates how many cells of the report would be flagged as regressed by
+// chance alone when comparing at the given confidence, treating each comparable cell as an
+// independent test. Cells missing their basis or sample were not compared and are not counted.
+func (r ComponentReport) ExpectedFalsePositives(confidence int) float64 {
+	comparable, _ := r.countComparableCells()
+	return float64(comparable) * (1 - float64(confidence)/100)
+}
+
+// Completeness is the fraction of cells of the report that were compared, that is not missing
+// their basis, their sample or both. A report without cells is not complete at all.
+func (r ComponentReport) Completeness() float64 {
+	comparable, total := r.countComparableCells()
+	if total == 0 {
+		return 0
+	}
+	return float64(comparable) / float64(total)
+}
+
+// countComparableCells counts the cells of every row with both a basis and a sample, and all cells.
+func (r ComponentReport) countComparableCells() (int, int) {
+	comparable, total := 0, 0
+	for _, row := range r.Rows {
+		for _, column := range row.Columns {
+			total++
+			switch column.Status {
+			case MissingBasis, MissingSample, MissingBasisAndSample:
+			default:
+				comparable++
+			}
+		}
+	}
+	return comparable, total
+}
+
+// ReleaseReady combines the reports of the required views, keyed by view name, into a single
+// readiness gate. Every test in a cell, regressed or triaged, whose status is one of failOn is a
+// blocker, listed once in the order of the required views. A required view without a report
+// also makes the release not ready, though it has no blockers to list.
+func ReleaseReady(reports map[string]ComponentReport, requiredViews []string, failOn []ComponentReportStatus) (bool, []ComponentReportTestIdentification) {
+	failing := map[ComponentReportStatus]bool{}
+	for _, status := range failOn {
+		failing[status] = true
+	}
+
+	ready := true
+	blockers := []ComponentReportTestIdentification{}
+	seen := map[ComponentReportTestIdentification]bool{}
+	block := func(summary ComponentReportTestSummary) {
+		if !failing[summary.Status] || seen[summary.ComponentReportTestIdentification] {
+			return
+		}
+		seen[summary.ComponentReportTestIdentification] = true
+		blockers = append(blockers, summary.ComponentReportTestIdentification)
+	}
+	for _, view := range requiredViews {
+		report, ok := reports[view]
+		if !ok {
+			ready = false
+			continue
+		}
+		for _, row := range report.Rows {
+			for _, column := range row.Columns {
+				for _, regressedTest := range column.RegressedTests {
+					block(regressedTest)
+				}
+				for _, triagedIncident := range column.TriagedIncidents {
+					block(triagedIncident.ComponentReportTestSummary)
+				}
+			}
+		}
+	}
+	return ready && len(blockers) == 0, blockers
+}
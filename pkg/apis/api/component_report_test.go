@@ -0,0 +1,1407 @@
+package api
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeReportTestStatus(t *testing.T) {
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	aws := ComponentTestIdentification{TestID: "1", Platform: "aws"}
+	gcp := ComponentTestIdentification{TestID: "1", Platform: "gcp"}
+
+	tests := []struct {
+		name     string
+		parts    []ComponentReportTestStatus
+		expected ComponentReportTestStatus
+	}{
+		{
+			name: "disjoint shards",
+			parts: []ComponentReportTestStatus{
+				{
+					BaseStatus:   map[ComponentTestIdentification]ComponentTestStatus{aws: {TestName: "test", TotalCount: 10, SuccessCount: 9}},
+					SampleStatus: map[ComponentTestIdentification]ComponentTestStatus{aws: {TestName: "test", TotalCount: 5, SuccessCount: 5}},
+					GeneratedAt:  &newer,
+				},
+				{
+					BaseStatus:   map[ComponentTestIdentification]ComponentTestStatus{gcp: {TestName: "test", TotalCount: 20, SuccessCount: 18, FlakeCount: 1}},
+					SampleStatus: map[ComponentTestIdentification]ComponentTestStatus{},
+					GeneratedAt:  &older,
+				},
+			},
+			expected: ComponentReportTestStatus{
+				BaseStatus: map[ComponentTestIdentification]ComponentTestStatus{
+					aws: {TestName: "test", TotalCount: 10, SuccessCount: 9},
+					gcp: {TestName: "test", TotalCount: 20, SuccessCount: 18, FlakeCount: 1},
+				},
+				SampleStatus: map[ComponentTestIdentification]ComponentTestStatus{aws: {TestName: "test", TotalCount: 5, SuccessCount: 5}},
+				GeneratedAt:  &newer,
+			},
+		},
+		{
+			name: "overlapping shards",
+			parts: []ComponentReportTestStatus{
+				{
+					BaseStatus: map[ComponentTestIdentification]ComponentTestStatus{
+						aws: {TestName: "test", Capabilities: []string{"cap1"}, Variants: []string{"v1"}, TotalCount: 10, SuccessCount: 8, FlakeCount: 1},
+					},
+					GeneratedAt: &older,
+				},
+				{
+					BaseStatus: map[ComponentTestIdentification]ComponentTestStatus{
+						aws: {TestName: "test", Capabilities: []string{"cap1", "cap2"}, Variants: []string{"v2"}, TotalCount: 5, SuccessCount: 4},
+					},
+					SampleStatus: map[ComponentTestIdentification]ComponentTestStatus{gcp: {TestName: "test", TotalCount: 3, SuccessCount: 3}},
+					GeneratedAt:  &newer,
+				},
+			},
+			expected: ComponentReportTestStatus{
+				BaseStatus: map[ComponentTestIdentification]ComponentTestStatus{
+					aws: {TestName: "test", Capabilities: []string{"cap1", "cap2"}, Variants: []string{"v1", "v2"}, TotalCount: 15, SuccessCount: 12, FlakeCount: 1},
+				},
+				SampleStatus: map[ComponentTestIdentification]ComponentTestStatus{gcp: {TestName: "test", TotalCount: 3, SuccessCount: 3}},
+				GeneratedAt:  &newer,
+			},
+		},
+		{
+			name: "no parts",
+			expected: ComponentReportTestStatus{
+				BaseStatus:   map[ComponentTestIdentification]ComponentTestStatus{},
+				SampleStatus: map[ComponentTestIdentification]ComponentTestStatus{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MergeReportTestStatus(tt.parts...))
+		})
+	}
+}
+
+func TestCompareReleaseHealth(t *testing.T) {
+	aws := ComponentReportColumnIdentification{Platform: "aws", Network: "ovn"}
+	gcp := ComponentReportColumnIdentification{Platform: "gcp", Network: "ovn"}
+	regressed := func(testID string, column ComponentReportColumnIdentification, status ComponentReportStatus) ComponentReportTestSummary {
+		return ComponentReportTestSummary{
+			ComponentReportTestIdentification: ComponentReportTestIdentification{
+				ComponentReportRowIdentification:    ComponentReportRowIdentification{TestID: testID},
+				ComponentReportColumnIdentification: column,
+			},
+			Status: status,
+		}
+	}
+
+	older := ComponentReport{
+		Rows: []ComponentReportRow{
+			{
+				ComponentReportRowIdentification: ComponentReportRowIdentification{Component: "component 1"},
+				Columns: []ComponentReportColumn{
+					{
+						ComponentReportColumnIdentification: aws,
+						Status:                              ExtremeRegression,
+						RegressedTests: []ComponentReportTestSummary{
+							regressed("1", aws, ExtremeRegression),
+							regressed("2", aws, SignificantRegression),
+						},
+					},
+				},
+			},
+		},
+	}
+	newer := ComponentReport{
+		Rows: []ComponentReportRow{
+			{
+				ComponentReportRowIdentification: ComponentReportRowIdentification{Component: "component 1", Capability: "cap1"},
+				Columns: []ComponentReportColumn{
+					{
+						ComponentReportColumnIdentification: aws,
+						Status:                              SignificantRegression,
+						RegressedTests: []ComponentReportTestSummary{
+							regressed("2", aws, SignificantRegression),
+						},
+					},
+					{
+						ComponentReportColumnIdentification: gcp,
+						Status:                              SignificantRegression,
+						RegressedTests: []ComponentReportTestSummary{
+							regressed("2", gcp, SignificantRegression),
+						},
+						TriagedIncidents: []ComponentReportTriageIncidentSummary{
+							{ComponentReportTestSummary: regressed("3", gcp, SignificantTriagedRegression)},
+						},
+					},
+				},
+			},
+			{
+				// the same regression listed under a second capability is only counted once
+				ComponentReportRowIdentification: ComponentReportRowIdentification{Component: "component 1", Capability: "cap2"},
+				Columns: []ComponentReportColumn{
+					{
+						ComponentReportColumnIdentification: aws,
+						Status:                              SignificantRegression,
+						RegressedTests: []ComponentReportTestSummary{
+							regressed("2", aws, SignificantRegression),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	comparison := CompareReleaseHealth(older, newer)
+	assert.Equal(t, map[ComponentReportStatus]int{ExtremeRegression: 1, SignificantRegression: 1}, comparison.RegressionsA)
+	assert.Equal(t, map[ComponentReportStatus]int{SignificantRegression: 2, SignificantTriagedRegression: 1}, comparison.RegressionsB)
+	assert.Equal(t, map[ComponentReportStatus]int{ExtremeRegression: -1, SignificantRegression: 1, SignificantTriagedRegression: 1}, comparison.NetChange)
+	assert.Equal(t, 2, comparison.TotalA)
+	assert.Equal(t, 3, comparison.TotalB)
+	assert.Equal(t, 1, comparison.TotalNetChange)
+
+	same := CompareReleaseHealth(newer, newer)
+	assert.Empty(t, same.NetChange)
+	assert.Equal(t, 0, same.TotalNetChange)
+}
+
+func TestReleaseForVariants(t *testing.T) {
+	start415 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	start414 := time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC)
+	baseRelease := ComponentReportRequestReleaseOptions{
+		Release: "4.15",
+		Start:   start415,
+		End:     start415.AddDate(0, 1, 0),
+		VariantOverrides: []ComponentReportReleaseOverride{
+			{Variant: "arch", Value: "arm64", Release: "4.14", Start: start414, End: start414.AddDate(0, 1, 0)},
+			{Variant: "platform", Value: "metal", Release: "4.13"},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		platform        string
+		arch            string
+		expectedRelease string
+	}{
+		{
+			name:            "amd64 uses the default basis",
+			platform:        "aws",
+			arch:            "amd64",
+			expectedRelease: "4.15",
+		},
+		{
+			name:            "arm64 uses its override",
+			platform:        "aws",
+			arch:            "arm64",
+			expectedRelease: "4.14",
+		},
+		{
+			name:            "first matching override wins",
+			platform:        "metal",
+			arch:            "arm64",
+			expectedRelease: "4.14",
+		},
+		{
+			name:            "unspecified variants do not match",
+			expectedRelease: "4.15",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := baseRelease.ReleaseForVariants(tt.platform, tt.arch, "ovn", "upgrade-micro")
+			assert.Equal(t, tt.expectedRelease, release.Release)
+			assert.Empty(t, release.VariantOverrides)
+		})
+	}
+
+	arm64 := baseRelease.ReleaseForVariants("aws", "arm64", "ovn", "upgrade-micro")
+	assert.Equal(t, start414, arm64.Start)
+	assert.Equal(t, start414.AddDate(0, 1, 0), arm64.End)
+}
+
+func TestResolveRollingBaseline(t *testing.T) {
+	sampleStart := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	fixed := ComponentReportRequestReleaseOptions{
+		Release: "4.16",
+		Start:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:     time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+	assert.Equal(t, fixed, fixed.ResolveRollingBaseline(sampleStart))
+	assert.Nil(t, fixed.RollingBaselineWindow())
+
+	rolling := ComponentReportRequestReleaseOptions{
+		Release:         "4.16",
+		RollingBaseline: 30 * 24 * time.Hour,
+	}
+	resolved := rolling.ResolveRollingBaseline(sampleStart)
+	assert.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), resolved.Start)
+	assert.Equal(t, sampleStart, resolved.End)
+	assert.Equal(t, &ReleaseWindow{Start: resolved.Start, End: resolved.End}, resolved.RollingBaselineWindow())
+	// the resolved window is used like any fixed one
+	assert.Equal(t, resolved.Start, resolved.ReleaseForVariants("aws", "amd64", "ovn", "upgrade-micro").Start)
+}
+
+func TestDistinguishingVariants(t *testing.T) {
+	awsOVN := ComponentReportColumnIdentification{Platform: "aws", Arch: "amd64", Network: "ovn", Upgrade: "upgrade-micro", Variant: "standard"}
+	awsSDN := ComponentReportColumnIdentification{Platform: "aws", Arch: "amd64", Network: "sdn", Upgrade: "upgrade-micro", Variant: "standard"}
+	gcpOVN := ComponentReportColumnIdentification{Platform: "gcp", Arch: "amd64", Network: "ovn", Upgrade: "upgrade-micro", Variant: "standard"}
+	awsNoUpgrade := ComponentReportColumnIdentification{Platform: "aws", Arch: "amd64", Network: "ovn", Variant: "standard"}
+
+	tests := []struct {
+		name     string
+		cols     []ComponentReportColumnIdentification
+		expected map[ComponentReportColumnIdentification][]string
+	}{
+		{
+			name: "only network differs",
+			cols: []ComponentReportColumnIdentification{awsOVN, awsSDN},
+			expected: map[ComponentReportColumnIdentification][]string{
+				awsOVN: {"ovn"},
+				awsSDN: {"sdn"},
+			},
+		},
+		{
+			name: "platform and network differ",
+			cols: []ComponentReportColumnIdentification{awsOVN, awsSDN, gcpOVN},
+			expected: map[ComponentReportColumnIdentification][]string{
+				awsOVN: {"aws", "ovn"},
+				awsSDN: {"aws", "sdn"},
+				gcpOVN: {"gcp", "ovn"},
+			},
+		},
+		{
+			name: "a missing variant differs from a set one",
+			cols: []ComponentReportColumnIdentification{awsOVN, awsNoUpgrade},
+			expected: map[ComponentReportColumnIdentification][]string{
+				awsOVN:       {"upgrade-micro"},
+				awsNoUpgrade: {""},
+			},
+		},
+		{
+			name: "single column has nothing to distinguish",
+			cols: []ComponentReportColumnIdentification{awsOVN},
+			expected: map[ComponentReportColumnIdentification][]string{
+				awsOVN: {},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DistinguishingVariants(tt.cols))
+		})
+	}
+}
+
+func TestRequiredCoverage(t *testing.T) {
+	report := ComponentReport{
+		Rows: []ComponentReportRow{
+			{
+				ComponentReportRowIdentification: ComponentReportRowIdentification{Component: "networking"},
+				Columns: []ComponentReportColumn{
+					{ComponentReportColumnIdentification: ComponentReportColumnIdentification{Platform: "aws", Arch: "amd64", Network: "ovn"}, Status: NotSignificant},
+					{ComponentReportColumnIdentification: ComponentReportColumnIdentification{Platform: "gcp", Arch: "amd64", Network: "ovn"}, Status: ExtremeRegression},
+					{ComponentReportColumnIdentification: ComponentReportColumnIdentification{Platform: "azure", Arch: "amd64", Network: "ovn"}, Status: MissingBasisAndSample},
+					{ComponentReportColumnIdentification: ComponentReportColumnIdentification{Platform: "metal", Arch: "amd64", Network: "ovn"}, Status: MissingSample},
+				},
+			},
+			{
+				ComponentReportRowIdentification: ComponentReportRowIdentification{Component: "storage"},
+				Columns: []ComponentReportColumn{
+					{ComponentReportColumnIdentification: ComponentReportColumnIdentification{Platform: "vsphere", Arch: "amd64", Network: "ovn"}, Status: NotSignificant},
+				},
+			},
+		},
+	}
+	aws := map[string]string{"platform": "aws"}
+	gcpOVN := map[string]string{"platform": "gcp", "network": "ovn"}
+	azure := map[string]string{"platform": "azure"}
+	metal := map[string]string{"platform": "metal"}
+	vsphere := map[string]string{"platform": "vsphere"}
+	awsSDN := map[string]string{"platform": "aws", "network": "sdn"}
+	unknownKey := map[string]string{"cloud": "aws"}
+
+	covered, missing := report.RequiredCoverage("networking", []map[string]string{aws, gcpOVN, azure, metal, vsphere, awsSDN, unknownKey})
+	assert.Equal(t, []map[string]string{aws, gcpOVN}, covered)
+	assert.Equal(t, []map[string]string{azure, metal, vsphere, awsSDN, unknownKey}, missing)
+
+	covered, missing = report.RequiredCoverage("storage", []map[string]string{vsphere})
+	assert.Equal(t, []map[string]string{vsphere}, covered)
+	assert.Empty(t, missing)
+
+	covered, missing = report.RequiredCoverage("unknown", []map[string]string{aws})
+	assert.Empty(t, covered)
+	assert.Equal(t, []map[string]string{aws}, missing)
+}
+
+func TestBucketedPassRateWithBands(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	run := func(day, total, success, flake int) ComponentJobRunTestStatusRow {
+		return ComponentJobRunTestStatusRow{
+			StartTime:    civil.DateTimeOf(start.Add(time.Duration(day) * 24 * time.Hour)),
+			TotalCount:   total,
+			SuccessCount: success,
+			FlakeCount:   flake,
+		}
+	}
+	// every bucket has an 80% pass rate, but each has ten times the runs of the one before
+	runs := []ComponentJobRunTestStatusRow{
+		run(0, 10, 8, 0),
+		run(4, 100, 80, 0),
+		run(8, 1000, 800, 0),
+		{TotalCount: 1000, SuccessCount: 0},
+	}
+
+	points := BucketedPassRateWithBands(runs, 3, 1.96, false)
+	assert.Equal(t, 3, len(points))
+	previousWidth := 1.0
+	for i, point := range points {
+		assert.False(t, point.Empty)
+		assert.InDelta(t, 0.8, point.PassRate, 0.0001)
+		assert.Less(t, point.Low, point.PassRate)
+		assert.Greater(t, point.High, point.PassRate)
+		width := point.High - point.Low
+		assert.Less(t, width, previousWidth, "band of bucket %d should be narrower than the one before", i)
+		previousWidth = width
+	}
+	assert.Equal(t, civil.DateTimeOf(start), points[0].Start)
+	assert.InDelta(t, 0.4902, points[0].Low, 0.0001)
+	assert.InDelta(t, 0.9433, points[0].High, 0.0001)
+}
+
+func TestBucketedPassRateWithBandsEmptyBuckets(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	runs := []ComponentJobRunTestStatusRow{
+		{StartTime: civil.DateTimeOf(start), TotalCount: 10, SuccessCount: 5, FlakeCount: 5},
+		{StartTime: civil.DateTimeOf(start.Add(72 * time.Hour)), TotalCount: 10, SuccessCount: 10},
+	}
+
+	points := BucketedPassRateWithBands(runs, 3, 1.96, false)
+	assert.Equal(t, 3, len(points))
+	assert.False(t, points[0].Empty)
+	assert.Equal(t, 1.0, points[0].PassRate, "flakes count as passes")
+	assert.True(t, points[1].Empty)
+	assert.Equal(t, 0, points[1].Total)
+	assert.False(t, points[2].Empty)
+
+	points = BucketedPassRateWithBands(runs, 3, 1.96, true)
+	assert.Equal(t, 0.5, points[0].PassRate, "flakes count as failures")
+
+	assert.Nil(t, BucketedPassRateWithBands(nil, 3, 1.96, false))
+	assert.Nil(t, BucketedPassRateWithBands(runs, 0, 1.96, false))
+}
+
+func TestEffectiveStart(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		maxRunAge time.Duration
+		expected  time.Time
+	}{
+		{
+			name:     "no max run age",
+			expected: start,
+		},
+		{
+			name:      "max run age within the window",
+			maxRunAge: 7 * 24 * time.Hour,
+			expected:  time.Date(2024, 3, 24, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "max run age longer than the window",
+			maxRunAge: 60 * 24 * time.Hour,
+			expected:  start,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := ComponentReportRequestReleaseOptions{Release: "4.15", Start: start, End: end, MaxRunAge: tt.maxRunAge}
+			assert.Equal(t, tt.expected, release.EffectiveStart())
+			assert.Equal(t, tt.expected, release.ReleaseForVariants("aws", "amd64", "ovn", "").EffectiveStart())
+		})
+	}
+}
+
+func TestToColumnTree(t *testing.T) {
+	awsOVN := ComponentReportColumnIdentification{Platform: "aws", Arch: "amd64", Network: "ovn"}
+	awsSDN := ComponentReportColumnIdentification{Platform: "aws", Arch: "amd64", Network: "sdn"}
+	awsOVNArm := ComponentReportColumnIdentification{Platform: "aws", Arch: "arm64", Network: "ovn"}
+	gcpOVN := ComponentReportColumnIdentification{Platform: "gcp", Arch: "amd64", Network: "ovn"}
+	row := func(component string, statuses ...ComponentReportStatus) ComponentReportRow {
+		columns := []ComponentReportColumn{}
+		for i, column := range []ComponentReportColumnIdentification{awsOVN, awsSDN, awsOVNArm, gcpOVN} {
+			columns = append(columns, ComponentReportColumn{ComponentReportColumnIdentification: column, Status: statuses[i]})
+		}
+		return ComponentReportRow{ComponentReportRowIdentification: ComponentReportRowIdentification{Component: component}, Columns: columns}
+	}
+	report := ComponentReport{Rows: []ComponentReportRow{
+		row("component 1", ExtremeRegression, NotSignificant, NotSignificant, MissingSample),
+		row("component 2", NotSignificant, SignificantRegression, NotSignificant, NotSignificant),
+	}}
+
+	tree := report.ToColumnTree([]string{"platform", "network"})
+	expected := ColumnTree{Children: []*ColumnTree{
+		{
+			Key: "platform", Value: "aws",
+			Children: []*ColumnTree{
+				{
+					Key: "network", Value: "ovn",
+					Leaves: []ColumnTreeLeaf{
+						{ComponentReportColumnIdentification: awsOVN, Statuses: []ComponentReportStatus{ExtremeRegression, NotSignificant}},
+						{ComponentReportColumnIdentification: awsOVNArm, Statuses: []ComponentReportStatus{NotSignificant, NotSignificant}},
+					},
+				},
+				{
+					Key: "network", Value: "sdn",
+					Leaves: []ColumnTreeLeaf{
+						{ComponentReportColumnIdentification: awsSDN, Statuses: []ComponentReportStatus{NotSignificant, SignificantRegression}},
+					},
+				},
+			},
+		},
+		{
+			Key: "platform", Value: "gcp",
+			Children: []*ColumnTree{
+				{
+					Key: "network", Value: "ovn",
+					Leaves: []ColumnTreeLeaf{
+						{ComponentReportColumnIdentification: gcpOVN, Statuses: []ComponentReportStatus{MissingSample, NotSignificant}},
+					},
+				},
+			},
+		},
+	}}
+	assert.Equal(t, expected, tree)
+
+	flat := report.ToColumnTree(nil)
+	assert.Empty(t, flat.Children)
+	assert.Equal(t, 4, len(flat.Leaves), "without an order every column is a leaf of the root")
+
+	assert.Equal(t, ColumnTree{}, ComponentReport{}.ToColumnTree([]string{"platform"}))
+}
+
+func TestRegressionCountsByVariantValue(t *testing.T) {
+	columns := func(statuses ...ComponentReportStatus) []ComponentReportColumn {
+		platforms := []string{"aws", "gcp", "metal"}
+		result := []ComponentReportColumn{}
+		for i, status := range statuses {
+			result = append(result, ComponentReportColumn{
+				ComponentReportColumnIdentification: ComponentReportColumnIdentification{Platform: platforms[i%len(platforms)], Network: "ovn"},
+				Status:                              status,
+			})
+		}
+		return result
+	}
+	report := ComponentReport{
+		Rows: []ComponentReportRow{
+			{Columns: columns(ExtremeRegression, NotSignificant, MissingSample)},
+			{Columns: columns(SignificantRegression, SignificantTriagedRegression, RegressionWarning)},
+			{Columns: columns(NotSignificant, ExtremeTriagedRegression, SignificantImprovement)},
+		},
+	}
+
+	assert.Equal(t, map[string]int{"aws": 2, "gcp": 2, "metal": 0}, report.RegressionCountsByVariantValue("platform"))
+	assert.Equal(t, map[string]int{"ovn": 4}, report.RegressionCountsByVariantValue("network"))
+	assert.Nil(t, report.RegressionCountsByVariantValue("arch"), "arch is not part of the column grouping")
+	assert.Nil(t, report.RegressionCountsByVariantValue("cloud"))
+}
+
+func TestRegressionsByArchitecture(t *testing.T) {
+	column := func(arch, platform string, status ComponentReportStatus) ComponentReportColumn {
+		return ComponentReportColumn{
+			ComponentReportColumnIdentification: ComponentReportColumnIdentification{Platform: platform, Arch: arch},
+			Status:                              status,
+		}
+	}
+	report := ComponentReport{
+		Rows: []ComponentReportRow{
+			{Columns: []ComponentReportColumn{
+				column("amd64", "aws", ExtremeRegression),
+				column("amd64", "gcp", NotSignificant),
+				column("arm64", "aws", SignificantRegression),
+				column("arm64", "gcp", SignificantTriagedRegression),
+			}},
+			{Columns: []ComponentReportColumn{
+				column("amd64", "aws", RegressionWarning),
+				column("amd64", "gcp", NotSignificant),
+				column("arm64", "aws", ExtremeTriagedRegression),
+				column("arm64", "gcp", MissingBasis),
+			}},
+		},
+	}
+	assert.Equal(t, map[string]int{"amd64": 1, "arm64": 3}, report.RegressionsByArchitecture())
+
+	healthy := ComponentReport{Rows: []ComponentReportRow{{Columns: []ComponentReportColumn{
+		column("amd64", "aws", NotSignificant),
+		column("arm64", "aws", SignificantImprovement),
+	}}}}
+	assert.Equal(t, map[string]int{"amd64": 0, "arm64": 0}, healthy.RegressionsByArchitecture())
+
+	ungrouped := ComponentReport{Rows: []ComponentReportRow{{Columns: []ComponentReportColumn{
+		column("", "aws", ExtremeRegression),
+	}}}}
+	assert.Nil(t, ungrouped.RegressionsByArchitecture(), "arch is not part of the column grouping")
+}
+
+func TestReleaseReady(t *testing.T) {
+	testID := func(id, platform string) ComponentReportTestIdentification {
+		return ComponentReportTestIdentification{
+			ComponentReportRowIdentification:    ComponentReportRowIdentification{Component: "comp", TestID: id},
+			ComponentReportColumnIdentification: ComponentReportColumnIdentification{Platform: platform},
+		}
+	}
+	reportWith := func(regressed []ComponentReportTestSummary, triaged []ComponentReportTriageIncidentSummary) ComponentReport {
+		return ComponentReport{Rows: []ComponentReportRow{{Columns: []ComponentReportColumn{{
+			RegressedTests:   regressed,
+			TriagedIncidents: triaged,
+		}}}}}
+	}
+	clean := reportWith(nil, nil)
+	regressed := reportWith([]ComponentReportTestSummary{
+		{ComponentReportTestIdentification: testID("1", "aws"), Status: ExtremeRegression},
+		{ComponentReportTestIdentification: testID("2", "aws"), Status: SignificantRegression},
+	}, nil)
+	triaged := reportWith(nil, []ComponentReportTriageIncidentSummary{
+		{ComponentReportTestSummary: ComponentReportTestSummary{ComponentReportTestIdentification: testID("3", "gcp"), Status: ExtremeTriagedRegression}},
+	})
+	reports := map[string]ComponentReport{
+		"main":     clean,
+		"upgrades": regressed,
+		"triaged":  triaged,
+		"optional": regressed,
+	}
+
+	tests := []struct {
+		name             string
+		requiredViews    []string
+		failOn           []ComponentReportStatus
+		expectedReady    bool
+		expectedBlockers []ComponentReportTestIdentification
+	}{
+		{
+			name:             "all required views clean",
+			requiredViews:    []string{"main"},
+			failOn:           []ComponentReportStatus{ExtremeRegression, SignificantRegression},
+			expectedReady:    true,
+			expectedBlockers: []ComponentReportTestIdentification{},
+		},
+		{
+			name:             "one required view blocks",
+			requiredViews:    []string{"main", "upgrades"},
+			failOn:           []ComponentReportStatus{ExtremeRegression},
+			expectedReady:    false,
+			expectedBlockers: []ComponentReportTestIdentification{testID("1", "aws")},
+		},
+		{
+			name:             "blockers listed once across views",
+			requiredViews:    []string{"upgrades", "optional"},
+			failOn:           []ComponentReportStatus{ExtremeRegression, SignificantRegression},
+			expectedReady:    false,
+			expectedBlockers: []ComponentReportTestIdentification{testID("1", "aws"), testID("2", "aws")},
+		},
+		{
+			name:             "triaged only blocks when asked",
+			requiredViews:    []string{"main", "triaged"},
+			failOn:           []ComponentReportStatus{ExtremeRegression, SignificantRegression},
+			expectedReady:    true,
+			expectedBlockers: []ComponentReportTestIdentification{},
+		},
+		{
+			name:             "triaged blocks",
+			requiredViews:    []string{"triaged"},
+			failOn:           []ComponentReportStatus{ExtremeTriagedRegression},
+			expectedReady:    false,
+			expectedBlockers: []ComponentReportTestIdentification{testID("3", "gcp")},
+		},
+		{
+			name:             "missing required view",
+			requiredViews:    []string{"main", "unknown"},
+			failOn:           []ComponentReportStatus{ExtremeRegression},
+			expectedReady:    false,
+			expectedBlockers: []ComponentReportTestIdentification{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ready, blockers := ReleaseReady(reports, tt.requiredViews, tt.failOn)
+			assert.Equal(t, tt.expectedReady, ready)
+			assert.Equal(t, tt.expectedBlockers, blockers)
+		})
+	}
+}
+
+func TestBucketWidth(t *testing.T) {
+	day := 24 * time.Hour
+	week := 7 * day
+	assert.Equal(t, day, BucketGranularityDay.BucketWidth(60*day))
+	assert.Equal(t, week, BucketGranularityWeek.BucketWidth(10*day))
+	assert.Equal(t, day, BucketGranularityAuto.BucketWidth(28*day))
+	assert.Equal(t, week, BucketGranularityAuto.BucketWidth(29*day))
+	assert.Equal(t, time.Duration(0), BucketGranularity("").BucketWidth(10*day))
+}
+
+func TestPassRateSeries(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(10 * 24 * time.Hour)
+	run := func(at time.Duration, total, success int) ComponentJobRunTestStatusRow {
+		return ComponentJobRunTestStatusRow{
+			StartTime:    civil.DateTimeOf(start.Add(at)),
+			TotalCount:   total,
+			SuccessCount: success,
+		}
+	}
+	runs := []ComponentJobRunTestStatusRow{
+		run(-time.Hour, 1, 0),                 // before the window
+		run(0, 1, 1),                          // first bucket
+		run(7*24*time.Hour-time.Second, 1, 0), // last moment of the first bucket
+		run(7*24*time.Hour, 1, 1),             // first moment of the second bucket
+		run(10*24*time.Hour, 1, 0),            // at the end of the window
+	}
+
+	points := PassRateSeries(runs, start, end, 7*24*time.Hour, 1.96, false)
+	assert.Equal(t, 2, len(points))
+	assert.Equal(t, civil.DateTimeOf(start), points[0].Start)
+	assert.Equal(t, civil.DateTimeOf(start.Add(7*24*time.Hour)), points[0].End)
+	assert.Equal(t, 2, points[0].Total)
+	assert.Equal(t, 0.5, points[0].PassRate)
+	// the last bucket is cut short at the end of the window
+	assert.Equal(t, civil.DateTimeOf(start.Add(7*24*time.Hour)), points[1].Start)
+	assert.Equal(t, civil.DateTimeOf(end), points[1].End)
+	assert.Equal(t, 1, points[1].Total)
+	assert.Equal(t, 1.0, points[1].PassRate)
+
+	assert.Nil(t, PassRateSeries(runs, start, end, 0, 1.96, false))
+	assert.Nil(t, PassRateSeries(runs, end, start, 24*time.Hour, 1.96, false))
+}
+
+func TestUniquePRCount(t *testing.T) {
+	run := func(org, repo, number string) ComponentJobRunTestStatusRow {
+		return ComponentJobRunTestStatusRow{PROrg: org, PRRepo: repo, PRNumber: number, TotalCount: 1}
+	}
+	status := ComponentJobRunTestReportStatus{
+		BaseStatus: map[string][]ComponentJobRunTestStatusRow{
+			"pull-ci-openshift-origin-master-e2e-aws-ovn": {run("openshift", "origin", "99")},
+		},
+		SampleStatus: map[string][]ComponentJobRunTestStatusRow{
+			"pull-ci-openshift-origin-master-e2e-aws-ovn": {
+				run("openshift", "origin", "1"),
+				run("openshift", "origin", "1"),
+				run("openshift", "origin", "2"),
+				run("", "", ""),
+			},
+			"pull-ci-openshift-installer-master-e2e-aws-ovn": {
+				// same number, different repository
+				run("openshift", "installer", "1"),
+			},
+			"periodic-ci-openshift-release-master-ci-4.16-e2e-aws-ovn": {
+				run("", "", ""),
+			},
+		},
+	}
+	assert.Equal(t, 3, status.UniquePRCount())
+	assert.Equal(t, 0, ComponentJobRunTestReportStatus{}.UniquePRCount())
+}
+
+func TestWritePromExposition(t *testing.T) {
+	regressed := func(testID, platform string, status ComponentReportStatus) ComponentReportTestSummary {
+		return ComponentReportTestSummary{
+			ComponentReportTestIdentification: ComponentReportTestIdentification{
+				ComponentReportRowIdentification:    ComponentReportRowIdentification{TestID: testID},
+				ComponentReportColumnIdentification: ComponentReportColumnIdentification{Platform: platform},
+			},
+			Status: status,
+		}
+	}
+	report := ComponentReport{
+		Rows: []ComponentReportRow{
+			{
+				ComponentReportRowIdentification: ComponentReportRowIdentification{Component: "networking", Capability: "dns"},
+				Columns: []ComponentReportColumn{
+					{
+						ComponentReportColumnIdentification: ComponentReportColumnIdentification{Platform: "aws"},
+						RegressedTests: []ComponentReportTestSummary{
+							regressed("1", "aws", ExtremeRegression),
+							regressed("2", "aws", SignificantRegression),
+						},
+						TriagedIncidents: []ComponentReportTriageIncidentSummary{
+							{ComponentReportTestSummary: regressed("3", "aws", SignificantTriagedRegression)},
+						},
+					},
+				},
+			},
+			{
+				// the same test under another capability is only counted once
+				ComponentReportRowIdentification: ComponentReportRowIdentification{Component: "networking", Capability: "router"},
+				Columns: []ComponentReportColumn{
+					{
+						ComponentReportColumnIdentification: ComponentReportColumnIdentification{Platform: "aws"},
+						RegressedTests:                      []ComponentReportTestSummary{regressed("1", "aws", ExtremeRegression)},
+					},
+				},
+			},
+			{
+				ComponentReportRowIdentification: ComponentReportRowIdentification{Component: `storage "csi"`},
+				Columns: []ComponentReportColumn{
+					{ComponentReportColumnIdentification: ComponentReportColumnIdentification{Platform: "aws"}, Status: NotSignificant},
+				},
+			},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, report.WritePromExposition(buf, "4.16-main"))
+
+	parser := expfmt.TextParser{}
+	families, err := parser.TextToMetricFamilies(buf)
+	assert.NoError(t, err)
+	family, ok := families["sippy_component_readiness_report_regressions"]
+	assert.True(t, ok)
+	assert.Equal(t, "GAUGE", family.GetType().String())
+
+	values := map[[2]string]float64{}
+	for _, metric := range family.GetMetric() {
+		labels := map[string]string{}
+		for _, label := range metric.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+		assert.Equal(t, 3, len(labels))
+		assert.Equal(t, "4.16-main", labels["view"])
+		values[[2]string{labels["component"], labels["severity"]}] = metric.GetGauge().GetValue()
+	}
+	assert.Equal(t, map[[2]string]float64{
+		{"networking", "extreme"}:                1,
+		{"networking", "significant"}:            1,
+		{"networking", "extreme_triaged"}:        0,
+		{"networking", "significant_triaged"}:    1,
+		{`storage "csi"`, "extreme"}:             0,
+		{`storage "csi"`, "significant"}:         0,
+		{`storage "csi"`, "extreme_triaged"}:     0,
+		{`storage "csi"`, "significant_triaged"}: 0,
+	}, values)
+}
+
+func TestMinimumBasisRunsFor(t *testing.T) {
+	options := ComponentReportRequestAdvancedOptions{
+		MinimumBasisRuns: 20,
+		MinimumBasisRunsByVariant: map[string]int{
+			"platform=metal":             10,
+			"platform=metal,network=ovn": 5,
+			"arch=arm64":                 8,
+			"arch=ppc64le":               6,
+			"malformed":                  1,
+		},
+	}
+	tests := []struct {
+		name     string
+		column   ComponentReportColumnIdentification
+		expected int
+	}{
+		{
+			name:     "no match uses the global minimum",
+			column:   ComponentReportColumnIdentification{Platform: "aws", Arch: "amd64", Network: "ovn"},
+			expected: 20,
+		},
+		{
+			name:     "single match",
+			column:   ComponentReportColumnIdentification{Platform: "metal", Arch: "amd64", Network: "sdn"},
+			expected: 10,
+		},
+		{
+			name:     "most specific match wins",
+			column:   ComponentReportColumnIdentification{Platform: "metal", Arch: "amd64", Network: "ovn"},
+			expected: 5,
+		},
+		{
+			name:     "equally specific matches take the lowest",
+			column:   ComponentReportColumnIdentification{Platform: "metal", Arch: "arm64", Network: "sdn"},
+			expected: 8,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, options.MinimumBasisRunsFor(tt.column))
+		})
+	}
+}
+
+func TestPValueHistogram(t *testing.T) {
+	report := ComponentReport{Rows: []ComponentReportRow{
+		{Columns: []ComponentReportColumn{
+			{PValues: []float64{0, 0.01, 0.24}},
+			{PValues: []float64{0.25, 0.5}},
+		}},
+		{Columns: []ComponentReportColumn{
+			{},
+			{PValues: []float64{0.75, 0.99, 1}},
+		}},
+	}}
+	tests := []struct {
+		name     string
+		bins     int
+		expected []int
+	}{
+		{
+			name:     "no bins",
+			bins:     0,
+			expected: nil,
+		},
+		{
+			name:     "single bin",
+			bins:     1,
+			expected: []int{8},
+		},
+		{
+			name:     "bin edges go to the upper bin and one to the last",
+			bins:     4,
+			expected: []int{3, 1, 1, 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, report.PValueHistogram(tt.bins))
+		})
+	}
+	assert.Equal(t, []int{0, 0}, ComponentReport{}.PValueHistogram(2), "a report without p-values has empty bins")
+}
+
+func TestConfidenceFor(t *testing.T) {
+	options := ComponentReportRequestAdvancedOptions{
+		Confidence: 95,
+		ConfidenceOverrides: []VariantConfidenceOverride{
+			{VariantName: "platform", VariantValue: "metal", Confidence: 90},
+			{VariantName: "network", VariantValue: "sdn", Confidence: 85},
+		},
+	}
+	tests := []struct {
+		name     string
+		column   ComponentReportColumnIdentification
+		expected int
+	}{
+		{
+			name:     "no match uses the requested confidence",
+			column:   ComponentReportColumnIdentification{Platform: "aws", Arch: "amd64", Network: "ovn"},
+			expected: 95,
+		},
+		{
+			name:     "single match",
+			column:   ComponentReportColumnIdentification{Platform: "aws", Arch: "amd64", Network: "sdn"},
+			expected: 85,
+		},
+		{
+			name:     "first match wins",
+			column:   ComponentReportColumnIdentification{Platform: "metal", Arch: "amd64", Network: "sdn"},
+			expected: 90,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, options.ConfidenceFor(tt.column))
+		})
+	}
+}
+
+func TestComponentReportTestDetailsWriteCSV(t *testing.T) {
+	start := civil.DateTimeOf(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	details := ComponentReportTestDetails{
+		SampleStats: ComponentReportTestDetailsReleaseStats{Release: "4.16"},
+		BaseStats:   ComponentReportTestDetailsReleaseStats{Release: "4.15"},
+		JobStats: []ComponentReportTestDetailsJobStats{
+			{
+				JobName: "job-a",
+				SampleJobRunStats: []ComponentReportTestDetailsJobRunStats{
+					{JobURL: "https://prow/job-a/1", JobRunID: "1", StartTime: start, TestStats: ComponentReportTestDetailsTestStats{SuccessCount: 1}},
+					{JobURL: "https://prow/job-a/2", JobRunID: "2", TestStats: ComponentReportTestDetailsTestStats{FailureCount: 1}},
+				},
+				BaseJobRunStats: []ComponentReportTestDetailsJobRunStats{
+					{JobURL: "https://prow/job-a/0", JobRunID: "0", StartTime: start, TestStats: ComponentReportTestDetailsTestStats{FlakeCount: 1}},
+				},
+			},
+			{
+				// only ran in the base
+				JobName: "job, b",
+				BaseJobRunStats: []ComponentReportTestDetailsJobRunStats{
+					{JobURL: "https://prow/job-b/3", JobRunID: "3", TestStats: ComponentReportTestDetailsTestStats{SuccessCount: 2}},
+				},
+			},
+		},
+	}
+	buf := &bytes.Buffer{}
+	assert.NoError(t, details.WriteCSV(buf))
+	assert.Equal(t, `release,job_name,job_run_id,job_url,start_time,success_count,failure_count,flake_count
+4.16,job-a,1,https://prow/job-a/1,2024-01-02T03:04:05,1,0,0
+4.16,job-a,2,https://prow/job-a/2,,0,1,0
+4.15,job-a,0,https://prow/job-a/0,2024-01-02T03:04:05,0,0,1
+4.15,"job, b",3,https://prow/job-b/3,,2,0,0
+`, buf.String())
+}
+
+func TestWeightedRegressionLoad(t *testing.T) {
+	aws := ComponentReportColumnIdentification{Platform: "aws"}
+	gcp := ComponentReportColumnIdentification{Platform: "gcp"}
+	summary := func(testID string, column ComponentReportColumnIdentification, status ComponentReportStatus) ComponentReportTestSummary {
+		return ComponentReportTestSummary{
+			ComponentReportTestIdentification: ComponentReportTestIdentification{
+				ComponentReportRowIdentification:    ComponentReportRowIdentification{TestID: testID},
+				ComponentReportColumnIdentification: column,
+			},
+			Status: status,
+		}
+	}
+	report := ComponentReport{Rows: []ComponentReportRow{
+		{
+			ComponentReportRowIdentification: ComponentReportRowIdentification{Component: "a", Capability: "cap1"},
+			Columns: []ComponentReportColumn{
+				{ComponentReportColumnIdentification: aws, RegressedTests: []ComponentReportTestSummary{
+					summary("1", aws, ExtremeRegression),
+					summary("2", aws, SignificantRegression),
+				}},
+				{ComponentReportColumnIdentification: gcp, RegressedTests: []ComponentReportTestSummary{
+					summary("1", gcp, SignificantRegression),
+				}},
+			},
+		},
+		{
+			// the same test in another capability row is only counted once
+			ComponentReportRowIdentification: ComponentReportRowIdentification{Component: "a", Capability: "cap2"},
+			Columns: []ComponentReportColumn{
+				{ComponentReportColumnIdentification: aws, RegressedTests: []ComponentReportTestSummary{
+					summary("1", aws, ExtremeRegression),
+				}, TriagedIncidents: []ComponentReportTriageIncidentSummary{
+					{ComponentReportTestSummary: summary("3", aws, ExtremeTriagedRegression)},
+				}},
+			},
+		},
+	}}
+	tests := []struct {
+		name     string
+		weights  map[ComponentReportStatus]float64
+		expected float64
+	}{
+		{
+			name:     "no weights",
+			expected: 0,
+		},
+		{
+			name:     "count of regressions",
+			weights:  map[ComponentReportStatus]float64{ExtremeRegression: 1, SignificantRegression: 1, ExtremeTriagedRegression: 1},
+			expected: 4,
+		},
+		{
+			name:     "extreme weighs more, triaged less",
+			weights:  map[ComponentReportStatus]float64{ExtremeRegression: 5, SignificantRegression: 1, ExtremeTriagedRegression: 0.5},
+			expected: 7.5,
+		},
+		{
+			name:     "unweighted statuses count as zero",
+			weights:  map[ComponentReportStatus]float64{ExtremeRegression: 5},
+			expected: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, report.WeightedRegressionLoad(tt.weights))
+		})
+	}
+}
+
+func TestUntestedComponents(t *testing.T) {
+	report := ComponentReport{Rows: []ComponentReportRow{
+		{ComponentReportRowIdentification: ComponentReportRowIdentification{Component: "etcd", Capability: "cap1"}},
+		{ComponentReportRowIdentification: ComponentReportRowIdentification{Component: "etcd", Capability: "cap2"}},
+		{ComponentReportRowIdentification: ComponentReportRowIdentification{Component: "kube-apiserver"}},
+	}}
+	tests := []struct {
+		name          string
+		allComponents []string
+		expected      []string
+	}{
+		{
+			name:          "every component tested",
+			allComponents: []string{"kube-apiserver", "etcd"},
+			expected:      []string{},
+		},
+		{
+			name:          "component without rows",
+			allComponents: []string{"etcd", "Networking / router", "kube-apiserver", "Storage"},
+			expected:      []string{"Networking / router", "Storage"},
+		},
+		{
+			name:          "components given twice are listed once",
+			allComponents: []string{"Storage", "Storage"},
+			expected:      []string{"Storage"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, report.UntestedComponents(tt.allComponents))
+		})
+	}
+	assert.Equal(t, []string{"etcd"}, ComponentReport{}.UntestedComponents([]string{"etcd"}), "an empty report tests nothing")
+}
+
+func TestComponentReportTestDetailsOnset(t *testing.T) {
+	run := func(day, hour, failures int) ComponentReportTestDetailsJobRunStats {
+		stats := ComponentReportTestDetailsJobRunStats{TestStats: ComponentReportTestDetailsTestStats{SuccessCount: 1 - failures, FailureCount: failures}}
+		if day > 0 {
+			stats.StartTime = civil.DateTime{Date: civil.Date{Year: 2024, Month: 3, Day: day}, Time: civil.Time{Hour: hour}}
+		}
+		return stats
+	}
+	details := ComponentReportTestDetails{JobStats: []ComponentReportTestDetailsJobStats{
+		{
+			JobName:           "job-a",
+			SampleJobRunStats: []ComponentReportTestDetailsJobRunStats{run(9, 0, 0), run(10, 6, 1), run(0, 0, 1)},
+			// base failures are not part of a regression
+			BaseJobRunStats: []ComponentReportTestDetailsJobRunStats{run(1, 0, 1)},
+		},
+		{
+			JobName:           "job-b",
+			SampleJobRunStats: []ComponentReportTestDetailsJobRunStats{run(11, 0, 1), run(10, 2, 1)},
+		},
+	}}
+	onset, ok := details.Onset()
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2024, 3, 10, 2, 0, 0, 0, time.UTC), onset)
+
+	_, ok = ComponentReportTestDetails{JobStats: []ComponentReportTestDetailsJobStats{
+		{SampleJobRunStats: []ComponentReportTestDetailsJobRunStats{run(9, 0, 0), run(0, 0, 1)}},
+	}}.Onset()
+	assert.False(t, ok, "no failing run with a start time has no onset")
+}
+
+func TestExpectedFalsePositives(t *testing.T) {
+	report := func(statuses ...ComponentReportStatus) ComponentReport {
+		row := ComponentReportRow{}
+		for _, status := range statuses {
+			row.Columns = append(row.Columns, ComponentReportColumn{Status: status})
+		}
+		return ComponentReport{Rows: []ComponentReportRow{row, row}}
+	}
+	tests := []struct {
+		name       string
+		report     ComponentReport
+		confidence int
+		expected   float64
+	}{
+		{
+			name:       "empty report",
+			report:     ComponentReport{},
+			confidence: 95,
+			expected:   0,
+		},
+		{
+			name:       "every cell comparable",
+			report:     report(NotSignificant, SignificantRegression, SignificantImprovement, ExtremeTriagedRegression, RegressionWarning),
+			confidence: 95,
+			expected:   0.5,
+		},
+		{
+			name:       "cells missing data are not counted",
+			report:     report(NotSignificant, MissingBasis, MissingSample, MissingBasisAndSample),
+			confidence: 90,
+			expected:   0.2,
+		},
+		{
+			name:       "higher confidence",
+			report:     report(NotSignificant, NotSignificant, NotSignificant, NotSignificant, NotSignificant),
+			confidence: 99,
+			expected:   0.1,
+		},
+		{
+			name:       "full confidence",
+			report:     report(NotSignificant, ExtremeRegression),
+			confidence: 100,
+			expected:   0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, tt.report.ExpectedFalsePositives(tt.confidence), 1e-9)
+		})
+	}
+}
+
+func TestClassifyRegressionPattern(t *testing.T) {
+	tests := []struct {
+		name     string
+		bucketed []float64
+		expected RegressionPattern
+	}{
+		{
+			name:     "cliff",
+			bucketed: []float64{0.98, 0.97, 0.98, 0.6, 0.62, 0.61},
+			expected: RegressionPatternSudden,
+		},
+		{
+			name:     "steady decline",
+			bucketed: []float64{1.0, 0.92, 0.85, 0.77, 0.7, 0.62},
+			expected: RegressionPatternGradual,
+		},
+		{
+			name:     "oscillating",
+			bucketed: []float64{0.95, 0.6, 0.94, 0.55, 0.96, 0.9},
+			expected: RegressionPatternIntermittent,
+		},
+		{
+			name:     "noise",
+			bucketed: []float64{0.95, 0.93, 0.96, 0.94, 0.95},
+			expected: RegressionPatternStable,
+		},
+		{
+			name:     "improving",
+			bucketed: []float64{0.6, 0.7, 0.8, 0.9},
+			expected: RegressionPatternStable,
+		},
+		{
+			name:     "single dip recovered",
+			bucketed: []float64{0.95, 0.95, 0.5, 0.94, 0.95},
+			expected: RegressionPatternStable,
+		},
+		{
+			name:     "empty buckets skipped",
+			bucketed: []float64{0.97, math.NaN(), 0.98, math.NaN(), 0.5, 0.52},
+			expected: RegressionPatternSudden,
+		},
+		{
+			name:     "too few buckets",
+			bucketed: []float64{1.0, 0.2},
+			expected: RegressionPatternStable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ClassifyRegressionPattern(tt.bucketed))
+		})
+	}
+}
+
+func TestConfidenceWeightedSeverity(t *testing.T) {
+	aws := ComponentReportColumnIdentification{Platform: "aws"}
+	gcp := ComponentReportColumnIdentification{Platform: "gcp"}
+	regression := func(testID string, column ComponentReportColumnIdentification, status ComponentReportStatus, p float64) ComponentReportTestSummary {
+		return ComponentReportTestSummary{
+			ComponentReportTestIdentification: ComponentReportTestIdentification{
+				ComponentReportRowIdentification:    ComponentReportRowIdentification{TestID: testID},
+				ComponentReportColumnIdentification: column,
+			},
+			Status:      status,
+			FisherExact: p,
+		}
+	}
+	report := func(component string, regressions ...ComponentReportTestSummary) ComponentReport {
+		return ComponentReport{Rows: []ComponentReportRow{
+			{
+				ComponentReportRowIdentification: ComponentReportRowIdentification{Component: component},
+				Columns: []ComponentReportColumn{
+					{ComponentReportColumnIdentification: aws, RegressedTests: regressions},
+				},
+			},
+		}}
+	}
+
+	highConfidence := report("a",
+		regression("1", aws, SignificantRegression, 0.001),
+		regression("2", aws, SignificantRegression, 0.001))
+	borderline := report("a",
+		regression("1", aws, SignificantRegression, 0.001),
+		regression("2", aws, SignificantRegression, 0.049))
+	assert.InDelta(t, 0.999, highConfidence.ConfidenceWeightedSeverity("a"), 0.0001)
+	assert.InDelta(t, 0.975, borderline.ConfidenceWeightedSeverity("a"), 0.0001)
+	assert.Less(t, borderline.ConfidenceWeightedSeverity("a"), highConfidence.ConfidenceWeightedSeverity("a"),
+		"a borderline regression should dampen the severity")
+
+	extreme := report("a",
+		regression("1", aws, ExtremeRegression, 0.001),
+		regression("2", aws, SignificantRegression, 0.001))
+	assert.InDelta(t, 1.4985, extreme.ConfidenceWeightedSeverity("a"), 0.0001)
+
+	assert.Equal(t, 0.0, highConfidence.ConfidenceWeightedSeverity("b"), "other components have no regressions")
+	assert.Equal(t, 0.0, ComponentReport{}.ConfidenceWeightedSeverity("a"))
+
+	// the same regression in another row of the component is only counted once
+	duplicated := report("a", regression("1", aws, SignificantRegression, 0.001))
+	duplicated.Rows = append(duplicated.Rows, ComponentReportRow{
+		ComponentReportRowIdentification: ComponentReportRowIdentification{Component: "a", Capability: "cap2"},
+		Columns: []ComponentReportColumn{
+			{ComponentReportColumnIdentification: aws, RegressedTests: []ComponentReportTestSummary{regression("1", aws, SignificantRegression, 0.001)}},
+			{ComponentReportColumnIdentification: gcp, RegressedTests: []ComponentReportTestSummary{regression("1", gcp, SignificantRegression, 0.049)}},
+		},
+	})
+	assert.InDelta(t, 0.975, duplicated.ConfidenceWeightedSeverity("a"), 0.0001)
+}
+
+func TestCompleteness(t *testing.T) {
+	grid := func(rows ...[]ComponentReportStatus) ComponentReport {
+		report := ComponentReport{}
+		for _, statuses := range rows {
+			row := ComponentReportRow{}
+			for _, status := range statuses {
+				row.Columns = append(row.Columns, ComponentReportColumn{Status: status})
+			}
+			report.Rows = append(report.Rows, row)
+		}
+		return report
+	}
+	tests := []struct {
+		name     string
+		report   ComponentReport
+		expected float64
+	}{
+		{
+			name:     "no cells",
+			report:   ComponentReport{},
+			expected: 0,
+		},
+		{
+			name: "fully comparable",
+			report: grid(
+				[]ComponentReportStatus{NotSignificant, SignificantRegression},
+				[]ComponentReportStatus{SignificantImprovement, ExtremeTriagedRegression},
+			),
+			expected: 1,
+		},
+		{
+			name: "partly missing",
+			report: grid(
+				[]ComponentReportStatus{NotSignificant, MissingBasis, NotSignificant, MissingSample},
+				[]ComponentReportStatus{RegressionWarning, NotSignificant, MissingBasisAndSample, NotSignificant},
+			),
+			expected: 0.625,
+		},
+		{
+			name: "all missing",
+			report: grid(
+				[]ComponentReportStatus{MissingBasis, MissingSample},
+				[]ComponentReportStatus{MissingBasisAndSample, MissingBasis},
+			),
+			expected: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, tt.report.Completeness(), 1e-9)
+		})
+	}
+}
+
+func TestComponentTestIdentificationWithVariantAliases(t *testing.T) {
+	aliases := map[string]map[string]string{
+		"network": {"sdn": "ovn"},
+		"variant": {"standard,techpreview": "techpreview"},
+	}
+	sdn := ComponentTestIdentification{TestID: "1", Network: "sdn", Upgrade: "upgrade-micro", Arch: "amd64", Platform: "aws", FlatVariants: "standard"}
+	ovn := sdn
+	ovn.Network = "ovn"
+
+	assert.Equal(t, ovn, sdn.WithVariantAliases(aliases))
+	assert.Equal(t, ovn, ovn.WithVariantAliases(aliases), "canonical values should be left alone")
+	assert.Equal(t, sdn, sdn.WithVariantAliases(nil))
+
+	sdnKey, err := sdn.WithVariantAliases(aliases).MarshalText()
+	assert.NoError(t, err)
+	ovnKey, err := ovn.WithVariantAliases(aliases).MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, string(ovnKey), string(sdnKey))
+	assert.Equal(t, `{"test_id":"1","network":"ovn","upgrade":"upgrade-micro","arch":"amd64","platform":"aws","flat_variants":"standard"}`, string(sdnKey))
+
+	techPreview := sdn
+	techPreview.FlatVariants = "standard,techpreview"
+	key, err := techPreview.WithVariantAliases(aliases).MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"test_id":"1","network":"ovn","upgrade":"upgrade-micro","arch":"amd64","platform":"aws","flat_variants":"techpreview"}`, string(key))
+
+	var decoded ComponentTestIdentification
+	assert.NoError(t, decoded.UnmarshalText(key))
+	assert.Equal(t, techPreview.WithVariantAliases(aliases), decoded)
+}
+
+func TestVariantOverlap(t *testing.T) {
+	awsOVN := map[string]string{"Platform": "aws", "Network": "ovn", "Arch": "amd64"}
+	tests := []struct {
+		name           string
+		a, b           map[string]string
+		expectedShared map[string]string
+		expectedScore  float64
+	}{
+		{
+			name:           "identical",
+			a:              awsOVN,
+			b:              map[string]string{"Platform": "aws", "Network": "ovn", "Arch": "amd64"},
+			expectedShared: awsOVN,
+			expectedScore:  1,
+		},
+		{
+			name:           "partial",
+			a:              awsOVN,
+			b:              map[string]string{"Platform": "aws", "Network": "ovn", "Arch": "arm64"},
+			expectedShared: map[string]string{"Platform": "aws", "Network": "ovn"},
+			expectedScore:  0.5,
+		},
+		{
+			name:           "subset",
+			a:              awsOVN,
+			b:              map[string]string{"Platform": "aws"},
+			expectedShared: map[string]string{"Platform": "aws"},
+			expectedScore:  1.0 / 3.0,
+		},
+		{
+			name:           "disjoint",
+			a:              awsOVN,
+			b:              map[string]string{"Platform": "gcp", "Network": "sdn", "Upgrade": "minor"},
+			expectedShared: map[string]string{},
+			expectedScore:  0,
+		},
+		{
+			name:           "empty",
+			expectedShared: map[string]string{},
+			expectedScore:  0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shared, score := VariantOverlap(tt.a, tt.b)
+			assert.Equal(t, tt.expectedShared, shared)
+			assert.InDelta(t, tt.expectedScore, score, 1e-9)
+			_, reversed := VariantOverlap(tt.b, tt.a)
+			assert.InDelta(t, score, reversed, 1e-9, "the score should be symmetric")
+		})
+	}
+}
+
+func TestComponentReportStatusValues(t *testing.T) {
+	// the status numbers are part of the API, new statuses must not change existing ones
+	assert.Equal(t, map[ComponentReportStatus]int{
+		ExtremeRegression:            -5,
+		SignificantRegression:        -4,
+		ExtremeTriagedRegression:     -3,
+		SignificantTriagedRegression: -2,
+		MissingSample:                -1,
+		NotSignificant:               0,
+		MissingBasis:                 1,
+		MissingBasisAndSample:        2,
+		SignificantImprovement:       3,
+		RegressionWarning:            4,
+		HighFlakeRate:                5,
+	}, map[ComponentReportStatus]int{
+		ExtremeRegression:            int(ExtremeRegression),
+		SignificantRegression:        int(SignificantRegression),
+		ExtremeTriagedRegression:     int(ExtremeTriagedRegression),
+		SignificantTriagedRegression: int(SignificantTriagedRegression),
+		MissingSample:                int(MissingSample),
+		NotSignificant:               int(NotSignificant),
+		MissingBasis:                 int(MissingBasis),
+		MissingBasisAndSample:        int(MissingBasisAndSample),
+		SignificantImprovement:       int(SignificantImprovement),
+		RegressionWarning:            int(RegressionWarning),
+		HighFlakeRate:                int(HighFlakeRate),
+	})
+
+	for i := 1; i < len(statusesBySeverity); i++ {
+		assert.True(t, statusesBySeverity[i-1].WorseThan(statusesBySeverity[i]), "%d should be worse than %d", statusesBySeverity[i-1], statusesBySeverity[i])
+	}
+	assert.True(t, SignificantImprovement.WorseThan(ComponentReportStatus(42)), "unknown statuses should rank last")
+}
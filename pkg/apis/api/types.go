@@ -778,10 +778,50 @@ type RiskLevel struct {
 	Level int
 }
 
+// ComponentReportRequestOptions are all the options of a component report request, as resolved
+// from its parameters and the server configuration, such as the base window of a rolling baseline
+// and the group by of a grouping preset.
+type ComponentReportRequestOptions struct {
+	BaseRelease     ComponentReportRequestReleaseOptions
+	SampleRelease   ComponentReportRequestReleaseOptions
+	TestIDOptions   ComponentReportRequestTestIdentificationOptions
+	VariantOptions  ComponentReportRequestVariantOptions
+	ExcludeOptions  ComponentReportRequestExcludeOptions
+	AdvancedOptions ComponentReportRequestAdvancedOptions
+}
+
 type ComponentReportRequestReleaseOptions struct {
 	Release string
 	Start   time.Time
 	End     time.Time
+	// VariantOverrides select a different release for the tests run on a variant, such as an
+	// architecture that became generally available later. They are only used for the basis,
+	// the first override matching a test wins.
+	VariantOverrides []ComponentReportReleaseOverride `json:",omitempty"`
+	// MaxRunAge excludes runs that started more than this long before End, even if they are
+	// within the window. Zero includes the whole window. It is only used for the basis.
+	MaxRunAge time.Duration `json:",omitempty"`
+	// RollingBaseline makes the basis the window of this length immediately preceding the start
+	// of the sample instead of a fixed Start and End, see ResolveRollingBaseline. It is only used
+	// for the basis and does not apply to VariantOverrides.
+	RollingBaseline time.Duration `json:",omitempty"`
+}
+
+// ReleaseWindow is the concrete window a relative release window, such as a rolling baseline,
+// resolved to.
+type ReleaseWindow struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// ComponentReportReleaseOverride replaces the release used for tests whose Variant column
+// (platform, arch, network or upgrade) has the given Value.
+type ComponentReportReleaseOverride struct {
+	Variant string
+	Value   string
+	Release string
+	Start   time.Time
+	End     time.Time
 }
 
 type ComponentReportRequestTestIdentificationOptions struct {
@@ -790,6 +830,11 @@ type ComponentReportRequestTestIdentificationOptions struct {
 	// TestID is a unique identification for the test defined in the DB.
 	// It matches the test_id in the bigquery ci_analysis_us.junit table.
 	TestID string
+	// SampleSubWindowStart and SampleSubWindowEnd narrow the sample runs of test details to those
+	// started within them, such as the last few days, intersected with the sample release window.
+	// A zero time leaves that side of the window as is.
+	SampleSubWindowStart time.Time
+	SampleSubWindowEnd   time.Time
 }
 
 // ComponentReportRequestExcludeOptions group all the exclude options passed in the request.
@@ -803,12 +848,24 @@ type ComponentReportRequestExcludeOptions struct {
 }
 
 type ComponentReportRequestVariantOptions struct {
+	// GroupBy is a comma separated list of the variants, such as cloud and arch, that split each
+	// row into columns. Empty yields a single aggregate column per row.
 	GroupBy  string
 	Platform string
 	Upgrade  string
 	Arch     string
 	Network  string
 	Variant  string
+	// BaseArch compares the sample of Arch against the base of another architecture, such as an
+	// arm64 sample against an amd64 base. The base is relabeled as Arch so tests line up, and the
+	// columns carry both sides. Requires Arch. Test details are not cross compared.
+	BaseArch string `json:",omitempty"`
+	// Suite restricts the report to tests from a single test suite.
+	Suite string
+	// VariantAliases canonicalize renamed variant values, keyed by variant (platform, arch,
+	// network, upgrade or variant) then by the value to replace, so base and sample data from
+	// before and after a rename are compared as one. See ComponentTestIdentification.WithVariantAliases.
+	VariantAliases map[string]map[string]string `json:",omitempty"`
 }
 
 type ComponentReportRequestAdvancedOptions struct {
@@ -817,6 +874,146 @@ type ComponentReportRequestAdvancedOptions struct {
 	PityFactor       int
 	IgnoreMissing    bool
 	IgnoreDisruption bool
+	// ExcludeFirstPRRuns drops the earliest sample run of the test on each pull request,
+	// as the first run on a PR often fails for transient reasons. It opens the test details
+	// sample to presubmit jobs, which the sample otherwise leaves out.
+	ExcludeFirstPRRuns bool
+	// IgnoreInfraFailures drops the job runs that failed for CI infrastructure reasons from both
+	// base and sample of test details, so infrastructure incidents do not count against tests.
+	IgnoreInfraFailures bool
+	// ExtremePassRateFloor is a hard quality bar, any sample pass percentage below it is an
+	// ExtremeRegression regardless of the basis, even for tests missing a basis entirely.
+	// Zero disables the floor.
+	ExtremePassRateFloor int
+	// MinimumFailingJobs requires sample failures to come from at least this many distinct
+	// jobs before a regression is reported, so one bad job failing repeatedly is not flagged.
+	MinimumFailingJobs int
+	// IncludeCapabilityStatuses adds a per capability breakdown to the cells of rows
+	// that aggregate several capabilities, such as the component rows of the main report.
+	IncludeCapabilityStatuses bool
+	// IncludeJobs restricts base and sample to the named jobs, given by their normalized
+	// names with releases replaced by X.X.
+	IncludeJobs []string `json:",omitempty"`
+	// IncludeImprovementsInList lists significantly improved tests in the ImprovedTests of their
+	// cell, alongside the regressed tests.
+	IncludeImprovementsInList bool
+	// ExcludedJobRunURLs drops the given job runs from both base and sample of test details, such
+	// as known bad infrastructure runs. Runs are matched on the prow job run ID that ends the URL,
+	// so prow and artifact URLs of a run both work.
+	ExcludedJobRunURLs []string `json:",omitempty"`
+	// IncludeZTest adds a two-proportion z-test to regressed tests for cross-checking against
+	// Fisher's exact test. It is informational only and never changes a status.
+	IncludeZTest bool
+	// IncludeCollapsedVariants lists the variant groupings left out of the GroupBy that the tests
+	// ran with several values of, so users know which distinctions the grid hides.
+	IncludeCollapsedVariants bool
+	// IncludeRawStats adds the contingency table the significance test was computed from to
+	// each test, so the p-value can be reproduced.
+	IncludeRawStats bool
+	// SortColumnsBy changes the order of the columns within each row, the default orders them
+	// by variant. See ColumnSortSeverity.
+	SortColumnsBy ColumnSort `json:",omitempty"`
+	// SortJobStatsBy changes the order of the JobStats of test details, the default orders them
+	// by job name. See JobStatsSortContribution.
+	SortJobStatsBy JobStatsSort `json:",omitempty"`
+	// MultipleComparisonCorrection adjusts the significance level for the number of tests
+	// compared across the whole report, so fewer regressions are statistical noise.
+	MultipleComparisonCorrection MultipleComparisonCorrection `json:",omitempty"`
+	// MaxBaseSampleGap annotates tests whose basis ended more than this long before the sample
+	// started, as the comparison may then reflect infrastructure changes. Zero disables it.
+	MaxBaseSampleGap time.Duration `json:",omitempty"`
+	// IncludeMinimumDetectableEffect adds the smallest detectable pass rate drop to test details.
+	IncludeMinimumDetectableEffect bool
+	// SeparateMigratedSuites keeps the stats of a test that ran in several suites within the
+	// window apart by suite, rather than merging them. Either way such tests are annotated.
+	SeparateMigratedSuites bool
+	// MinimumBasisRuns flags tests whose basis has fewer runs than this as having a weak basis,
+	// see ComponentReportTestSummary.WeakBasis. Zero disables the check.
+	MinimumBasisRuns int
+	// MinimumBasisRunsByVariant replaces MinimumBasisRuns for cells matching the variants of the
+	// key, given as comma separated key=value pairs such as "platform=metal,network=ovn". The
+	// match with the most pairs wins.
+	MinimumBasisRunsByVariant map[string]int `json:",omitempty"`
+	// ConfidenceOverrides replaces Confidence for cells with the variant of an override, so noisier
+	// variants such as metal can use a lower confidence. The first matching override wins. A
+	// MultipleComparisonCorrection takes precedence over any override.
+	ConfidenceOverrides []VariantConfidenceOverride `json:",omitempty"`
+	// MinimumFailureByComponent replaces MinimumFailure for the tests of the given components,
+	// such as components with few tests where a handful of failures is noise.
+	MinimumFailureByComponent map[string]int `json:",omitempty"`
+	// MinimumRegressionAgeDays reports regressions that have not been tracked as open for at
+	// least this many days as not significant, to focus on chronic regressions. Zero disables it.
+	MinimumRegressionAgeDays int
+	// SuppressWeakBasisRegressions reports regressions against a weak basis as not significant.
+	SuppressWeakBasisRegressions bool
+	// ChiSquaredThreshold replaces the fisher exact test with the cheaper chi-squared test when
+	// both the base and sample totals exceed it, where the difference is negligible. Zero always
+	// uses the fisher exact test.
+	ChiSquaredThreshold int
+	// Comparison selects how pass rates are compared, empty is ComparisonFisher.
+	Comparison Comparison `json:",omitempty"`
+	// AlternativeHypothesis selects one or two sided fisher exact and chi-squared tests, empty is
+	// AlternativeOneSided.
+	AlternativeHypothesis AlternativeHypothesis `json:",omitempty"`
+	// BucketGranularity adds a bucketed sample pass rate series to test details, see
+	// BucketGranularityAuto. Empty leaves the series out.
+	BucketGranularity BucketGranularity `json:",omitempty"`
+	// WarningMargin, in percentage points of confidence, flags drops in pass rate that narrowly
+	// miss significance as a RegressionWarning. For example a Confidence of 95 and a WarningMargin
+	// of 5 warns on p-values below 0.10. Zero disables warnings.
+	WarningMargin int
+	// MinimumImprovementDelta, in percentage points of pass rate, is how much the sample must
+	// improve on the basis to be a SignificantImprovement, so that tiny but significant
+	// improvements on large samples are reported as not significant. Zero disables it.
+	MinimumImprovementDelta float64
+	// IncludePValues keeps the p-value of every test compared in a cell, not only those of
+	// regressed tests, so the calibration of the report can be checked. See PValueHistogram.
+	IncludePValues bool
+	// JobAggregation changes how test details combine the pass rates of the jobs the test ran
+	// in, the default pools the counts of all jobs. See JobAggregationMedian.
+	JobAggregation JobAggregation `json:",omitempty"`
+	// JunitAggregation collapses the results of several junit files for the same test in the
+	// same job run into a single run before counting. The default counts each junit file as a
+	// run of its own.
+	JunitAggregation JunitAggregation `json:",omitempty"`
+	// FlakeHandling changes how flakes count towards the reported pass rates, the default counts
+	// them as passes. Regressions are still assessed counting flakes as passes.
+	FlakeHandling FlakeHandling `json:",omitempty"`
+	// ExcludeRunsWithoutTest drops the job runs of test details in which the test did not run
+	// at all, so they are neither listed nor picked as the first run of a pull request.
+	ExcludeRunsWithoutTest bool
+	// JobNameNormalizations are applied in order to prow job names to match the jobs of base and
+	// sample, for the per job stats of test details and IncludeJobs. They replace the default,
+	// which replaces the base and sample releases, and the releases before them, with X.X and
+	// the frequency suffix with -fXX.
+	JobNameNormalizations []JobNameNormalization `json:",omitempty"`
+	// CapabilityOverrides replace the capabilities of the tests with the given test IDs, for tests
+	// the component mapping misclassifies.
+	CapabilityOverrides map[string][]string `json:",omitempty"`
+	// MinimumJobSetOverlap, a percentage, warns in test details when the jobs that ran the test
+	// in base and sample overlap less than this, see ComponentReportTestDetails.JobSetOverlap.
+	// Zero disables the warning.
+	MinimumJobSetOverlap int
+	// IncludeFlakeRates adds the flake rates of sample and basis to each cell of the report.
+	IncludeFlakeRates bool
+	// FlagHighFlakeRates reports tests whose pass rate is stable, as flakes count as passes, but
+	// whose sample flake rate is significantly above the basis flake rate as HighFlakeRate.
+	FlagHighFlakeRates bool
+}
+
+// VariantConfidenceOverride is the confidence to use for cells where the variant VariantName,
+// such as platform, has the value VariantValue.
+type VariantConfidenceOverride struct {
+	VariantName  string `json:"variant_name" yaml:"variantName"`
+	VariantValue string `json:"variant_value" yaml:"variantValue"`
+	Confidence   int    `json:"confidence" yaml:"confidence"`
+}
+
+// JobNameNormalization replaces every match of Pattern, an RE2 regular expression, in a prow job
+// name with Replacement. The replacement is literal, it cannot refer to groups of the pattern.
+type JobNameNormalization struct {
+	Pattern     string `json:"pattern" yaml:"pattern"`
+	Replacement string `json:"replacement" yaml:"replacement"`
 }
 
 type ComponentTestStatus struct {
@@ -828,6 +1025,11 @@ type ComponentTestStatus struct {
 	TotalCount   int      `json:"total_count"`
 	SuccessCount int      `json:"success_count"`
 	FlakeCount   int      `json:"flake_count"`
+	// FailingJobCount is the number of distinct jobs with at least one failure of the test.
+	FailingJobCount int `json:"failing_job_count"`
+	// SuiteCount is the number of suites the test ran in, more than one when it migrated
+	// between suites within the window.
+	SuiteCount int `json:"suite_count,omitempty"`
 }
 
 type ComponentReportTestStatus struct {
@@ -843,6 +1045,8 @@ type ComponentTestIdentification struct {
 	Arch         string `json:"arch"`
 	Platform     string `json:"platform"`
 	FlatVariants string `json:"flat_variants"`
+	// TestSuite is only set when stats are kept apart by suite, see SeparateMigratedSuites.
+	TestSuite string `json:"test_suite,omitempty"`
 }
 
 // implement encoding.TextMarshaler for json map key marshalling support
@@ -871,11 +1075,22 @@ type ComponentTestStatusRow struct {
 	FlakeCount   int      `bigquery:"flake_count"`
 	Component    string   `bigquery:"component"`
 	Capabilities []string `bigquery:"capabilities"`
+	// FailingJobCount is the number of distinct jobs with at least one failure of the test.
+	FailingJobCount int `bigquery:"failing_job_count"`
+	SuiteCount      int `bigquery:"suite_count"`
 }
 
 type ComponentReport struct {
 	Rows        []ComponentReportRow `json:"rows,omitempty"`
 	GeneratedAt *time.Time           `json:"generated_at"`
+	// BaseWindow is the window the basis resolved to, only set for a RollingBaseline.
+	BaseWindow *ReleaseWindow `json:"base_window,omitempty"`
+	// CollapsedVariants are the variant groupings the cells aggregate over because they were left
+	// out of the GroupBy, only set when IncludeCollapsedVariants is requested.
+	CollapsedVariants []string `json:"collapsed_variants,omitempty"`
+	// RegressionStatuses is the status of the test of each tracked regression of the sample
+	// release, keyed by regression ID. Regressions of tests the report has no data for are left out.
+	RegressionStatuses map[string]ComponentReportStatus `json:"regression_statuses,omitempty"`
 }
 
 type ComponentReportRow struct {
@@ -891,11 +1106,75 @@ type ComponentReportRowIdentification struct {
 	TestID     string `json:"test_id,omitempty"`
 }
 
+// HealthComparison summarizes the difference in regressed tests between two reports of the
+// same view, typically for two releases at a comparable point in their cycle.
+type HealthComparison struct {
+	// RegressionsA and RegressionsB are the number of regressed tests in each report by status.
+	RegressionsA map[ComponentReportStatus]int `json:"regressions_a"`
+	RegressionsB map[ComponentReportStatus]int `json:"regressions_b"`
+	// NetChange is the number of regressed tests in b minus those in a, by status.
+	NetChange map[ComponentReportStatus]int `json:"net_change"`
+	TotalA    int                           `json:"total_a"`
+	TotalB    int                           `json:"total_b"`
+	// TotalNetChange is TotalB minus TotalA, positive when b has more regressions.
+	TotalNetChange int `json:"total_net_change"`
+}
+
+// ColumnTree nests the columns of a component report by variant for tree views. The root has
+// no key, each level below it groups the columns by one variant.
+type ColumnTree struct {
+	Key      string        `json:"key,omitempty"`
+	Value    string        `json:"value,omitempty"`
+	Children []*ColumnTree `json:"children,omitempty"`
+	// Leaves are the columns at the deepest level of the tree.
+	Leaves []ColumnTreeLeaf `json:"leaves,omitempty"`
+}
+
+// ColumnTreeLeaf is a column of the report with its status in each row, in row order.
+type ColumnTreeLeaf struct {
+	ComponentReportColumnIdentification
+	Statuses []ComponentReportStatus `json:"statuses"`
+}
+
+// ContingencyTable is the 2x2 table of sample and base passes and failures compared by the
+// significance tests. Flakes count as passes, and sample failures covered by triaged incidents
+// are left out.
+type ContingencyTable struct {
+	SamplePass int `json:"sample_pass"`
+	SampleFail int `json:"sample_fail"`
+	BasePass   int `json:"base_pass"`
+	BaseFail   int `json:"base_fail"`
+}
+
+// BandedPoint is the pass rate of a test over one time bucket, with the bounds of its
+// Wilson score interval for drawing a confidence band.
+type BandedPoint struct {
+	Start civil.DateTime `json:"start"`
+	End   civil.DateTime `json:"end"`
+	// Empty is set when no runs fell into the bucket, the rates are meaningless then.
+	Empty    bool    `json:"empty"`
+	Total    int     `json:"total"`
+	PassRate float64 `json:"pass_rate"`
+	Low      float64 `json:"low"`
+	High     float64 `json:"high"`
+}
+
 type ComponentReportColumn struct {
 	ComponentReportColumnIdentification
 	Status           ComponentReportStatus                  `json:"status"`
 	RegressedTests   []ComponentReportTestSummary           `json:"regressed_tests,omitempty"`
 	TriagedIncidents []ComponentReportTriageIncidentSummary `json:"triaged_incidents,omitempty"`
+	// ImprovedTests lists the tests of the cell that significantly improved, when requested.
+	ImprovedTests []ComponentReportTestSummary `json:"improved_tests,omitempty"`
+	// CapabilityStatuses breaks the status of the cell down by capability, when requested.
+	CapabilityStatuses map[string]ComponentReportStatus `json:"capability_statuses,omitempty"`
+	// PValues are the p-values of the significance tests run for the tests of the cell, only
+	// set when requested.
+	PValues []float64 `json:"p_values,omitempty"`
+	// SampleFlakeRate and BaseFlakeRate are the flakes of all tests of the cell over their runs,
+	// only set when requested with IncludeFlakeRates and the cell has runs.
+	SampleFlakeRate *float64 `json:"sample_flake_rate,omitempty"`
+	BaseFlakeRate   *float64 `json:"base_flake_rate,omitempty"`
 }
 
 type ComponentReportColumnIdentification struct {
@@ -904,6 +1183,12 @@ type ComponentReportColumnIdentification struct {
 	Arch     string `json:"arch,omitempty"`
 	Platform string `json:"platform,omitempty"`
 	Variant  string `json:"variant,omitempty"`
+	// BaseArch is the architecture of the base the Arch of the sample was compared against, set
+	// only when cross comparing architectures. See ComponentReportRequestVariantOptions.BaseArch.
+	BaseArch string `json:"base_arch,omitempty"`
+	// Suite is the test suite, named differently from the row TestSuite so both can be embedded
+	// in ComponentReportTestIdentification.
+	Suite string `json:"suite,omitempty"`
 }
 
 type ComponentReportStatus int
@@ -918,12 +1203,74 @@ type ComponentReportTestSummary struct {
 	// Status is an integer representing the severity of the regression.
 	Status ComponentReportStatus `json:"status"`
 
+	// SampleSuccessRate and BaseSuccessRate are the pass rates the status was assessed from.
+	SampleSuccessRate float64 `json:"sample_success_rate"`
+	BaseSuccessRate   float64 `json:"base_success_rate"`
+
+	// SingleJobFailures is set when all sample failures came from a single job, which usually
+	// points at a problem with that job rather than a broad regression.
+	SingleJobFailures bool `json:"single_job_failures,omitempty"`
+
+	// DecidingFactor is what determined the status, empty when there was nothing to compare.
+	DecidingFactor DecidingFactor `json:"deciding_factor,omitempty"`
+
+	// FisherExact is the p-value of the significance test named by DecidingFactor, usually the
+	// fisher exact test, zero when it was not computed.
+	FisherExact float64 `json:"fisher_exact,omitempty"`
+
+	// SuiteMigration is set when the test ran in more than one suite within the windows, its
+	// stats are then either merged or split by suite, see SeparateMigratedSuites.
+	SuiteMigration bool `json:"suite_migration,omitempty"`
+
+	// BaseSampleGap is the time between the end of the basis and the start of the sample,
+	// only set when it exceeds the requested MaxBaseSampleGap.
+	BaseSampleGap time.Duration `json:"base_sample_gap,omitempty"`
+
+	// WeakBasis is set when the basis had fewer runs than the requested MinimumBasisRuns, so is
+	// not a trustworthy baseline to compare against.
+	WeakBasis bool `json:"weak_basis,omitempty"`
+
+	// OverriddenConfidence is the confidence the test was assessed with when one of the
+	// ConfidenceOverrides replaced the requested Confidence, zero otherwise.
+	OverriddenConfidence int `json:"overridden_confidence,omitempty"`
+
+	// ZScore and ZPValue are the two-proportion z-test of sample against base, only set
+	// when requested. A negative ZScore means the sample pass rate is lower.
+	ZScore  *float64 `json:"z_score,omitempty"`
+	ZPValue *float64 `json:"z_p_value,omitempty"`
+
+	// ContingencyTable holds the passes and failures the status was assessed from, only set when
+	// IncludeRawStats is requested.
+	ContingencyTable *ContingencyTable `json:"contingency_table,omitempty"`
+
 	// Opened will be set to the time we first recorded this test went regressed.
 	// TODO: This is largely a hack right now, the sippy metrics loop sets this as soon as it notices
 	// the regression with it's *default view* query. However we always include it in the response (if that test
 	// is regressed per the query params used). Eventually we should only include these details if the default view
 	// is being used, without overriding the start/end dates.
 	Opened *time.Time `json:"opened"`
+	// CredibleIntervalLower and CredibleIntervalUpper bound the sample pass rate minus the base pass
+	// rate, at the requested confidence, only set for the bayesian comparison.
+	CredibleIntervalLower *float64 `json:"credible_interval_lower,omitempty"`
+	CredibleIntervalUpper *float64 `json:"credible_interval_upper,omitempty"`
+	// RegressionAgeDays is how many whole days the regression had been open by the end of the
+	// sample, zero when it is not tracked or has since closed.
+	RegressionAgeDays int `json:"regression_age_days,omitempty"`
+	// KnownIssues are the open bugs linked to a regressed test, see AnnotateKnownIssues.
+	KnownIssues []KnownIssue `json:"known_issues,omitempty"`
+}
+
+// KnownIssue is an open bug linked to a test.
+type KnownIssue struct {
+	Key     string `json:"key"`
+	URL     string `json:"url"`
+	Summary string `json:"summary,omitempty"`
+}
+
+// ComponentReportNewTest is a test, with its variants, that ran in the sample but not in the basis.
+type ComponentReportNewTest struct {
+	ComponentReportTestIdentification
+	SampleStats ComponentReportTestDetailsTestStats `json:"sample_stats"`
 }
 
 type ComponentReportTestDetails struct {
@@ -934,8 +1281,44 @@ type ComponentReportTestDetails struct {
 	BaseStats       ComponentReportTestDetailsReleaseStats `json:"base_stats"`
 	FisherExact     float64                                `json:"fisher_exact"`
 	ReportStatus    ComponentReportStatus                  `json:"report_status"`
-	JobStats        []ComponentReportTestDetailsJobStats   `json:"job_stats,omitempty"`
-	GeneratedAt     *time.Time                             `json:"generated_at"`
+	DecidingFactor  DecidingFactor                         `json:"deciding_factor,omitempty"`
+	ZScore          *float64                               `json:"z_score,omitempty"`
+	ZPValue         *float64                               `json:"z_p_value,omitempty"`
+	// ContingencyTable is set like that of ComponentReportTestSummary.
+	ContingencyTable *ContingencyTable `json:"contingency_table,omitempty"`
+	// CredibleIntervalLower and CredibleIntervalUpper are set like those of ComponentReportTestSummary.
+	CredibleIntervalLower *float64 `json:"credible_interval_lower,omitempty"`
+	CredibleIntervalUpper *float64 `json:"credible_interval_upper,omitempty"`
+	// MinimumDetectableEffect is the smallest drop in pass rate the sample size could detect,
+	// only set when requested.
+	MinimumDetectableEffect *float64                             `json:"minimum_detectable_effect,omitempty"`
+	SuiteMigration          bool                                 `json:"suite_migration,omitempty"`
+	JobStats                []ComponentReportTestDetailsJobStats `json:"job_stats,omitempty"`
+	GeneratedAt             *time.Time                           `json:"generated_at"`
+	// SampleSeries is the sample pass rate over the sample window, only set when a
+	// BucketGranularity is requested.
+	SampleSeries []BandedPoint `json:"sample_series,omitempty"`
+	// RequestedBaseRelease is the base release of the request, only set when a variant override
+	// replaced it for this test. BaseStats carries the release actually compared against.
+	RequestedBaseRelease string `json:"requested_base_release,omitempty"`
+	// OverriddenConfidence is set like ComponentReportTestSummary.OverriddenConfidence.
+	OverriddenConfidence int `json:"overridden_confidence,omitempty"`
+	// RunsWithoutTest is the number of base and sample job runs that did not run the test, only
+	// set when they are excluded with ExcludeRunsWithoutTest.
+	RunsWithoutTest int `json:"runs_without_test,omitempty"`
+	// JobSetOverlap is the Jaccard index of the jobs that ran the test in base and sample, one
+	// when the same jobs ran in both. The comparison is weaker the lower it is, as jobs changed
+	// between the releases.
+	JobSetOverlap float64 `json:"job_set_overlap"`
+	// Warnings are about the comparability of base and sample, such as a low JobSetOverlap.
+	Warnings []string `json:"warnings,omitempty"`
+	// ExcludedJobRuns is how many job runs were dropped as requested by ExcludedJobRunURLs.
+	ExcludedJobRuns int `json:"excluded_job_runs,omitempty"`
+	// BaseWindow is set like ComponentReport.BaseWindow.
+	BaseWindow *ReleaseWindow `json:"base_window,omitempty"`
+	// InfraFailedJobRuns is how many job runs were dropped as infrastructure failures, see
+	// IgnoreInfraFailures.
+	InfraFailedJobRuns int `json:"infra_failed_job_runs,omitempty"`
 }
 
 type ComponentReportTestDetailsReleaseStats struct {
@@ -960,7 +1343,10 @@ type ComponentReportTestDetailsJobStats struct {
 }
 
 type ComponentReportTestDetailsJobRunStats struct {
-	JobURL string `json:"job_url"`
+	JobURL   string `json:"job_url"`
+	JobRunID string `json:"job_run_id,omitempty"`
+	// StartTime is when the job run started, zero when it is not known.
+	StartTime civil.DateTime `json:"start_time"`
 	// TestStats is the test stats from one particular job run.
 	// For the majority of the tests, there is only one junit. But
 	// there are cases multiple junits are generated for the same test.
@@ -987,15 +1373,26 @@ type ComponentJobRunTestIdentification struct {
 }
 
 type ComponentJobRunTestStatusRow struct {
-	ProwJob         string   `bigquery:"prowjob_name"`
-	TestID          string   `bigquery:"test_id"`
-	TestName        string   `bigquery:"test_name"`
-	FilePath        string   `bigquery:"file_path"`
-	TotalCount      int      `bigquery:"total_count"`
-	SuccessCount    int      `bigquery:"success_count"`
-	FlakeCount      int      `bigquery:"flake_count"`
-	JiraComponent   string   `bigquery:"jira_component"`
-	JiraComponentID *big.Rat `bigquery:"jira_component_id"`
+	ProwJob         string         `bigquery:"prowjob_name"`
+	ProwJobRunID    string         `bigquery:"prowjob_build_id"`
+	StartTime       civil.DateTime `bigquery:"prowjob_start"`
+	TestID          string         `bigquery:"test_id"`
+	TestName        string         `bigquery:"test_name"`
+	TestSuite       string         `bigquery:"test_suite"`
+	FilePath        string         `bigquery:"file_path"`
+	TotalCount      int            `bigquery:"total_count"`
+	SuccessCount    int            `bigquery:"success_count"`
+	FlakeCount      int            `bigquery:"flake_count"`
+	JiraComponent   string         `bigquery:"jira_component"`
+	JiraComponentID *big.Rat       `bigquery:"jira_component_id"`
+	// PROrg, PRRepo and PRNumber identify the pull request the job run tested, they are empty for
+	// job runs not associated with a pull request.
+	PROrg    string `bigquery:"org"`
+	PRRepo   string `bigquery:"repo"`
+	PRNumber string `bigquery:"pr_number"`
+	// InfrastructureFailure is set when the install infrastructure test failed in the job run, so
+	// the run failed for CI infrastructure reasons.
+	InfrastructureFailure bool `bigquery:"infrastructure_failure"`
 }
 
 type ComponentJobRunTestReportStatus struct {
@@ -1023,6 +1420,202 @@ const (
 	MissingBasisAndSample ComponentReportStatus = 2
 	// SignificantImprovement indicates improved sample rate
 	SignificantImprovement ComponentReportStatus = 3
+	// RegressionWarning shows a drop in pass rate that is not significant, but whose fisher exact
+	// p-value falls within the requested warning margin of the required confidence. Its value
+	// follows the existing statuses, so it is out of order, see WorseThan.
+	RegressionWarning ComponentReportStatus = 4
+	// HighFlakeRate shows a stable pass rate, but a sample flake rate significantly above the
+	// basis flake rate, see FlagHighFlakeRates. Like RegressionWarning it is out of order.
+	HighFlakeRate ComponentReportStatus = 5
+)
+
+// statusesBySeverity are the statuses from most to least severe.
+var statusesBySeverity = []ComponentReportStatus{
+	ExtremeRegression,
+	SignificantRegression,
+	ExtremeTriagedRegression,
+	SignificantTriagedRegression,
+	RegressionWarning,
+	HighFlakeRate,
+	MissingSample,
+	NotSignificant,
+	MissingBasis,
+	MissingBasisAndSample,
+	SignificantImprovement,
+}
+
+// WorseThan returns true when the status is more severe than other. Statuses are numbered in
+// order of severity, except for those added later, which follow the existing values so as not
+// to change them, so statuses should be ordered with WorseThan rather than compared as numbers.
+func (s ComponentReportStatus) WorseThan(other ComponentReportStatus) bool {
+	return s.severity() < other.severity()
+}
+
+// severity is the position of the status in statusesBySeverity, unknown statuses rank last.
+func (s ComponentReportStatus) severity() int {
+	for i, status := range statusesBySeverity {
+		if status == s {
+			return i
+		}
+	}
+	return len(statusesBySeverity)
+}
+
+// DecidingFactor identifies what ultimately determined the status of a test.
+type DecidingFactor string
+
+const (
+	// DecidingFactorFisher means the fisher exact test decided the status
+	DecidingFactorFisher DecidingFactor = "fisher"
+	// DecidingFactorChiSquared means the chi-squared test decided the status, see ChiSquaredThreshold
+	DecidingFactorChiSquared DecidingFactor = "chi_squared"
+	// DecidingFactorPity means the pass rate drop was within the pity factor
+	DecidingFactorPity DecidingFactor = "pity"
+	// DecidingFactorIdentical means base and sample had identical counts, so no significance test
+	// was run
+	DecidingFactorIdentical DecidingFactor = "identical"
+	// DecidingFactorMinimumFailure means there were fewer sample failures than the minimum
+	DecidingFactorMinimumFailure DecidingFactor = "minimum_failure"
+	// DecidingFactorPassRateFloor means the sample pass rate was below the extreme pass rate floor
+	DecidingFactorPassRateFloor DecidingFactor = "pass_rate_floor"
+	// DecidingFactorMinimumFailingJobs means the sample failures came from too few distinct jobs
+	DecidingFactorMinimumFailingJobs DecidingFactor = "minimum_failing_jobs"
+	// DecidingFactorWeakBasis means a regression was suppressed as the basis had too few runs
+	DecidingFactorWeakBasis DecidingFactor = "weak_basis"
+	// DecidingFactorRegressionAge means a regression was suppressed as it had not been open for
+	// MinimumRegressionAgeDays
+	DecidingFactorRegressionAge DecidingFactor = "regression_age"
+	// DecidingFactorBayesian means the credible interval of the pass rate difference decided the
+	// status, see ComparisonBayesian
+	DecidingFactorBayesian DecidingFactor = "bayesian"
+	// DecidingFactorFlakeRate means the fisher exact test of the flake rates decided the status,
+	// see HighFlakeRate
+	DecidingFactorFlakeRate DecidingFactor = "flake_rate"
+	// DecidingFactorMinimumImprovement means a significant improvement was smaller than the
+	// MinimumImprovementDelta
+	DecidingFactorMinimumImprovement DecidingFactor = "minimum_improvement"
+)
+
+// MultipleComparisonCorrection is a method of correcting for the number of fisher exact tests
+// performed in a single report.
+type MultipleComparisonCorrection string
+
+const (
+	// CorrectionBonferroni divides the significance level by the number of tests performed.
+	CorrectionBonferroni MultipleComparisonCorrection = "bonferroni"
+	// CorrectionBenjaminiHochberg controls the false discovery rate, it is less strict than
+	// Bonferroni when there are many real regressions.
+	CorrectionBenjaminiHochberg MultipleComparisonCorrection = "benjamini-hochberg"
+)
+
+// ColumnSort is an ordering for the columns of a component report row.
+type ColumnSort string
+
+const (
+	// ColumnSortSeverity orders the columns of each row worst status first, breaking ties by
+	// the most significant fisher exact result of the regressed tests in the column.
+	ColumnSortSeverity ColumnSort = "severity"
+)
+
+// JobStatsSort is an ordering for the per job stats of test details.
+type JobStatsSort string
+
+const (
+	// JobStatsSortContribution orders the jobs by their contribution to a regression, the drop
+	// in pass rate from base to sample times the sample runs, largest first. Jobs only in the
+	// sample are compared against the pass rate of the whole base.
+	JobStatsSortContribution JobStatsSort = "contribution"
+)
+
+// BucketGranularity is the width of the buckets of a pass rate series.
+type BucketGranularity string
+
+const (
+	// BucketGranularityDay uses one bucket per day of the window.
+	BucketGranularityDay BucketGranularity = "day"
+	// BucketGranularityWeek uses one bucket per week of the window.
+	BucketGranularityWeek BucketGranularity = "week"
+	// BucketGranularityAuto uses daily buckets for windows of up to four weeks, weekly otherwise.
+	BucketGranularityAuto BucketGranularity = "auto"
+)
+
+// JobAggregation is how the per job pass rates of test details are combined into the overall
+// sample and base pass rates.
+type JobAggregation string
+
+const (
+	// JobAggregationMedian uses the median of the per job pass rates, so a single high volume
+	// job does not dominate the overall pass rate. The counts, and so the status, stay pooled.
+	JobAggregationMedian JobAggregation = "median"
+	// JobAggregationMean uses the unweighted mean of the per job pass rates.
+	JobAggregationMean JobAggregation = "mean"
+)
+
+// JunitAggregation is how the results of several junit files for the same test in the same job
+// run are collapsed into a single outcome of the run.
+type JunitAggregation string
+
+const (
+	// JunitAggregationAnyPass passes the run when any junit passed, it flakes when any flaked.
+	JunitAggregationAnyPass JunitAggregation = "any-pass"
+	// JunitAggregationAllPass fails the run when any junit failed, it flakes when any flaked
+	// and none failed.
+	JunitAggregationAllPass JunitAggregation = "all-pass"
+	// JunitAggregationMajority passes the run when more junits passed or flaked than failed,
+	// ties fail. It flakes when it passes and any junit flaked.
+	JunitAggregationMajority JunitAggregation = "majority"
+)
+
+// FlakeHandling is how flakes count towards a pass rate.
+type FlakeHandling string
+
+const (
+	// FlakeHandlingExclude leaves flakes out of the pass rate entirely, the successes over the
+	// runs that did not flake, so a test that flakes but never fails passes 100%.
+	FlakeHandlingExclude FlakeHandling = "exclude"
+)
+
+// RegressionPattern is the shape of the decline of a bucketed pass rate series, see
+// ClassifyRegressionPattern.
+type RegressionPattern string
+
+const (
+	// RegressionPatternSudden is a cliff, most of the decline happened between two buckets. It
+	// usually points at a specific change.
+	RegressionPatternSudden RegressionPattern = "sudden"
+	// RegressionPatternGradual is a steady decline over several buckets.
+	RegressionPatternGradual RegressionPattern = "gradual"
+	// RegressionPatternIntermittent is a pass rate repeatedly dropping and recovering, which
+	// usually means flakiness.
+	RegressionPatternIntermittent RegressionPattern = "intermittent"
+	// RegressionPatternStable is a series without any of the other patterns.
+	RegressionPatternStable RegressionPattern = "stable"
+)
+
+// Comparison is how the sample and base pass rates of a test are compared.
+type Comparison string
+
+const (
+	// ComparisonFisher uses the fisher exact test, or the chi-squared test above the
+	// ChiSquaredThreshold. It is the default.
+	ComparisonFisher Comparison = "fisher"
+	// ComparisonBayesian computes the posterior pass rates of sample and base from uniform Beta
+	// priors, and finds a significant change when the credible interval of their difference at
+	// the requested confidence excludes zero. It swings less than the fisher exact test on
+	// small samples.
+	ComparisonBayesian Comparison = "bayesian"
+)
+
+// AlternativeHypothesis is the alternative hypothesis of the significance tests.
+type AlternativeHypothesis string
+
+const (
+	// AlternativeOneSided tests for a change in the direction of the pass rates only, a drop when
+	// the sample pass rate is lower and a rise otherwise. It is the default.
+	AlternativeOneSided AlternativeHypothesis = "one_sided"
+	// AlternativeTwoSided tests for a change in either direction, the direction of a significant
+	// change is then taken from the pass rates.
+	AlternativeTwoSided AlternativeHypothesis = "two_sided"
 )
 
 type ComponentReportResponse []ComponentReportRow
@@ -1120,6 +1713,23 @@ type TestRegression struct {
 	Opened       time.Time                `bigquery:"opened" json:"opened"`
 	Closed       bigquery.NullTimestamp   `bigquery:"closed" json:"closed"`
 	Variants     []ComponentReportVariant `bigquery:"variants" json:"variants"`
+	// JiraCreated is when a Jira issue was filed for the regression, null until one is recorded.
+	JiraCreated bigquery.NullTimestamp `bigquery:"jira_created" json:"jira_created"`
+	// ClearCount is how many consecutive reports the test was clear in while the regression was
+	// open, see tracker.RegressionReconciler. Null until the regression is first reconciled.
+	ClearCount bigquery.NullInt64 `bigquery:"clear_count" json:"clear_count"`
+}
+
+// OpenTestRegression is a tracked regression that has not closed, as listed across releases.
+type OpenTestRegression struct {
+	TestRegression
+	// Component is the component the test currently maps to, empty when it no longer maps to one.
+	Component string `bigquery:"component" json:"component"`
+	// Status is the latest status of the test in the component report of its release, nil when
+	// no report was given for the release or the test no longer appears regressed in it.
+	Status *ComponentReportStatus `bigquery:"-" json:"status,omitempty"`
+	// AgeDays is how many whole days the regression has been open.
+	AgeDays int `bigquery:"-" json:"age_days"`
 }
 
 type TriagedIncident struct {
@@ -1,10 +1,14 @@
 package cache
 
 import (
+	"errors"
 	"net/http"
 	"time"
 )
 
+// ErrCacheMiss is returned for peek only requests when nothing is cached for the key.
+var ErrCacheMiss = errors.New("cache miss")
+
 type Cache interface {
 	Get(key string) ([]byte, error)
 	Set(key string, content []byte, duration time.Duration) error
@@ -19,6 +23,9 @@ type APIResponse struct {
 // request, such as forcing the cache to be bypassed.
 type RequestOptions struct {
 	ForceRefresh bool
+	// PeekOnly returns a cached item if present, and ErrCacheMiss rather than
+	// generating the data when it is not.
+	PeekOnly bool
 	// CRTimeRoundingFactor is used to calculate cache expiration time
 	CRTimeRoundingFactor time.Duration
 }
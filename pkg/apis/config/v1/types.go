@@ -1,8 +1,49 @@
 package v1
 
+import (
+	"github.com/openshift/sippy/pkg/apis/api"
+)
+
 type SippyConfig struct {
-	Prow     ProwConfig               `yaml:"prow"`
-	Releases map[string]ReleaseConfig `yaml:"releases"`
+	Prow               ProwConfig               `yaml:"prow"`
+	Releases           map[string]ReleaseConfig `yaml:"releases"`
+	ComponentReadiness ComponentReadinessConfig `yaml:"componentReadiness,omitempty"`
+}
+
+type ComponentReadinessConfig struct {
+	// GroupingPresets are named lists of variant groupings, such as cloud or network, that can
+	// be selected by name instead of passing groupBy on every component report request.
+	GroupingPresets map[string][]string `yaml:"groupingPresets,omitempty"`
+	// MinimumBasisRunsByVariant lowers or raises the minimum basis runs for cells with the given
+	// variants, such as "platform=metal" or "platform=metal,network=ovn", for platforms that run
+	// less often. See ComponentReportRequestAdvancedOptions.MinimumBasisRunsByVariant.
+	MinimumBasisRunsByVariant map[string]int `yaml:"minimumBasisRunsByVariant,omitempty"`
+	// ConfidenceOverrides lowers or raises the confidence for cells with a variant, such as
+	// platform metal, that is inherently noisier. The first matching override wins.
+	ConfidenceOverrides []api.VariantConfidenceOverride `yaml:"confidenceOverrides,omitempty"`
+	// MinimumFailureByComponent raises or lowers the minimum failures for the tests of the given
+	// components. See ComponentReportRequestAdvancedOptions.MinimumFailureByComponent.
+	MinimumFailureByComponent map[string]int `yaml:"minimumFailureByComponent,omitempty"`
+	// JobNameNormalizations replace the default normalization of job names, for teams whose job
+	// names do not follow the usual release and frequency scheme. They are applied in order.
+	JobNameNormalizations []api.JobNameNormalization `yaml:"jobNameNormalizations,omitempty"`
+	// FlagHighFlakeRates reports tests that flake significantly more in the sample than in the
+	// basis as HighFlakeRate, even when their pass rate is stable.
+	FlagHighFlakeRates bool `yaml:"flagHighFlakeRates,omitempty"`
+	// CapabilityOverrides reassign the tests with the given test IDs to the listed capabilities,
+	// replacing those of the component mapping, until the mapping itself is corrected.
+	CapabilityOverrides map[string][]string `yaml:"capabilityOverrides,omitempty"`
+}
+
+// ApplyAdvancedOptions sets the advanced options of a component report request that are
+// configured rather than requested.
+func (c ComponentReadinessConfig) ApplyAdvancedOptions(options *api.ComponentReportRequestAdvancedOptions) {
+	options.MinimumBasisRunsByVariant = c.MinimumBasisRunsByVariant
+	options.ConfidenceOverrides = c.ConfidenceOverrides
+	options.MinimumFailureByComponent = c.MinimumFailureByComponent
+	options.JobNameNormalizations = c.JobNameNormalizations
+	options.FlagHighFlakeRates = c.FlagHighFlakeRates
+	options.CapabilityOverrides = c.CapabilityOverrides
 }
 
 type ProwConfig struct {
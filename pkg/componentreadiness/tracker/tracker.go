@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"cloud.google.com/go/bigquery"
@@ -21,12 +24,42 @@ const (
 	testRegressionsTable = "test_regressions"
 )
 
+// addedRegressionColumns were added to the test_regressions table after it was created, see
+// MigrateRegressionsTable. They are left out of inserts so rows can be written to a table that
+// has not been migrated yet, and are only set by updates.
+var addedRegressionColumns = bigquery.Schema{
+	{Name: "jira_created", Type: bigquery.TimestampFieldType},
+	{Name: "clear_count", Type: bigquery.IntegerFieldType},
+}
+
+// insertedTestRegression is the part of a TestRegression written when a regression opens, every
+// column but addedRegressionColumns.
+type insertedTestRegression struct {
+	Release      string                       `bigquery:"release"`
+	TestID       string                       `bigquery:"test_id"`
+	TestName     string                       `bigquery:"test_name"`
+	RegressionID string                       `bigquery:"regression_id"`
+	Opened       time.Time                    `bigquery:"opened"`
+	Closed       bigquery.NullTimestamp       `bigquery:"closed"`
+	Variants     []api.ComponentReportVariant `bigquery:"variants"`
+}
+
 // RegressionStore is an underlying interface for where we store/load data on open test regressions.
 type RegressionStore interface {
 	ListCurrentRegressions(release string) ([]api.TestRegression, error)
+	// ListTestRegressions returns every regression recorded for the test, open or closed, in any release.
+	ListTestRegressions(ctx context.Context, testID string) ([]api.TestRegression, error)
+	// ListOpenRegressions returns every regression that has not closed, in any release, with the component of its test.
+	ListOpenRegressions(ctx context.Context) ([]api.OpenTestRegression, error)
 	OpenRegression(release string, newRegressedTest api.ComponentReportTestSummary) (*api.TestRegression, error)
 	ReOpenRegression(regressionID string) error
 	CloseRegression(regressionID string, closedAt time.Time) error
+	// RecordJiraCreated records when a Jira issue was filed for the regression.
+	RecordJiraCreated(regressionID string, createdAt time.Time) error
+	// RecordClearCount records how many consecutive reports the regression has been clear in.
+	RecordClearCount(regressionID string, clearCount int) error
+	// MigrateRegressionsTable adds the columns added since the regressions table was created.
+	MigrateRegressionsTable(ctx context.Context) error
 }
 
 // BigQueryRegressionStore is the primary implementation for real world usage, storing when regressions appear/disappear in BigQuery.
@@ -55,11 +88,61 @@ func (bq *BigQueryRegressionStore) ListCurrentRegressions(release string) ([]api
 	sampleQuery := bq.client.BQ.Query(queryString)
 	sampleQuery.Parameters = append(sampleQuery.Parameters, params...)
 
-	regressions := make([]api.TestRegression, 0)
 	log.Infof("Fetching current test regressions with:\n%s\nParameters:\n%+v\n",
 		sampleQuery.Q, sampleQuery.Parameters)
+	return readRegressions(context.TODO(), sampleQuery)
+}
+
+func (bq *BigQueryRegressionStore) ListTestRegressions(ctx context.Context, testID string) ([]api.TestRegression, error) {
+	queryString := fmt.Sprintf("SELECT * FROM %s.%s WHERE test_id = @TestID", bq.client.Dataset, testRegressionsTable)
+	query := bq.client.BQ.Query(queryString)
+	query.Parameters = []bigquery.QueryParameter{
+		{
+			Name:  "TestID",
+			Value: testID,
+		},
+	}
+	return readRegressions(ctx, query)
+}
+
+func (bq *BigQueryRegressionStore) ListOpenRegressions(ctx context.Context) ([]api.OpenTestRegression, error) {
+	queryString := fmt.Sprintf(`WITH latest_component_mapping AS (
+						SELECT id, ANY_VALUE(component) AS component
+						FROM %s.component_mapping
+						WHERE created_at = (
+								SELECT MAX(created_at)
+								FROM %s.component_mapping)
+						GROUP BY id)
+					SELECT r.*, IFNULL(cm.component, '') AS component
+					FROM %s.%s r
+					LEFT JOIN latest_component_mapping cm ON r.test_id = cm.id
+					WHERE r.closed IS NULL`, bq.client.Dataset, bq.client.Dataset, bq.client.Dataset, testRegressionsTable)
+	query := bq.client.BQ.Query(queryString)
+
+	regressions := make([]api.OpenTestRegression, 0)
+	it, err := query.Read(ctx)
+	if err != nil {
+		log.WithError(err).Error("error querying open regressions from bigquery")
+		return regressions, err
+	}
+	for {
+		var regression api.OpenTestRegression
+		err := it.Next(&regression)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			log.WithError(err).Error("error parsing open regression from bigquery")
+			return nil, errors.Wrap(err, "error parsing open regression from bigquery")
+		}
+		regressions = append(regressions, regression)
+	}
+	return regressions, nil
+}
 
-	it, err := sampleQuery.Read(context.TODO())
+func readRegressions(ctx context.Context, query *bigquery.Query) ([]api.TestRegression, error) {
+	regressions := make([]api.TestRegression, 0)
+	it, err := query.Read(ctx)
 	if err != nil {
 		log.WithError(err).Error("error querying triaged incidents from bigquery")
 		return regressions, err
@@ -78,8 +161,8 @@ func (bq *BigQueryRegressionStore) ListCurrentRegressions(release string) ([]api
 		regressions = append(regressions, regression)
 	}
 	return regressions, nil
-
 }
+
 func (bq *BigQueryRegressionStore) OpenRegression(release string, newRegressedTest api.ComponentReportTestSummary) (*api.TestRegression, error) {
 	id := uuid.New()
 	newRegression := &api.TestRegression{
@@ -112,8 +195,16 @@ func (bq *BigQueryRegressionStore) OpenRegression(release string, newRegressedTe
 		},
 	}
 	inserter := bq.client.BQ.Dataset(bq.client.Dataset).Table(testRegressionsTable).Inserter()
-	items := []*api.TestRegression{
-		newRegression,
+	items := []*insertedTestRegression{
+		{
+			Release:      newRegression.Release,
+			TestID:       newRegression.TestID,
+			TestName:     newRegression.TestName,
+			RegressionID: newRegression.RegressionID,
+			Opened:       newRegression.Opened,
+			Closed:       newRegression.Closed,
+			Variants:     newRegression.Variants,
+		},
 	}
 	if err := inserter.Put(context.TODO(), items); err != nil {
 		return nil, err
@@ -122,6 +213,32 @@ func (bq *BigQueryRegressionStore) OpenRegression(release string, newRegressedTe
 
 }
 
+// MigrateRegressionsTable adds any of addedRegressionColumns the test_regressions table does not
+// have yet. They are nullable, so existing rows read as null.
+func (bq *BigQueryRegressionStore) MigrateRegressionsTable(ctx context.Context) error {
+	table := bq.client.BQ.Dataset(bq.client.Dataset).Table(testRegressionsTable)
+	md, err := table.Metadata(ctx)
+	if err != nil {
+		return err
+	}
+	existing := map[string]bool{}
+	for _, field := range md.Schema {
+		existing[field.Name] = true
+	}
+	schema := md.Schema
+	for _, field := range addedRegressionColumns {
+		if !existing[field.Name] {
+			log.Infof("adding column %s to %s", field.Name, testRegressionsTable)
+			schema = append(schema, field)
+		}
+	}
+	if len(schema) == len(md.Schema) {
+		return nil
+	}
+	_, err = table.Update(ctx, bigquery.TableMetadataToUpdate{Schema: schema}, md.ETag)
+	return err
+}
+
 func (bq *BigQueryRegressionStore) ReOpenRegression(regressionID string) error {
 	return bq.updateClosed(regressionID, "NULL")
 }
@@ -131,9 +248,22 @@ func (bq *BigQueryRegressionStore) CloseRegression(regressionID string, closedAt
 		fmt.Sprintf("'%s'", closedAt.Format("2006-01-02 15:04:05.999999")))
 }
 
+func (bq *BigQueryRegressionStore) RecordJiraCreated(regressionID string, createdAt time.Time) error {
+	return bq.update(regressionID, "jira_created",
+		fmt.Sprintf("'%s'", createdAt.Format("2006-01-02 15:04:05.999999")))
+}
+
+func (bq *BigQueryRegressionStore) RecordClearCount(regressionID string, clearCount int) error {
+	return bq.update(regressionID, "clear_count", strconv.Itoa(clearCount))
+}
+
 func (bq *BigQueryRegressionStore) updateClosed(regressionID, closed string) error {
-	queryString := fmt.Sprintf("UPDATE %s.%s SET closed = %s WHERE regression_id = '%s'",
-		bq.client.Dataset, testRegressionsTable, closed, regressionID)
+	return bq.update(regressionID, "closed", closed)
+}
+
+func (bq *BigQueryRegressionStore) update(regressionID, column, value string) error {
+	queryString := fmt.Sprintf("UPDATE %s.%s SET %s = %s WHERE regression_id = '%s'",
+		bq.client.Dataset, testRegressionsTable, column, value, regressionID)
 
 	query := bq.client.BQ.Query(queryString)
 
@@ -160,11 +290,76 @@ func NewRegressionTracker(backend RegressionStore, dryRun bool) *RegressionTrack
 
 // RegressionTracker is the primary object for managing regression tracking logic.
 type RegressionTracker struct {
-	backend RegressionStore
-	dryRun  bool
+	backend    RegressionStore
+	dryRun     bool
+	reconciler *RegressionReconciler
+}
+
+// WithReconciler makes the tracker close regressions no longer appearing in the report only once
+// the reconciler decides they cleared, rather than on the first report without them. The reports
+// each regression was clear in are counted in the store, so trackers may be created for every sync.
+func (rt *RegressionTracker) WithReconciler(reconciler *RegressionReconciler) *RegressionTracker {
+	rt.reconciler = reconciler
+	return rt
+}
+
+// RegressionReconciler decides when tracked regressions have cleared. A regression is closed once
+// it was clear, NotSignificant or improved, in ClearSnapshots consecutive snapshots. Any snapshot in
+// which it regressed again starts the count over, so a test flapping in and out of the report is
+// not closed and reopened over and over. Snapshots without a status for it, or with missing data,
+// neither count nor start over. The count is kept in the ClearCount of each regression, so it
+// carries over between reconcilers as long as the regressions they change are stored.
+type RegressionReconciler struct {
+	ClearSnapshots int
+}
+
+func NewRegressionReconciler(clearSnapshots int) *RegressionReconciler {
+	return &RegressionReconciler{
+		ClearSnapshots: clearSnapshots,
+	}
+}
+
+// Reconcile records a snapshot of the report status of the regressions, keyed by regression ID. It
+// returns the regressions whose ClearCount changed, with Closed set to now for open regressions that
+// have now cleared.
+func (r *RegressionReconciler) Reconcile(regs []api.TestRegression, statuses map[string]api.ComponentReportStatus, now time.Time) []api.TestRegression {
+	changed := []api.TestRegression{}
+	for _, reg := range regs {
+		status, ok := statuses[reg.RegressionID]
+		if !ok {
+			continue
+		}
+		clearCount := reg.ClearCount.Int64
+		switch status {
+		case api.NotSignificant, api.SignificantImprovement:
+			if reg.Closed.Valid {
+				continue
+			}
+			clearCount++
+		case api.MissingSample, api.MissingBasis, api.MissingBasisAndSample:
+			// missing data tells nothing about the regression
+			continue
+		default:
+			clearCount = 0
+		}
+		if clearCount == reg.ClearCount.Int64 {
+			continue
+		}
+		reg.ClearCount = bigquery.NullInt64{Int64: clearCount, Valid: true}
+		if clearCount >= int64(r.ClearSnapshots) {
+			reg.Closed = bigquery.NullTimestamp{Timestamp: now, Valid: true}
+		}
+		changed = append(changed, reg)
+	}
+	return changed
 }
 
 func (rt *RegressionTracker) SyncComponentReport(release string, report *api.ComponentReport) error {
+	if !rt.dryRun {
+		if err := rt.backend.MigrateRegressionsTable(context.TODO()); err != nil {
+			return errors.Wrap(err, "error migrating the regressions table")
+		}
+	}
 	regressions, err := rt.backend.ListCurrentRegressions(release)
 	if err != nil {
 		return err
@@ -224,14 +419,45 @@ func (rt *RegressionTracker) SyncComponentReport(release string, report *api.Com
 
 	// Now we want to close any open regressions that are not appearing in the latest report:
 	now := time.Now()
-	for _, regression := range regressions {
-		var matched bool
+	isMatched := func(regression api.TestRegression) bool {
 		for _, m := range matchedOpenRegressions {
 			if reflect.DeepEqual(m, regression) {
-				matched = true
-				break
+				return true
+			}
+		}
+		return false
+	}
+	var cleared map[string]bool
+	if rt.reconciler != nil {
+		// regressions the report has no data for are left out, so they are not counted as clear
+		statuses := map[string]api.ComponentReportStatus{}
+		for regressionID, status := range report.RegressionStatuses {
+			statuses[regressionID] = status
+		}
+		for _, regression := range regressions {
+			if isMatched(regression) {
+				statuses[regression.RegressionID] = api.SignificantRegression
+			}
+		}
+		cleared = map[string]bool{}
+		for _, regression := range rt.reconciler.Reconcile(regressions, statuses, now) {
+			if !rt.dryRun {
+				if err := rt.backend.RecordClearCount(regression.RegressionID, int(regression.ClearCount.Int64)); err != nil {
+					rLog.WithError(err).Errorf("error recording clear count of regression: %v", regression)
+					return errors.Wrap(err, "error recording clear count of regression")
+				}
+			}
+			if regression.Closed.Valid {
+				cleared[regression.RegressionID] = true
 			}
 		}
+	}
+	for _, regression := range regressions {
+		matched := isMatched(regression)
+		if !matched && cleared != nil && !cleared[regression.RegressionID] {
+			rLog.Infof("regression no longer appearing in the report has not cleared long enough to close: %v", regression)
+			continue
+		}
 		// If we didn't match to an active test regression, and this record isn't already closed, close it.
 		if !matched && !regression.Closed.Valid {
 			rLog.Infof("found a regression no longer appearing in the report which should be closed: %v", regression)
@@ -284,6 +510,108 @@ func FindOpenRegression(release string,
 	return nil
 }
 
+// StaleOpenRegressions returns the regressions that are still open and were opened more than maxAge ago.
+// Regressions that never close often point at a tracking failure rather than a real long standing issue.
+func StaleOpenRegressions(regs []api.TestRegression, maxAge time.Duration) []api.TestRegression {
+	return staleOpenRegressions(regs, maxAge, time.Now())
+}
+
+func staleOpenRegressions(regs []api.TestRegression, maxAge time.Duration, now time.Time) []api.TestRegression {
+	stale := []api.TestRegression{}
+	for _, reg := range regs {
+		if reg.Closed.Valid {
+			continue
+		}
+		if now.Sub(reg.Opened) > maxAge {
+			stale = append(stale, reg)
+		}
+	}
+	return stale
+}
+
+// JiraLeadTimes returns, for each regression with a recorded Jira issue, the time from the
+// regression opening to the issue being filed, in the order of regs. This measures how quickly
+// regressions are triaged. An issue filed before the regression opened, such as a known issue,
+// has a lead time of zero.
+func JiraLeadTimes(regs []api.TestRegression) []time.Duration {
+	leadTimes := []time.Duration{}
+	for _, reg := range regs {
+		if !reg.JiraCreated.Valid {
+			continue
+		}
+		leadTime := reg.JiraCreated.Timestamp.Sub(reg.Opened)
+		if leadTime < 0 {
+			leadTime = 0
+		}
+		leadTimes = append(leadTimes, leadTime)
+	}
+	return leadTimes
+}
+
+// RecurringRegressions returns the current regressions that were also regressed in the prior release,
+// matching on test ID and variants regardless of release. These point at chronic problems.
+func RecurringRegressions(current, prior []api.TestRegression) []api.TestRegression {
+	priorKeys := map[string]struct{}{}
+	for _, reg := range prior {
+		priorKeys[regressionKey(reg)] = struct{}{}
+	}
+	recurring := []api.TestRegression{}
+	for _, reg := range current {
+		if _, ok := priorKeys[regressionKey(reg)]; ok {
+			recurring = append(recurring, reg)
+		}
+	}
+	return recurring
+}
+
+// regressionKey identifies the regressed test and variants independent of the release and
+// the order the variants were recorded in.
+func regressionKey(reg api.TestRegression) string {
+	variants := make([]string, 0, len(reg.Variants))
+	for _, v := range reg.Variants {
+		variants = append(variants, v.Key+"="+v.Value)
+	}
+	sort.Strings(variants)
+	return reg.TestID + ":" + strings.Join(variants, ",")
+}
+
+// SortOpenRegressions sets the age of each open regression as of now and, for regressions of a
+// release in reports, the latest status of the regressed test in that release's report. The
+// regressions are then ordered most severe first, those without a status last, and oldest first
+// within a severity.
+func SortOpenRegressions(regs []api.OpenTestRegression, reports map[string]api.ComponentReport, now time.Time) {
+	summaries := map[string][]api.ComponentReportTestSummary{}
+	for release, report := range reports {
+		for _, row := range report.Rows {
+			for _, column := range row.Columns {
+				summaries[release] = append(summaries[release], column.RegressedTests...)
+				for _, triagedIncident := range column.TriagedIncidents {
+					summaries[release] = append(summaries[release], triagedIncident.ComponentReportTestSummary)
+				}
+			}
+		}
+	}
+	for i := range regs {
+		regs[i].AgeDays = int(now.Sub(regs[i].Opened).Hours() / 24)
+		for _, summary := range summaries[regs[i].Release] {
+			if FindOpenRegression(regs[i].Release, summary, []api.TestRegression{regs[i].TestRegression}) != nil {
+				status := summary.Status
+				regs[i].Status = &status
+				break
+			}
+		}
+	}
+	sort.SliceStable(regs, func(i, j int) bool {
+		if (regs[i].Status == nil) != (regs[j].Status == nil) {
+			return regs[j].Status == nil
+		}
+		if regs[i].Status != nil && *regs[i].Status != *regs[j].Status {
+			return regs[i].Status.WorseThan(*regs[j].Status)
+		}
+		return regs[i].Opened.Before(regs[j].Opened)
+	})
+}
+
 func findVariant(variantName string, testReg api.TestRegression) string {
 	for _, v := range testReg.Variants {
 		if v.Key == variantName {
@@ -0,0 +1,382 @@
+package tracker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/apis/api"
+)
+
+func TestStaleOpenRegressions(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	fresh := api.TestRegression{RegressionID: "fresh", Opened: now.Add(-24 * time.Hour)}
+	ancient := api.TestRegression{RegressionID: "ancient", Opened: now.Add(-180 * 24 * time.Hour)}
+	ancientClosed := api.TestRegression{
+		RegressionID: "ancient-closed",
+		Opened:       now.Add(-180 * 24 * time.Hour),
+		Closed:       bigquery.NullTimestamp{Timestamp: now.Add(-90 * 24 * time.Hour), Valid: true},
+	}
+	borderline := api.TestRegression{RegressionID: "borderline", Opened: now.Add(-30 * 24 * time.Hour)}
+
+	tests := []struct {
+		name     string
+		regs     []api.TestRegression
+		maxAge   time.Duration
+		expected []string
+	}{
+		{
+			name:     "only old open regressions are stale",
+			regs:     []api.TestRegression{fresh, ancient, ancientClosed},
+			maxAge:   30 * 24 * time.Hour,
+			expected: []string{"ancient"},
+		},
+		{
+			name:     "exactly max age is not stale",
+			regs:     []api.TestRegression{borderline},
+			maxAge:   30 * 24 * time.Hour,
+			expected: []string{},
+		},
+		{
+			name:     "everything open is stale with a short max age",
+			regs:     []api.TestRegression{fresh, ancient, ancientClosed},
+			maxAge:   time.Hour,
+			expected: []string{"fresh", "ancient"},
+		},
+		{
+			name:     "no regressions",
+			maxAge:   time.Hour,
+			expected: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := []string{}
+			for _, reg := range staleOpenRegressions(tt.regs, tt.maxAge, now) {
+				ids = append(ids, reg.RegressionID)
+			}
+			assert.Equal(t, tt.expected, ids)
+		})
+	}
+}
+
+func TestRecurringRegressions(t *testing.T) {
+	variants := func(platform, network string) []api.ComponentReportVariant {
+		return []api.ComponentReportVariant{
+			{Key: "Platform", Value: platform},
+			{Key: "Network", Value: network},
+		}
+	}
+	prior := []api.TestRegression{
+		{RegressionID: "prior-1", Release: "4.14", TestID: "test-1", Variants: variants("aws", "ovn")},
+		{RegressionID: "prior-2", Release: "4.14", TestID: "test-2", Variants: variants("gcp", "ovn")},
+	}
+	current := []api.TestRegression{
+		{RegressionID: "recurring", Release: "4.15", TestID: "test-1", Variants: variants("aws", "ovn")},
+		{
+			RegressionID: "recurring-reordered",
+			Release:      "4.15",
+			TestID:       "test-2",
+			Variants:     []api.ComponentReportVariant{{Key: "Network", Value: "ovn"}, {Key: "Platform", Value: "gcp"}},
+		},
+		{RegressionID: "novel-variant", Release: "4.15", TestID: "test-1", Variants: variants("aws", "sdn")},
+		{RegressionID: "novel-test", Release: "4.15", TestID: "test-3", Variants: variants("aws", "ovn")},
+	}
+
+	recurring := RecurringRegressions(current, prior)
+	ids := []string{}
+	for _, reg := range recurring {
+		ids = append(ids, reg.RegressionID)
+	}
+	assert.Equal(t, []string{"recurring", "recurring-reordered"}, ids)
+	assert.Empty(t, RecurringRegressions(current, nil))
+}
+
+func TestInsertedTestRegressionColumns(t *testing.T) {
+	columnNames := func(schema bigquery.Schema) []string {
+		names := []string{}
+		for _, field := range schema {
+			names = append(names, field.Name)
+		}
+		return names
+	}
+	full, err := bigquery.InferSchema(api.TestRegression{})
+	assert.NoError(t, err)
+	inserted, err := bigquery.InferSchema(insertedTestRegression{})
+	assert.NoError(t, err)
+	added := map[string]bool{}
+	for _, field := range addedRegressionColumns {
+		assert.Contains(t, columnNames(full), field.Name)
+		assert.False(t, field.Required, "added column %s must be nullable", field.Name)
+		added[field.Name] = true
+	}
+	// every column is inserted but those added by the migration
+	expected := []string{}
+	for _, name := range columnNames(full) {
+		if !added[name] {
+			expected = append(expected, name)
+		}
+	}
+	assert.Equal(t, expected, columnNames(inserted))
+}
+
+func TestJiraLeadTimes(t *testing.T) {
+	opened := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	jira := func(after time.Duration) bigquery.NullTimestamp {
+		return bigquery.NullTimestamp{Timestamp: opened.Add(after), Valid: true}
+	}
+	regs := []api.TestRegression{
+		{RegressionID: "filed-after-a-day", Opened: opened, JiraCreated: jira(24 * time.Hour)},
+		{RegressionID: "never-filed", Opened: opened},
+		{RegressionID: "filed-after-an-hour", Opened: opened, JiraCreated: jira(time.Hour)},
+		{RegressionID: "known-issue", Opened: opened, JiraCreated: jira(-time.Hour)},
+	}
+	assert.Equal(t, []time.Duration{24 * time.Hour, time.Hour, 0}, JiraLeadTimes(regs))
+	assert.Empty(t, JiraLeadTimes([]api.TestRegression{{RegressionID: "never-filed", Opened: opened}}))
+}
+
+// fakeRegressionStore serves a fixed set of regressions.
+type fakeRegressionStore struct {
+	regressions []api.TestRegression
+}
+
+func (f *fakeRegressionStore) ListCurrentRegressions(release string) ([]api.TestRegression, error) {
+	current := []api.TestRegression{}
+	for _, reg := range f.regressions {
+		if reg.Release == release && !reg.Closed.Valid {
+			current = append(current, reg)
+		}
+	}
+	return current, nil
+}
+
+func (f *fakeRegressionStore) ListTestRegressions(_ context.Context, testID string) ([]api.TestRegression, error) {
+	regs := []api.TestRegression{}
+	for _, reg := range f.regressions {
+		if reg.TestID == testID {
+			regs = append(regs, reg)
+		}
+	}
+	return regs, nil
+}
+
+func (f *fakeRegressionStore) ListOpenRegressions(context.Context) ([]api.OpenTestRegression, error) {
+	open := []api.OpenTestRegression{}
+	for _, reg := range f.regressions {
+		if !reg.Closed.Valid {
+			open = append(open, api.OpenTestRegression{TestRegression: reg})
+		}
+	}
+	return open, nil
+}
+
+func (f *fakeRegressionStore) OpenRegression(string, api.ComponentReportTestSummary) (*api.TestRegression, error) {
+	return nil, nil
+}
+
+func (f *fakeRegressionStore) ReOpenRegression(string) error {
+	return nil
+}
+
+func (f *fakeRegressionStore) CloseRegression(regressionID string, closedAt time.Time) error {
+	for i := range f.regressions {
+		if f.regressions[i].RegressionID == regressionID {
+			f.regressions[i].Closed = bigquery.NullTimestamp{Timestamp: closedAt, Valid: true}
+		}
+	}
+	return nil
+}
+
+func (f *fakeRegressionStore) RecordJiraCreated(string, time.Time) error {
+	return nil
+}
+
+func (f *fakeRegressionStore) RecordClearCount(regressionID string, clearCount int) error {
+	for i := range f.regressions {
+		if f.regressions[i].RegressionID == regressionID {
+			f.regressions[i].ClearCount = bigquery.NullInt64{Int64: int64(clearCount), Valid: true}
+		}
+	}
+	return nil
+}
+
+func (f *fakeRegressionStore) MigrateRegressionsTable(context.Context) error {
+	return nil
+}
+
+func TestSortOpenRegressions(t *testing.T) {
+	now := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+	variants := func(platform string) []api.ComponentReportVariant {
+		return []api.ComponentReportVariant{
+			{Key: "Platform", Value: platform},
+			{Key: "Architecture", Value: "amd64"},
+			{Key: "Network", Value: "ovn"},
+			{Key: "Upgrade", Value: "upgrade-micro"},
+			{Key: "Variant", Value: "standard"},
+		}
+	}
+	summary := func(testID, platform string, status api.ComponentReportStatus) api.ComponentReportTestSummary {
+		return api.ComponentReportTestSummary{
+			ComponentReportTestIdentification: api.ComponentReportTestIdentification{
+				ComponentReportRowIdentification: api.ComponentReportRowIdentification{TestID: testID},
+				ComponentReportColumnIdentification: api.ComponentReportColumnIdentification{
+					Platform: platform, Arch: "amd64", Network: "ovn", Upgrade: "upgrade-micro", Variant: "standard",
+				},
+			},
+			Status: status,
+		}
+	}
+	open := func(id, release, testID, platform string, days int) api.OpenTestRegression {
+		return api.OpenTestRegression{TestRegression: api.TestRegression{
+			RegressionID: id,
+			Release:      release,
+			TestID:       testID,
+			Opened:       now.Add(-time.Duration(days)*24*time.Hour - time.Hour),
+			Variants:     variants(platform),
+		}}
+	}
+	regs := []api.OpenTestRegression{
+		open("no-report", "4.15", "1", "aws", 30),
+		open("significant-new", "4.16", "1", "aws", 1),
+		open("extreme", "4.16", "2", "aws", 2),
+		open("significant-old", "4.16", "3", "aws", 9),
+		open("triaged", "4.16", "4", "aws", 20),
+		open("recovered", "4.16", "1", "gcp", 40),
+	}
+	reports := map[string]api.ComponentReport{
+		"4.16": {Rows: []api.ComponentReportRow{
+			{Columns: []api.ComponentReportColumn{
+				{
+					RegressedTests: []api.ComponentReportTestSummary{
+						summary("1", "aws", api.SignificantRegression),
+						summary("2", "aws", api.ExtremeRegression),
+						summary("3", "aws", api.SignificantRegression),
+					},
+					TriagedIncidents: []api.ComponentReportTriageIncidentSummary{
+						{ComponentReportTestSummary: summary("4", "aws", api.SignificantTriagedRegression)},
+					},
+				},
+			}},
+		}},
+	}
+
+	SortOpenRegressions(regs, reports, now)
+	ids := []string{}
+	for _, reg := range regs {
+		ids = append(ids, reg.RegressionID)
+	}
+	assert.Equal(t, []string{"extreme", "significant-old", "significant-new", "triaged", "recovered", "no-report"}, ids)
+	assert.Equal(t, 2, regs[0].AgeDays)
+	assert.Equal(t, api.ExtremeRegression, *regs[0].Status)
+	assert.Nil(t, regs[4].Status)
+	assert.Equal(t, 30, regs[5].AgeDays)
+}
+
+func TestRegressionReconciler(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	reg := api.TestRegression{RegressionID: "reg", Release: "4.16", TestID: "test-1"}
+	tests := []struct {
+		name      string
+		snapshots []api.ComponentReportStatus
+		// closedAt is the index of the snapshot closing the regression, -1 when it stays open
+		closedAt int
+	}{
+		{
+			name:      "closes after enough clear snapshots",
+			snapshots: []api.ComponentReportStatus{api.NotSignificant, api.SignificantImprovement, api.NotSignificant},
+			closedAt:  2,
+		},
+		{
+			name:      "regressing again starts over",
+			snapshots: []api.ComponentReportStatus{api.NotSignificant, api.NotSignificant, api.SignificantRegression, api.NotSignificant, api.NotSignificant, api.NotSignificant},
+			closedAt:  5,
+		},
+		{
+			name:      "flapping never closes",
+			snapshots: []api.ComponentReportStatus{api.NotSignificant, api.ExtremeRegression, api.NotSignificant, api.SignificantTriagedRegression, api.NotSignificant, api.RegressionWarning},
+			closedAt:  -1,
+		},
+		{
+			name:      "missing data neither counts nor starts over",
+			snapshots: []api.ComponentReportStatus{api.NotSignificant, api.MissingSample, api.NotSignificant, api.MissingBasis, api.NotSignificant},
+			closedAt:  4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := reg
+			closedAt := -1
+			for i, status := range tt.snapshots {
+				// a new reconciler every time, the count is carried by the regression
+				changed := NewRegressionReconciler(3).Reconcile([]api.TestRegression{reg}, map[string]api.ComponentReportStatus{"reg": status}, now)
+				if len(changed) == 0 {
+					continue
+				}
+				assert.Equal(t, 1, len(changed))
+				if changed[0].Closed.Valid && !reg.Closed.Valid {
+					closedAt = i
+					assert.Equal(t, bigquery.NullTimestamp{Timestamp: now, Valid: true}, changed[0].Closed)
+				}
+				reg = changed[0]
+			}
+			assert.Equal(t, tt.closedAt, closedAt)
+		})
+	}
+
+	t.Run("closed and untracked regressions are left alone", func(t *testing.T) {
+		reconciler := NewRegressionReconciler(1)
+		closedReg := reg
+		closedReg.Closed = bigquery.NullTimestamp{Timestamp: now, Valid: true}
+		assert.Empty(t, reconciler.Reconcile([]api.TestRegression{closedReg}, map[string]api.ComponentReportStatus{"reg": api.NotSignificant}, now))
+		assert.Empty(t, reconciler.Reconcile([]api.TestRegression{reg}, map[string]api.ComponentReportStatus{}, now))
+	})
+
+	t.Run("regressing again resets the count of a closed regression", func(t *testing.T) {
+		closedReg := reg
+		closedReg.Closed = bigquery.NullTimestamp{Timestamp: now, Valid: true}
+		closedReg.ClearCount = bigquery.NullInt64{Int64: 3, Valid: true}
+		changed := NewRegressionReconciler(3).Reconcile([]api.TestRegression{closedReg}, map[string]api.ComponentReportStatus{"reg": api.SignificantRegression}, now)
+		assert.Equal(t, 1, len(changed))
+		assert.Equal(t, bigquery.NullInt64{Int64: 0, Valid: true}, changed[0].ClearCount)
+	})
+}
+
+func TestSyncComponentReportWithReconciler(t *testing.T) {
+	opened := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	newStore := func() *fakeRegressionStore {
+		return &fakeRegressionStore{regressions: []api.TestRegression{
+			{RegressionID: "reg", Release: "4.16", TestID: "test-1", Opened: opened},
+		}}
+	}
+	clearReport := &api.ComponentReport{RegressionStatuses: map[string]api.ComponentReportStatus{"reg": api.NotSignificant}}
+	missingSampleReport := &api.ComponentReport{RegressionStatuses: map[string]api.ComponentReportStatus{"reg": api.MissingSample}}
+	noDataReport := &api.ComponentReport{}
+
+	store := newStore()
+	assert.NoError(t, NewRegressionTracker(store, false).SyncComponentReport("4.16", noDataReport))
+	assert.True(t, store.regressions[0].Closed.Valid, "without a reconciler regressions close as soon as they leave the report")
+
+	// a new tracker and reconciler for every sync, as the metrics refresh does
+	sync := func(store *fakeRegressionStore, report *api.ComponentReport) {
+		assert.NoError(t, NewRegressionTracker(store, false).WithReconciler(NewRegressionReconciler(2)).SyncComponentReport("4.16", report))
+	}
+	store = newStore()
+	sync(store, clearReport)
+	assert.False(t, store.regressions[0].Closed.Valid)
+	assert.Equal(t, bigquery.NullInt64{Int64: 1, Valid: true}, store.regressions[0].ClearCount)
+	sync(store, noDataReport)
+	sync(store, missingSampleReport)
+	assert.False(t, store.regressions[0].Closed.Valid, "reports without data for the test do not count as clear")
+	assert.Equal(t, bigquery.NullInt64{Int64: 1, Valid: true}, store.regressions[0].ClearCount)
+	sync(store, clearReport)
+	assert.True(t, store.regressions[0].Closed.Valid)
+
+	store = newStore()
+	dryRun := NewRegressionTracker(store, true).WithReconciler(NewRegressionReconciler(1))
+	assert.NoError(t, dryRun.SyncComponentReport("4.16", clearReport))
+	assert.False(t, store.regressions[0].ClearCount.Valid, "dry runs store nothing")
+	assert.False(t, store.regressions[0].Closed.Valid, "dry runs store nothing")
+}
@@ -0,0 +1,63 @@
+package releaseloader
+
+import (
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// BumpedComponents returns the components whose version changed from the previous payload.
+func (c ChangeLog) BumpedComponents() []ChangeLogComponent {
+	bumped := []ChangeLogComponent{}
+	for _, component := range c.Components {
+		if component.From != "" && component.From != component.Version {
+			bumped = append(bumped, component)
+		}
+	}
+	return bumped
+}
+
+// SuspectChangeLogComponents returns the components bumped by the payloads created within window
+// before onset, usually the first failure of a regression, as candidates for having caused it.
+// Payloads closest to the onset come first, and a bump to the same version is listed once.
+// Changelogs with an unparsable creation time are skipped.
+func SuspectChangeLogComponents(onset time.Time, window time.Duration, changeLogs []ChangeLog) []ChangeLogComponent {
+	type payload struct {
+		created time.Time
+		bumped  []ChangeLogComponent
+	}
+	payloads := []payload{}
+	for _, changeLog := range changeLogs {
+		created, err := time.Parse(time.RFC3339, changeLog.To.Created)
+		if err != nil {
+			log.WithError(err).Warningf("skipping changelog of %s with unparsable creation time", changeLog.To.Name)
+			continue
+		}
+		if created.After(onset) || created.Before(onset.Add(-window)) {
+			continue
+		}
+		payloads = append(payloads, payload{created: created, bumped: changeLog.BumpedComponents()})
+	}
+	sort.SliceStable(payloads, func(i, j int) bool {
+		return payloads[i].created.After(payloads[j].created)
+	})
+
+	type bump struct {
+		name    string
+		version string
+	}
+	seen := map[bump]bool{}
+	suspects := []ChangeLogComponent{}
+	for _, p := range payloads {
+		for _, component := range p.bumped {
+			key := bump{name: component.Name, version: component.Version}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			suspects = append(suspects, component)
+		}
+	}
+	return suspects
+}
@@ -0,0 +1,61 @@
+package releaseloader
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuspectChangeLogComponents(t *testing.T) {
+	onset := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	changeLog := func(created time.Time, components ...ChangeLogComponent) ChangeLog {
+		return ChangeLog{
+			To:         ChangeLogRelease{Name: created.Format("4.16.0-0.nightly-2006-01-02-150405"), Created: created.Format(time.RFC3339)},
+			Components: components,
+		}
+	}
+	kube := ChangeLogComponent{Name: "Kubernetes", Version: "1.29.2", From: "1.29.1"}
+	rhcos := ChangeLogComponent{Name: "Red Hat Enterprise Linux CoreOS", Version: "416.94.202403081200-0", From: "416.94.202403011200-0"}
+	unchangedKube := ChangeLogComponent{Name: "Kubernetes", Version: "1.29.2", From: "1.29.2"}
+	changeLogs := []ChangeLog{
+		// long before the onset
+		changeLog(onset.Add(-72*time.Hour), ChangeLogComponent{Name: "Kubernetes", Version: "1.29.1", From: "1.29.0"}),
+		changeLog(onset.Add(-12*time.Hour), rhcos, unchangedKube),
+		changeLog(onset.Add(-2*time.Hour), kube),
+		// after the onset, so it cannot have caused it
+		changeLog(onset.Add(time.Hour), ChangeLogComponent{Name: "Kubernetes", Version: "1.29.3", From: "1.29.2"}),
+		{To: ChangeLogRelease{Name: "4.16.0-0.nightly-unknown", Created: "yesterday"}, Components: []ChangeLogComponent{kube}},
+	}
+
+	tests := []struct {
+		name     string
+		window   time.Duration
+		expected []ChangeLogComponent
+	}{
+		{
+			name:     "bumps within the window, closest to the onset first",
+			window:   24 * time.Hour,
+			expected: []ChangeLogComponent{kube, rhcos},
+		},
+		{
+			name:     "narrow window",
+			window:   4 * time.Hour,
+			expected: []ChangeLogComponent{kube},
+		},
+		{
+			name:     "no payloads within the window",
+			window:   time.Hour,
+			expected: []ChangeLogComponent{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SuspectChangeLogComponents(onset, tt.window, changeLogs))
+		})
+	}
+
+	// a bump carried by two payloads, such as a retried payload, is listed once
+	repeated := []ChangeLog{changeLog(onset.Add(-time.Hour), kube), changeLog(onset.Add(-2*time.Hour), kube)}
+	assert.Equal(t, []ChangeLogComponent{kube}, SuspectChangeLogComponents(onset, 24*time.Hour, repeated))
+}
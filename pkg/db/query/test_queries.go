@@ -189,6 +189,39 @@ func LoadBugsForTest(dbc *db.DB, testName string, filterClosed bool) ([]models.B
 	return test.Bugs, nil
 }
 
+// LoadOpenBugsForTests returns the open bugs linked to each of the given tests, keyed by test
+// name, in a single query. Tests without open bugs are left out.
+func LoadOpenBugsForTests(dbc *db.DB, testNames []string) (map[string][]models.Bug, error) {
+	results := map[string][]models.Bug{}
+	if len(testNames) == 0 {
+		return results, nil
+	}
+
+	rows := []struct {
+		TestName string
+		Key      string
+		URL      string
+		Summary  string
+	}{}
+	res := dbc.DB.Table("bug_tests").
+		Select("tests.name AS test_name, bugs.key, bugs.url, bugs.summary").
+		Joins("JOIN tests ON tests.id = bug_tests.test_id").
+		Joins("JOIN bugs ON bugs.id = bug_tests.bug_id").
+		Where("tests.name IN ?", testNames).
+		Where("bugs.deleted_at IS NULL").
+		Where("UPPER(bugs.status) != 'CLOSED' and UPPER(bugs.status) != 'VERIFIED'").
+		Order("tests.name, bugs.key").
+		Scan(&rows)
+	if res.Error != nil {
+		return results, res.Error
+	}
+	for _, row := range rows {
+		results[row.TestName] = append(results[row.TestName], models.Bug{Key: row.Key, URL: row.URL, Summary: row.Summary})
+	}
+	log.Infof("found open bugs for %d of %d tests", len(results), len(testNames))
+	return results, nil
+}
+
 // TestsByNURPAndStandardDeviation returns a test report for every test in the db matching the given substrings, separated by variant.
 // Result will include current and previous test rates such as passing, flaking, failing rates.
 // In addition, it includes the following calculated rates to help identify bad nurps.
@@ -15,6 +15,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/openshift/sippy/pkg/apis/cache"
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
 	bqclient "github.com/openshift/sippy/pkg/bigquery"
 	"github.com/openshift/sippy/pkg/dataloader/releaseloader"
 	"github.com/openshift/sippy/pkg/filter"
@@ -135,9 +136,13 @@ func getReleaseStatus(releases []query.Release, release string) string {
 
 // presume in a historical context there won't be scraping of these metrics
 // pinning the time just to be consistent
+// regressionClearSnapshots is how many consecutive refreshes a tracked regression must be clear in
+// before it closes, see tracker.RegressionReconciler. Zero closes it on the first refresh it no
+// longer appears regressed in.
 func RefreshMetricsDB(dbc *db.DB, bqc *bqclient.Client, prowURL, gcsBucket string,
 	variantManager testidentification.VariantManager, reportEnd time.Time,
-	cacheOptions cache.RequestOptions, maintainRegressionTables bool) error {
+	cacheOptions cache.RequestOptions, maintainRegressionTables bool, regressionClearSnapshots int,
+	componentReadinessConfig v1.ComponentReadinessConfig) error {
 	start := time.Now()
 	log.Info("beginning refresh metrics")
 	releases, err := api.GetReleases(dbc, bqc)
@@ -208,7 +213,7 @@ func RefreshMetricsDB(dbc *db.DB, bqc *bqclient.Client, prowURL, gcsBucket strin
 
 	// BigQuery metrics
 	if bqc != nil {
-		if err := refreshComponentReadinessMetrics(bqc, prowURL, gcsBucket, cacheOptions, maintainRegressionTables); err != nil {
+		if err := refreshComponentReadinessMetrics(bqc, prowURL, gcsBucket, cacheOptions, maintainRegressionTables, regressionClearSnapshots, componentReadinessConfig); err != nil {
 			log.WithError(err).Error("error refreshing component readiness metrics")
 		}
 
@@ -223,7 +228,8 @@ func RefreshMetricsDB(dbc *db.DB, bqc *bqclient.Client, prowURL, gcsBucket strin
 }
 
 func refreshComponentReadinessMetrics(client *bqclient.Client, prowURL, gcsBucket string,
-	cacheOptions cache.RequestOptions, maintainRegressionTables bool) error {
+	cacheOptions cache.RequestOptions, maintainRegressionTables bool, regressionClearSnapshots int,
+	componentReadinessConfig v1.ComponentReadinessConfig) error {
 	if client == nil || client.BQ == nil {
 		log.Warningf("not generating component readiness metrics as we don't have a bigquery client")
 		return nil
@@ -311,6 +317,7 @@ func refreshComponentReadinessMetrics(client *bqclient.Client, prowURL, gcsBucke
 		IgnoreMissing:    api.DefaultIgnoreMissing,
 		IgnoreDisruption: api.DefaultIgnoreDisruption,
 	}
+	componentReadinessConfig.ApplyAdvancedOptions(&advancedOption)
 
 	// Get report
 	report, errs := api.GetComponentReportFromBigQuery(client, prowURL, gcsBucket, baseRelease, sampleRelease, testIDOption, variantOption, excludeOption, advancedOption, cacheOptions)
@@ -341,6 +348,9 @@ func refreshComponentReadinessMetrics(client *bqclient.Client, prowURL, gcsBucke
 
 	// Maintain the test regressions table for anything new or now no longer appearing:
 	regressionTracker := tracker.NewRegressionTracker(tracker.NewBigQueryRegressionStore(client), !maintainRegressionTables)
+	if regressionClearSnapshots > 0 {
+		regressionTracker.WithReconciler(tracker.NewRegressionReconciler(regressionClearSnapshots))
+	}
 	err = regressionTracker.SyncComponentReport(sampleRelease.Release, &report)
 	if err != nil {
 		return errors.Wrap(err, "regression tracker reported an error")
@@ -1,6 +1,7 @@
 package sippyserver
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/fs"
@@ -26,6 +27,7 @@ import (
 	"github.com/openshift/sippy/pkg/api/jobrunintervals"
 	apitype "github.com/openshift/sippy/pkg/apis/api"
 	"github.com/openshift/sippy/pkg/apis/cache"
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
 	"github.com/openshift/sippy/pkg/bigquery"
 	"github.com/openshift/sippy/pkg/dataloader/releaseloader"
 	"github.com/openshift/sippy/pkg/db"
@@ -60,6 +62,7 @@ func NewServer(
 	pinnedDateTime *time.Time,
 	cacheClient cache.Cache,
 	crTimeRoundingFactor time.Duration,
+	componentReadinessConfig v1.ComponentReadinessConfig,
 ) *Server {
 
 	server := &Server{
@@ -77,6 +80,8 @@ func NewServer(
 		gcsClient:            gcsClient,
 		cache:                cacheClient,
 		crTimeRoundingFactor: crTimeRoundingFactor,
+
+		componentReadinessConfig: componentReadinessConfig,
 	}
 
 	if bigQueryClient != nil {
@@ -115,6 +120,8 @@ type Server struct {
 	cache                cache.Cache
 	crTimeRoundingFactor time.Duration
 	capabilities         []string
+
+	componentReadinessConfig v1.ComponentReadinessConfig
 }
 
 func (s *Server) GetReportEnd() time.Time {
@@ -684,6 +691,251 @@ func (s *Server) jsonComponentReportFromBigQuery(w http.ResponseWriter, req *htt
 		})
 		return
 	}
+	// the report is cached, the bugs linked to its tests are looked up fresh on every request
+	if s.db != nil {
+		err = api.AnnotateKnownIssues(&outputs, func(testNames []string) (map[string][]models.Bug, error) {
+			return query.LoadOpenBugsForTests(s.db, testNames)
+		})
+		if err != nil {
+			log.WithError(err).Warning("error annotating component report with known issues")
+		}
+	}
+	api.RespondWithJSON(http.StatusOK, w, outputs)
+}
+
+// jsonComponentReportOptions reports the options a component report request resolves to, without
+// generating the report, so operators can debug requests producing unexpected results.
+func (s *Server) jsonComponentReportOptions(w http.ResponseWriter, req *http.Request) {
+	baseRelease, sampleRelease, testIDOption, variantOption, excludeOption, advancedOption, _, err := s.parseComponentReportRequest(req)
+	if err != nil {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": err.Error(),
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, apitype.ComponentReportRequestOptions{
+		BaseRelease:     baseRelease,
+		SampleRelease:   sampleRelease,
+		TestIDOptions:   testIDOption,
+		VariantOptions:  variantOption,
+		ExcludeOptions:  excludeOption,
+		AdvancedOptions: advancedOption,
+	})
+}
+
+// jsonComponentReportStreamFromBigQuery writes the rows of the component report as a JSON array, one
+// row at a time as they are generated, so very large reports are never held in memory as a whole.
+func (s *Server) jsonComponentReportStreamFromBigQuery(w http.ResponseWriter, req *http.Request) {
+	baseRelease, sampleRelease, testIDOption, variantOption, excludeOption, advancedOption, cacheOption, err := s.parseComponentReportRequest(req)
+	if err != nil {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+	rows := make(chan apitype.ComponentReportRow)
+	errsCh := make(chan []error, 1)
+	go func() {
+		errsCh <- api.StreamComponentReportFromBigQuery(
+			ctx,
+			s.bigQueryClient,
+			s.prowURL,
+			s.gcsBucket,
+			baseRelease,
+			sampleRelease,
+			testIDOption,
+			variantOption,
+			excludeOption,
+			advancedOption,
+			cacheOption,
+			rows,
+		)
+	}()
+
+	// Errors can only occur before the first row is sent, so the response status is
+	// not committed until either a row or an error arrives.
+	encoder := json.NewEncoder(w)
+	count := 0
+	for row := range rows {
+		if count == 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, err = w.Write([]byte("["))
+		} else {
+			_, err = w.Write([]byte(","))
+		}
+		if err == nil {
+			err = encoder.Encode(row)
+		}
+		if err != nil {
+			log.WithError(err).Warning("error streaming component report, stopping")
+			cancel()
+			break
+		}
+		count++
+	}
+	if errs := <-errsCh; len(errs) > 0 {
+		log.Warningf("%d errors were encountered while querying component from big query:", len(errs))
+		for _, err := range errs {
+			log.Error(err.Error())
+		}
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": fmt.Sprintf("error querying component from big query: %v", errs),
+		})
+		return
+	}
+	if err != nil {
+		return
+	}
+	if count == 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("["))
+	}
+	_, _ = w.Write([]byte("]"))
+}
+
+// jsonOpenRegressionsFromBigQuery lists the open regressions across releases, optionally filtered
+// by release and component. When the usual component report parameters are also given, the
+// regressions of the sample release are joined with their status in that report. Only that one
+// release is joined, regressions of other releases are listed without a status, as joining them
+// would take a full report per release.
+func (s *Server) jsonOpenRegressionsFromBigQuery(w http.ResponseWriter, req *http.Request) {
+	if s.bigQueryClient == nil {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": "open regressions API is only available when google-service-account-credential-file is configured",
+		})
+		return
+	}
+
+	var reports map[string]apitype.ComponentReport
+	if req.URL.Query().Get("baseRelease") != "" {
+		baseRelease, sampleRelease, testIDOption, variantOption, excludeOption, advancedOption, cacheOption, err := s.parseComponentReportRequest(req)
+		if err != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": err.Error(),
+			})
+			return
+		}
+		report, errs := api.GetComponentReportFromBigQuery(
+			s.bigQueryClient,
+			s.prowURL,
+			s.gcsBucket,
+			baseRelease,
+			sampleRelease,
+			testIDOption,
+			variantOption,
+			excludeOption,
+			advancedOption,
+			cacheOption,
+		)
+		if len(errs) > 0 {
+			log.Warningf("%d errors were encountered while querying component from big query:", len(errs))
+			for _, err := range errs {
+				log.Error(err.Error())
+			}
+			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+				"code":    http.StatusInternalServerError,
+				"message": fmt.Sprintf("error querying component from big query: %v", errs),
+			})
+			return
+		}
+		reports = map[string]apitype.ComponentReport{sampleRelease.Release: report}
+	}
+
+	regressions, err := api.GetOpenRegressionsFromBigQuery(req.Context(), s.bigQueryClient,
+		req.URL.Query().Get("release"), req.URL.Query().Get("component"), reports)
+	if err != nil {
+		log.WithError(err).Error("error querying open regressions from big query")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": fmt.Sprintf("error querying open regressions from big query: %v", err),
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, regressions)
+}
+
+func (s *Server) jsonObservedTestVariantsFromBigQuery(w http.ResponseWriter, req *http.Request) {
+	if s.bigQueryClient == nil {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": "component report API is only available when google-service-account-credential-file is configured",
+		})
+		return
+	}
+	_, sampleRelease, testIDOption, _, _, _, cacheOption, err := s.parseComponentReportRequest(req)
+	if err == nil && testIDOption.TestID == "" {
+		err = fmt.Errorf("missing testId")
+	}
+	if err != nil {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": err.Error(),
+		})
+		return
+	}
+	outputs, errs := api.GetObservedTestVariantsFromBigQuery(req.Context(), s.bigQueryClient, s.gcsBucket, sampleRelease, testIDOption.TestID, cacheOption)
+	if len(errs) > 0 {
+		log.Warningf("%d errors were encountered while querying observed test variants from big query:", len(errs))
+		for _, err := range errs {
+			log.Error(err.Error())
+		}
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": fmt.Sprintf("error querying observed test variants from big query: %v", errs),
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, outputs)
+}
+
+func (s *Server) jsonNewTestsFromBigQuery(w http.ResponseWriter, req *http.Request) {
+	if s.bigQueryClient == nil {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": "component report API is only available when google-service-account-credential-file is configured",
+		})
+		return
+	}
+	baseRelease, sampleRelease, testIDOption, variantOption, excludeOption, advancedOption, cacheOption, err := s.parseComponentReportRequest(req)
+	if err != nil {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": err.Error(),
+		})
+		return
+	}
+	outputs, errs := api.GetNewTestsFromBigQuery(
+		s.bigQueryClient,
+		s.prowURL,
+		s.gcsBucket,
+		baseRelease,
+		sampleRelease,
+		testIDOption,
+		variantOption,
+		excludeOption,
+		advancedOption,
+		cacheOption)
+	if len(errs) > 0 {
+		log.Warningf("%d errors were encountered while querying new tests from big query:", len(errs))
+		for _, err := range errs {
+			log.Error(err.Error())
+		}
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": fmt.Sprintf("error querying new tests from big query: %v", errs),
+		})
+		return
+	}
 	api.RespondWithJSON(http.StatusOK, w, outputs)
 }
 
@@ -718,6 +970,14 @@ func (s *Server) jsonComponentReportTestDetailsFromBigQuery(w http.ResponseWrite
 		})
 		return
 	}
+	if strings.Contains(req.Header.Get("Accept"), "text/csv") {
+		w.Header().Set("Content-Type", "text/csv;charset=UTF-8")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		if err := outputs.WriteCSV(w); err != nil {
+			log.WithError(err).Error("error writing component test details as csv")
+		}
+		return
+	}
 	api.RespondWithJSON(http.StatusOK, w, outputs)
 }
 
@@ -747,19 +1007,39 @@ func (s *Server) parseComponentReportRequest(req *http.Request) (
 		return
 	}
 
-	timeStr := req.URL.Query().Get("baseStartTime")
-	baseRelease.Start, err = util.ParseCRReleaseTime(timeStr, s.crTimeRoundingFactor)
-	if err != nil {
-		err = fmt.Errorf("base start time in wrong format")
-		return
+	// baseRollingWindow replaces the base start and end times with the window preceding the sample
+	if rollingWindowStr := req.URL.Query().Get("baseRollingWindow"); rollingWindowStr != "" {
+		baseRelease.RollingBaseline, err = time.ParseDuration(rollingWindowStr)
+		if err != nil || baseRelease.RollingBaseline <= 0 {
+			err = fmt.Errorf("base rolling window is not a valid duration")
+			return
+		}
+		if req.URL.Query().Get("baseStartTime") != "" || req.URL.Query().Get("baseEndTime") != "" {
+			err = fmt.Errorf("base rolling window and base start or end time cannot both be specified")
+			return
+		}
+	} else {
+		timeStr := req.URL.Query().Get("baseStartTime")
+		baseRelease.Start, err = util.ParseCRReleaseTime(timeStr, s.crTimeRoundingFactor)
+		if err != nil {
+			err = fmt.Errorf("base start time in wrong format")
+			return
+		}
+		timeStr = req.URL.Query().Get("baseEndTime")
+		baseRelease.End, err = util.ParseCRReleaseTime(timeStr, s.crTimeRoundingFactor)
+		if err != nil {
+			err = fmt.Errorf("base end time in wrong format")
+			return
+		}
 	}
-	timeStr = req.URL.Query().Get("baseEndTime")
-	baseRelease.End, err = util.ParseCRReleaseTime(timeStr, s.crTimeRoundingFactor)
-	if err != nil {
-		err = fmt.Errorf("base end time in wrong format")
-		return
+	if maxRunAgeStr := req.URL.Query().Get("baseMaxRunAge"); maxRunAgeStr != "" {
+		baseRelease.MaxRunAge, err = time.ParseDuration(maxRunAgeStr)
+		if err != nil || baseRelease.MaxRunAge < 0 {
+			err = fmt.Errorf("base max run age is not a valid duration")
+			return
+		}
 	}
-	timeStr = req.URL.Query().Get("sampleStartTime")
+	timeStr := req.URL.Query().Get("sampleStartTime")
 	sampleRelease.Start, err = util.ParseCRReleaseTime(timeStr, s.crTimeRoundingFactor)
 	if err != nil {
 		err = fmt.Errorf("sample start time in wrong format")
@@ -771,17 +1051,93 @@ func (s *Server) parseComponentReportRequest(req *http.Request) (
 		err = fmt.Errorf("sample end time in wrong format")
 		return
 	}
+	baseRelease = baseRelease.ResolveRollingBaseline(sampleRelease.Start)
+
+	// baseOverride=<variant>,<value>,<release>,<start>,<end> selects a different basis for a variant
+	for _, overrideStr := range req.URL.Query()["baseOverride"] {
+		parts := strings.Split(overrideStr, ",")
+		if len(parts) != 5 {
+			err = fmt.Errorf("base override %q is not in the format variant,value,release,start,end", overrideStr)
+			return
+		}
+		override := apitype.ComponentReportReleaseOverride{
+			Variant: parts[0],
+			Value:   parts[1],
+			Release: parts[2],
+		}
+		override.Start, err = util.ParseCRReleaseTime(parts[3], s.crTimeRoundingFactor)
+		if err != nil {
+			err = fmt.Errorf("base override start time in wrong format")
+			return
+		}
+		override.End, err = util.ParseCRReleaseTime(parts[4], s.crTimeRoundingFactor)
+		if err != nil {
+			err = fmt.Errorf("base override end time in wrong format")
+			return
+		}
+		baseRelease.VariantOverrides = append(baseRelease.VariantOverrides, override)
+	}
 
 	testIDOption.Component = req.URL.Query().Get("component")
 	testIDOption.Capability = req.URL.Query().Get("capability")
 	testIDOption.TestID = req.URL.Query().Get("testId")
+	if timeStr = req.URL.Query().Get("sampleSubWindowStartTime"); timeStr != "" {
+		testIDOption.SampleSubWindowStart, err = time.Parse(time.RFC3339, timeStr)
+		if err != nil {
+			err = fmt.Errorf("sample sub-window start time in wrong format")
+			return
+		}
+	}
+	if timeStr = req.URL.Query().Get("sampleSubWindowEndTime"); timeStr != "" {
+		testIDOption.SampleSubWindowEnd, err = time.Parse(time.RFC3339, timeStr)
+		if err != nil {
+			err = fmt.Errorf("sample sub-window end time in wrong format")
+			return
+		}
+	}
+	if !testIDOption.SampleSubWindowStart.IsZero() && !testIDOption.SampleSubWindowEnd.IsZero() &&
+		testIDOption.SampleSubWindowEnd.Before(testIDOption.SampleSubWindowStart) {
+		err = fmt.Errorf("sample sub-window ends before it starts")
+		return
+	}
 
 	variantOption.GroupBy = req.URL.Query().Get("groupBy")
+	if groupingPreset := req.URL.Query().Get("groupingPreset"); groupingPreset != "" {
+		if variantOption.GroupBy != "" {
+			err = fmt.Errorf("groupBy and groupingPreset cannot both be specified")
+			return
+		}
+		variantOption.GroupBy, err = api.ResolveGroupingPreset(s.componentReadinessConfig.GroupingPresets, groupingPreset)
+		if err != nil {
+			return
+		}
+	}
 	variantOption.Platform = req.URL.Query().Get("platform")
 	variantOption.Upgrade = req.URL.Query().Get("upgrade")
 	variantOption.Arch = req.URL.Query().Get("arch")
 	variantOption.Network = req.URL.Query().Get("network")
 	variantOption.Variant = req.URL.Query().Get("variant")
+	variantOption.Suite = req.URL.Query().Get("suite")
+	variantOption.BaseArch = req.URL.Query().Get("baseArch")
+	if variantOption.BaseArch != "" && variantOption.Arch == "" {
+		err = fmt.Errorf("arch is required to compare against base arch %q", variantOption.BaseArch)
+		return
+	}
+	// variantAlias=<variant>,<value>,<canonical value> merges a renamed variant value into its new name
+	for _, aliasStr := range req.URL.Query()["variantAlias"] {
+		parts := strings.Split(aliasStr, ",")
+		if len(parts) != 3 {
+			err = fmt.Errorf("variant alias %q is not in the format variant,value,canonical", aliasStr)
+			return
+		}
+		if variantOption.VariantAliases == nil {
+			variantOption.VariantAliases = map[string]map[string]string{}
+		}
+		if variantOption.VariantAliases[parts[0]] == nil {
+			variantOption.VariantAliases[parts[0]] = map[string]string{}
+		}
+		variantOption.VariantAliases[parts[0]][parts[1]] = parts[2]
+	}
 
 	excludeOption.ExcludePlatforms = req.URL.Query().Get("excludeClouds")
 	excludeOption.ExcludeArches = req.URL.Query().Get("excludeArches")
@@ -851,6 +1207,286 @@ func (s *Server) parseComponentReportRequest(req *http.Request) (
 		}
 	}
 
+	extremePassRateFloorStr := req.URL.Query().Get("extremePassRateFloor")
+	if extremePassRateFloorStr != "" {
+		advancedOption.ExtremePassRateFloor, err = strconv.Atoi(extremePassRateFloorStr)
+		if err != nil {
+			err = fmt.Errorf("extreme pass rate floor is not a number")
+			return
+		}
+		if advancedOption.ExtremePassRateFloor < 0 || advancedOption.ExtremePassRateFloor > 100 {
+			err = fmt.Errorf("extreme pass rate floor is not in the correct range")
+			return
+		}
+	}
+
+	minimumFailingJobsStr := req.URL.Query().Get("minimumFailingJobs")
+	if minimumFailingJobsStr != "" {
+		advancedOption.MinimumFailingJobs, err = strconv.Atoi(minimumFailingJobsStr)
+		if err != nil {
+			err = fmt.Errorf("minimum failing jobs is not a number")
+			return
+		}
+	}
+
+	includeCapabilityStatusesStr := req.URL.Query().Get("includeCapabilityStatuses")
+	if includeCapabilityStatusesStr != "" {
+		advancedOption.IncludeCapabilityStatuses, err = strconv.ParseBool(includeCapabilityStatusesStr)
+		if err != nil {
+			err = errors.WithMessage(err, "expected boolean for including capability statuses")
+			return
+		}
+	}
+
+	advancedOption.IncludeJobs = req.URL.Query()["includeJob"]
+	advancedOption.ExcludedJobRunURLs = req.URL.Query()["excludeJobRunURL"]
+
+	advancedOption.SortColumnsBy = apitype.ColumnSort(req.URL.Query().Get("sortColumnsBy"))
+	if advancedOption.SortColumnsBy != "" && advancedOption.SortColumnsBy != apitype.ColumnSortSeverity {
+		err = fmt.Errorf("unknown column sort %q", advancedOption.SortColumnsBy)
+		return
+	}
+
+	advancedOption.SortJobStatsBy = apitype.JobStatsSort(req.URL.Query().Get("sortJobStatsBy"))
+	if advancedOption.SortJobStatsBy != "" && advancedOption.SortJobStatsBy != apitype.JobStatsSortContribution {
+		err = fmt.Errorf("unknown job stats sort %q", advancedOption.SortJobStatsBy)
+		return
+	}
+
+	advancedOption.MultipleComparisonCorrection = apitype.MultipleComparisonCorrection(req.URL.Query().Get("multipleComparisonCorrection"))
+	switch advancedOption.MultipleComparisonCorrection {
+	case "", apitype.CorrectionBonferroni, apitype.CorrectionBenjaminiHochberg:
+	default:
+		err = fmt.Errorf("unknown multiple comparison correction %q", advancedOption.MultipleComparisonCorrection)
+		return
+	}
+
+	if maxGapStr := req.URL.Query().Get("maxBaseSampleGap"); maxGapStr != "" {
+		advancedOption.MaxBaseSampleGap, err = time.ParseDuration(maxGapStr)
+		if err != nil || advancedOption.MaxBaseSampleGap < 0 {
+			err = fmt.Errorf("max base sample gap is not a valid duration")
+			return
+		}
+	}
+
+	includeMDEStr := req.URL.Query().Get("includeMinimumDetectableEffect")
+	if includeMDEStr != "" {
+		advancedOption.IncludeMinimumDetectableEffect, err = strconv.ParseBool(includeMDEStr)
+		if err != nil {
+			err = errors.WithMessage(err, "expected boolean for including the minimum detectable effect")
+			return
+		}
+	}
+
+	separateMigratedSuitesStr := req.URL.Query().Get("separateMigratedSuites")
+	if separateMigratedSuitesStr != "" {
+		advancedOption.SeparateMigratedSuites, err = strconv.ParseBool(separateMigratedSuitesStr)
+		if err != nil {
+			err = errors.WithMessage(err, "expected boolean for separating migrated suites")
+			return
+		}
+	}
+
+	minimumBasisRunsStr := req.URL.Query().Get("minimumBasisRuns")
+	if minimumBasisRunsStr != "" {
+		advancedOption.MinimumBasisRuns, err = strconv.Atoi(minimumBasisRunsStr)
+		if err != nil {
+			err = fmt.Errorf("minimum basis runs is not a number")
+			return
+		}
+	}
+
+	s.componentReadinessConfig.ApplyAdvancedOptions(&advancedOption)
+
+	minimumRegressionAgeStr := req.URL.Query().Get("minimumRegressionAgeDays")
+	if minimumRegressionAgeStr != "" {
+		advancedOption.MinimumRegressionAgeDays, err = strconv.Atoi(minimumRegressionAgeStr)
+		if err != nil {
+			err = fmt.Errorf("minimum regression age days is not a number")
+			return
+		}
+	}
+
+	suppressWeakBasisStr := req.URL.Query().Get("suppressWeakBasisRegressions")
+	if suppressWeakBasisStr != "" {
+		advancedOption.SuppressWeakBasisRegressions, err = strconv.ParseBool(suppressWeakBasisStr)
+		if err != nil {
+			err = errors.WithMessage(err, "expected boolean for suppressing weak basis regressions")
+			return
+		}
+	}
+
+	chiSquaredThresholdStr := req.URL.Query().Get("chiSquaredThreshold")
+	if chiSquaredThresholdStr != "" {
+		advancedOption.ChiSquaredThreshold, err = strconv.Atoi(chiSquaredThresholdStr)
+		if err != nil {
+			err = fmt.Errorf("chi-squared threshold is not a number")
+			return
+		}
+	}
+
+	advancedOption.BucketGranularity = apitype.BucketGranularity(req.URL.Query().Get("bucketGranularity"))
+	switch advancedOption.BucketGranularity {
+	case "", apitype.BucketGranularityDay, apitype.BucketGranularityWeek, apitype.BucketGranularityAuto:
+	default:
+		err = fmt.Errorf("unknown bucket granularity %q", advancedOption.BucketGranularity)
+		return
+	}
+
+	advancedOption.Comparison = apitype.Comparison(req.URL.Query().Get("comparison"))
+	switch advancedOption.Comparison {
+	case "", apitype.ComparisonFisher, apitype.ComparisonBayesian:
+	default:
+		err = fmt.Errorf("unknown comparison %q", advancedOption.Comparison)
+		return
+	}
+
+	advancedOption.AlternativeHypothesis = apitype.AlternativeHypothesis(req.URL.Query().Get("alternativeHypothesis"))
+	switch advancedOption.AlternativeHypothesis {
+	case "", apitype.AlternativeOneSided, apitype.AlternativeTwoSided:
+	default:
+		err = fmt.Errorf("unknown alternative hypothesis %q", advancedOption.AlternativeHypothesis)
+		return
+	}
+
+	warningMarginStr := req.URL.Query().Get("warningMargin")
+	if warningMarginStr != "" {
+		advancedOption.WarningMargin, err = strconv.Atoi(warningMarginStr)
+		if err != nil {
+			err = fmt.Errorf("warning margin is not a number")
+			return
+		}
+		if advancedOption.WarningMargin < 0 || advancedOption.WarningMargin > 100 {
+			err = fmt.Errorf("warning margin is not in the correct range")
+			return
+		}
+	}
+
+	minImprovementDeltaStr := req.URL.Query().Get("minImprovementDelta")
+	if minImprovementDeltaStr != "" {
+		advancedOption.MinimumImprovementDelta, err = strconv.ParseFloat(minImprovementDeltaStr, 64)
+		if err != nil {
+			err = fmt.Errorf("minimum improvement delta is not a number")
+			return
+		}
+		if advancedOption.MinimumImprovementDelta < 0 || advancedOption.MinimumImprovementDelta > 100 {
+			err = fmt.Errorf("minimum improvement delta is not in the correct range")
+			return
+		}
+	}
+
+	includePValuesStr := req.URL.Query().Get("includePValues")
+	if includePValuesStr != "" {
+		advancedOption.IncludePValues, err = strconv.ParseBool(includePValuesStr)
+		if err != nil {
+			err = errors.WithMessage(err, "expected boolean for including p-values")
+			return
+		}
+	}
+
+	advancedOption.JobAggregation = apitype.JobAggregation(req.URL.Query().Get("jobAggregation"))
+	switch advancedOption.JobAggregation {
+	case "", apitype.JobAggregationMedian, apitype.JobAggregationMean:
+	default:
+		err = fmt.Errorf("unknown job aggregation %q", advancedOption.JobAggregation)
+		return
+	}
+
+	advancedOption.JunitAggregation = apitype.JunitAggregation(req.URL.Query().Get("junitAggregation"))
+	switch advancedOption.JunitAggregation {
+	case "", apitype.JunitAggregationAnyPass, apitype.JunitAggregationAllPass, apitype.JunitAggregationMajority:
+	default:
+		err = fmt.Errorf("unknown junit aggregation %q", advancedOption.JunitAggregation)
+		return
+	}
+
+	advancedOption.FlakeHandling = apitype.FlakeHandling(req.URL.Query().Get("flakeHandling"))
+	if advancedOption.FlakeHandling != "" && advancedOption.FlakeHandling != apitype.FlakeHandlingExclude {
+		err = fmt.Errorf("unknown flake handling %q", advancedOption.FlakeHandling)
+		return
+	}
+
+	excludeRunsWithoutTestStr := req.URL.Query().Get("excludeRunsWithoutTest")
+	if excludeRunsWithoutTestStr != "" {
+		advancedOption.ExcludeRunsWithoutTest, err = strconv.ParseBool(excludeRunsWithoutTestStr)
+		if err != nil {
+			err = errors.WithMessage(err, "expected boolean for excluding runs without the test")
+			return
+		}
+	}
+
+	includeImprovementsStr := req.URL.Query().Get("includeImprovementsInList")
+	if includeImprovementsStr != "" {
+		advancedOption.IncludeImprovementsInList, err = strconv.ParseBool(includeImprovementsStr)
+		if err != nil {
+			err = errors.WithMessage(err, "expected boolean for including improvements in the list")
+			return
+		}
+	}
+
+	includeFlakeRatesStr := req.URL.Query().Get("includeFlakeRates")
+	if includeFlakeRatesStr != "" {
+		advancedOption.IncludeFlakeRates, err = strconv.ParseBool(includeFlakeRatesStr)
+		if err != nil {
+			err = errors.WithMessage(err, "expected boolean for including flake rates")
+			return
+		}
+	}
+
+	minimumJobSetOverlapStr := req.URL.Query().Get("minimumJobSetOverlap")
+	if minimumJobSetOverlapStr != "" {
+		advancedOption.MinimumJobSetOverlap, err = strconv.Atoi(minimumJobSetOverlapStr)
+		if err != nil {
+			err = fmt.Errorf("minimum job set overlap is not a number")
+			return
+		}
+		if advancedOption.MinimumJobSetOverlap < 0 || advancedOption.MinimumJobSetOverlap > 100 {
+			err = fmt.Errorf("minimum job set overlap is not in the correct range")
+			return
+		}
+	}
+
+	if includeCollapsedVariantsStr := req.URL.Query().Get("includeCollapsedVariants"); includeCollapsedVariantsStr != "" {
+		advancedOption.IncludeCollapsedVariants, err = strconv.ParseBool(includeCollapsedVariantsStr)
+		if err != nil {
+			err = errors.WithMessage(err, "expected boolean for including collapsed variants")
+			return
+		}
+	}
+
+	if includeRawStatsStr := req.URL.Query().Get("includeRawStats"); includeRawStatsStr != "" {
+		advancedOption.IncludeRawStats, err = strconv.ParseBool(includeRawStatsStr)
+		if err != nil {
+			err = errors.WithMessage(err, "expected boolean for including raw stats")
+			return
+		}
+	}
+
+	includeZTestStr := req.URL.Query().Get("includeZTest")
+	if includeZTestStr != "" {
+		advancedOption.IncludeZTest, err = strconv.ParseBool(includeZTestStr)
+		if err != nil {
+			err = errors.WithMessage(err, "expected boolean for including the z-test")
+			return
+		}
+	}
+
+	if ignoreInfraFailuresStr := req.URL.Query().Get("ignoreInfraFailures"); ignoreInfraFailuresStr != "" {
+		advancedOption.IgnoreInfraFailures, err = strconv.ParseBool(ignoreInfraFailuresStr)
+		if err != nil {
+			err = errors.WithMessage(err, "expected boolean for ignore infra failures")
+			return
+		}
+	}
+	excludeFirstPRRunsStr := req.URL.Query().Get("excludeFirstPRRuns")
+	if excludeFirstPRRunsStr != "" {
+		advancedOption.ExcludeFirstPRRuns, err = strconv.ParseBool(excludeFirstPRRunsStr)
+		if err != nil {
+			err = errors.WithMessage(err, "expected boolean for exclude first PR runs")
+			return
+		}
+	}
+
 	forceRefreshStr := req.URL.Query().Get("forceRefresh")
 	if forceRefreshStr != "" {
 		cacheOption.ForceRefresh, err = strconv.ParseBool(forceRefreshStr)
@@ -1608,12 +2244,42 @@ func (s *Server) Serve() {
 			Capabilities: []string{ComponentReadinessCapability},
 			HandlerFunc:  s.jsonComponentReportFromBigQuery,
 		},
+		{
+			EndpointPath: "/api/component_readiness/stream",
+			Description:  "Streams component readiness report rows from BigQuery as a JSON array",
+			Capabilities: []string{ComponentReadinessCapability},
+			HandlerFunc:  s.jsonComponentReportStreamFromBigQuery,
+		},
+		{
+			EndpointPath: "/api/component_readiness/options",
+			Description:  "Reports the options a component readiness request resolves to, for debugging",
+			Capabilities: []string{ComponentReadinessCapability},
+			HandlerFunc:  s.jsonComponentReportOptions,
+		},
+		{
+			EndpointPath: "/api/component_readiness/regressions",
+			Description:  "Lists open regressions across releases from BigQuery, with their status only for the sample release when report parameters are given",
+			Capabilities: []string{ComponentReadinessCapability},
+			HandlerFunc:  s.jsonOpenRegressionsFromBigQuery,
+		},
+		{
+			EndpointPath: "/api/component_readiness/new_tests",
+			Description:  "Lists the tests that ran in the sample but not in the basis from BigQuery",
+			Capabilities: []string{ComponentReadinessCapability},
+			HandlerFunc:  s.jsonNewTestsFromBigQuery,
+		},
 		{
 			EndpointPath: "/api/component_readiness/test_details",
 			Description:  "Reports test details for component readiness from BigQuery",
 			Capabilities: []string{ComponentReadinessCapability},
 			HandlerFunc:  s.jsonComponentReportTestDetailsFromBigQuery,
 		},
+		{
+			EndpointPath: "/api/component_readiness/test_variants",
+			Description:  "Reports the variant values a test ran with in the sample window from BigQuery",
+			Capabilities: []string{ComponentReadinessCapability},
+			HandlerFunc:  s.jsonObservedTestVariantsFromBigQuery,
+		},
 		{
 			EndpointPath: "/api/component_readiness/variants",
 			Description:  "Reports test variants for component readiness from BigQuery",
@@ -2,10 +2,15 @@ package sippyserver
 
 import (
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	apitype "github.com/openshift/sippy/pkg/apis/api"
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/bigquery"
 	"github.com/openshift/sippy/pkg/db/models"
 )
 
@@ -120,3 +125,47 @@ func TestEncodeDefaultHighRisk(t *testing.T) {
 		t.Fatal("Invalid overall risk analysis after decoding")
 	}
 }
+
+func TestComponentReportOptions(t *testing.T) {
+	s := &Server{
+		bigQueryClient: &bigquery.Client{},
+		componentReadinessConfig: v1.ComponentReadinessConfig{
+			GroupingPresets: map[string][]string{"by-arch": {"arch", "network"}},
+		},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/component_readiness/options?"+strings.Join([]string{
+		"baseRelease=4.15",
+		"sampleRelease=4.16",
+		"baseRollingWindow=168h",
+		"sampleStartTime=2024-02-01T00:00:00Z",
+		"sampleEndTime=2024-02-08T00:00:00Z",
+		"groupingPreset=by-arch",
+	}, "&"), nil)
+	w := httptest.NewRecorder()
+	s.jsonComponentReportOptions(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+	}
+
+	options := apitype.ComponentReportRequestOptions{}
+	if err := json.NewDecoder(w.Body).Decode(&options); err != nil {
+		t.Fatalf("Error while decoding options: %v", err)
+	}
+	sampleStart := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	if !options.BaseRelease.Start.Equal(sampleStart.Add(-168*time.Hour)) || !options.BaseRelease.End.Equal(sampleStart) {
+		t.Fatalf("rolling baseline was not resolved to the week before the sample: %v to %v", options.BaseRelease.Start, options.BaseRelease.End)
+	}
+	if options.VariantOptions.GroupBy != "arch,network" {
+		t.Fatalf("grouping preset was not resolved: %q", options.VariantOptions.GroupBy)
+	}
+	if options.AdvancedOptions.PityFactor != 5 || options.AdvancedOptions.MinimumFailure != 3 {
+		t.Fatalf("defaults were not resolved: %+v", options.AdvancedOptions)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, strings.Replace(req.URL.String(), "by-arch", "missing", 1), nil)
+	w = httptest.NewRecorder()
+	s.jsonComponentReportOptions(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "unknown grouping preset") {
+		t.Fatalf("expected a bad request for an unknown preset, got %d: %s", w.Code, w.Body.String())
+	}
+}